@@ -0,0 +1,56 @@
+package nla_framework
+
+import (
+	"fmt"
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+)
+
+// StartDryRun прогоняет генерацию проекта целиком, но файлы пишутся в память, а не на диск.
+// После генерации печатается список изменений относительно текущего состояния проекта.
+// isPrintDiff - для измененных файлов печатается unified diff, иначе только сводка по файлам.
+// Ошибки описания проекта возвращаются так же, как и в Start
+func StartDryRun(p types.ProjectType, modifyFunc copyFileModifyFunc, isPrintDiff bool) ([]utils.FileChange, error) {
+	return StartDryRunInDir(p, types.DefaultOutputRoot, modifyFunc, isPrintDiff)
+}
+
+// StartDryRunInDir то же, что StartDryRun, но изменения считаются относительно проекта в директории outputRoot
+func StartDryRunInDir(p types.ProjectType, outputRoot string, modifyFunc copyFileModifyFunc, isPrintDiff bool) ([]utils.FileChange, error) {
+	memFs := utils.NewMemFileSystem()
+	utils.SetFileSystem(memFs)
+	defer utils.SetFileSystem(utils.OsFileSystem{})
+
+	if err := StartInDir(p, outputRoot, modifyFunc); err != nil {
+		return nil, err
+	}
+
+	changes := memFs.Changes()
+	printDryRunReport(changes, isPrintDiff)
+	return changes, nil
+}
+
+func printDryRunReport(changes []utils.FileChange, isPrintDiff bool) {
+	cnt := map[string]int{}
+	for _, ch := range changes {
+		cnt[ch.Status]++
+	}
+	fmt.Printf("dry run: added %v, changed %v, deleted %v\n", cnt[utils.FileChangeAdded], cnt[utils.FileChangeChanged], cnt[utils.FileChangeDeleted])
+	for _, ch := range changes {
+		switch ch.Status {
+		case utils.FileChangeAdded:
+			fmt.Printf("  + %s\n", ch.Path)
+		case utils.FileChangeChanged:
+			fmt.Printf("  ~ %s\n", ch.Path)
+		case utils.FileChangeDeleted:
+			fmt.Printf("  - %s\n", ch.Path)
+		}
+	}
+	if !isPrintDiff {
+		return
+	}
+	for _, ch := range changes {
+		if diff := utils.UnifiedDiff(ch.Path, ch.Old, ch.New); len(diff) > 0 {
+			fmt.Printf("\n%s", diff)
+		}
+	}
+}
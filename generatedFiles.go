@@ -0,0 +1,50 @@
+package nla_framework
+
+import (
+	"fmt"
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"log"
+	"strings"
+)
+
+// читаем манифест предыдущей генерации и проверяем, какие сгенерированные файлы были изменены вручную
+func prepareManifest(p types.ProjectType) (prev *utils.Manifest, err error) {
+	prev, err = utils.ReadManifest(p.OutputRoot(), p.GeneratedFiles.Keep)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %s", utils.ManifestFilename, err)
+	}
+	if p.GeneratedFiles.OnHandEdit == types.GeneratedFilesOnHandEditOverwrite {
+		return
+	}
+	edited := prev.HandEdited()
+	if len(edited) == 0 {
+		return
+	}
+	arr := []string{}
+	for _, e := range edited {
+		arr = append(arr, "\t"+e.Path)
+	}
+	if p.GeneratedFiles.OnHandEdit == types.GeneratedFilesOnHandEditRefuse {
+		return nil, fmt.Errorf("generated files were modified by hand. Add them to GeneratedFiles.Keep or revert changes:\n%s", strings.Join(arr, "\n"))
+	}
+	log.Printf("warning: generated files were modified by hand and will be overwritten (add them to GeneratedFiles.Keep to preserve):\n%s", strings.Join(arr, "\n"))
+	return
+}
+
+// удаляем файлы, которые перестали генерироваться, и сохраняем новый манифест
+func finishManifest(p types.ProjectType, prev, cur *utils.Manifest) {
+	prev.CopyKept(cur)
+	if !p.GeneratedFiles.IsNoCleanup {
+		removed, skipped, err := prev.RemoveOrphans(cur)
+		utils.CheckErr(err, "finishManifest RemoveOrphans")
+		for _, e := range removed {
+			log.Printf("removed orphaned file: %s", e.Path)
+		}
+		for _, e := range skipped {
+			log.Printf("warning: orphaned file is kept or modified by hand, not removed: %s", e.Path)
+		}
+	}
+	err := cur.Save()
+	utils.CheckErr(err, "finishManifest Save")
+}
@@ -0,0 +1,56 @@
+package nla_framework
+
+import (
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// GoldenCompare генерирует проект во временную директорию и сравнивает результат с эталонным деревом файлов goldenDir
+// (например testdata/golden/<fixture>). Возвращает отличия, пути относительно корня проекта.
+// Если isUpdate, то эталон перезаписывается результатом генерации и отличия не возвращаются.
+// Пример использования в тесте:
+//  changes, err := nla_framework.GoldenCompare(fixtureProject(), "testdata/golden/sample", *update)
+//  for _, ch := range changes { t.Errorf("%s %s\n%s", ch.Status, ch.Path, utils.UnifiedDiff(ch.Path, ch.Old, ch.New)) }
+func GoldenCompare(p types.ProjectType, goldenDir string, isUpdate bool) ([]utils.FileChange, error) {
+	tmpDir, err := ioutil.TempDir("", "nla_golden")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err = StartInDir(p, tmpDir, nil); err != nil {
+		return nil, err
+	}
+
+	if isUpdate {
+		return nil, copyGoldenDir(tmpDir, goldenDir)
+	}
+	return utils.CompareDirs(goldenDir, tmpDir)
+}
+
+// перезапись эталона результатом генерации
+func copyGoldenDir(source, dist string) error {
+	if err := os.RemoveAll(dist); err != nil {
+		return err
+	}
+	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dist, rel), os.ModePerm)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dist, rel), data, info.Mode())
+	})
+}
@@ -0,0 +1,71 @@
+package nla_framework
+
+import (
+	"flag"
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"testing"
+)
+
+// go test -run TestGolden -update - перезапись эталона testdata/golden/fixture после изменения шаблонов.
+// Новые файлы эталона добавлять через git add -f: .gitignore сгенерированного проекта скрывает, например, image/*
+var update = flag.Bool("update", false, "rewrite testdata/golden by generation result")
+
+// fixtureProject небольшой проект для golden теста: один документ с базовыми методами, кэшем списка и рассылкой изменений
+func fixtureProject() types.ProjectType {
+	p := types.ProjectType{Name: "fixture"}
+	p.Config.LocalProjectPath = "fixture/src"
+	p.Config.Vue.QuasarVersion = 2
+	p.Config.Postgres = types.PostrgesConfig{DbName: "fixture", Port: 5440, Password: "fixturePassword", TimeZone: "Europe/Moscow"}
+	p.Config.WebServer = types.WebServerConfig{Port: 3090, Url: "https://fixture.ru", Path: "/home/fixture"}
+	p.Config.Email = types.EmailConfig{Sender: "noreply@fixture.ru", Host: "smtp.fixture.ru", Port: 465}
+
+	doc := types.DocType{
+		Project: &p,
+		Name:    "client",
+		NameRu:  "клиент",
+		Vue:     types.DocVue{RouteName: "client", MenuIcon: "image/client.svg", Roles: []string{"admin"}},
+		Flds: []types.FldType{
+			types.GetFldTitle(),
+			types.GetFldString("inn", "ИНН", 20, [][]int{{1, 2}}).SetIsSearch(),
+			types.GetFldRef("manager_id", "менеджер", "user", [][]int{{2, 1}}, "isShowLink"),
+			types.GetFldDouble("amount", "сумма", [][]int{{2, 2}}),
+		},
+		IsBaseTemplates: types.DocIsBaseTemplates{Vue: true, Sql: true},
+	}
+	doc.Sql.FillBaseMethods(doc.Name, "admin")
+	doc.Sql.Methods["client_list"].Cache = &types.DocSqlMethodCache{Ttl: 60, Scope: []string{types.PgMethodCacheScopeUser, types.PgMethodCacheScopeParams}}
+	doc.Realtime = &types.DocRealtime{Flds: []string{"title", "amount"}}
+	doc.Init()
+
+	p.Docs = []types.DocType{doc}
+	p.FillVueBaseRoutes()
+	p.Vue.Menu = []types.VueMenu{{DocName: "client"}}
+	p.FillSideMenu()
+	return p
+}
+
+func TestGolden(t *testing.T) {
+	changes, err := GoldenCompare(fixtureProject(), "testdata/golden/fixture", *update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range changes {
+		t.Errorf("%s %s\n%s", ch.Status, ch.Path, utils.UnifiedDiff(ch.Path, ch.Old, ch.New))
+	}
+}
+
+// описание проекта в YAML (testdata/definition) должно давать ту же генерацию, что и fixtureProject в Go коде
+func TestLoadProjectGolden(t *testing.T) {
+	p, err := types.LoadProject("testdata/definition/project.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := GoldenCompare(*p, "testdata/golden/fixture", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range changes {
+		t.Errorf("%s %s\n%s", ch.Status, ch.Path, utils.UnifiedDiff(ch.Path, ch.Old, ch.New))
+	}
+}
@@ -2,12 +2,10 @@ package nla_framework
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/tvitcom/nla_framework/templates"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
-	"github.com/spf13/cast"
 	"io/ioutil"
 	"log"
 	"os"
@@ -29,8 +27,12 @@ var (
 	tmplMap map[string]*template.Template
 )
 
-func readData(p types.ProjectType) {
+// readData подготавливает описание проекта к генерации и возвращает отчет о найденных в описании проблемах
+func readData(p types.ProjectType, outputRoot string) *types.ValidationReport {
 	project = p
+	// генерация дописывает шаблоны в документы, поэтому работаем с копией, чтобы не менять описание, переданное в Start
+	project.Docs = copyDocs(p.Docs)
+	project.SetOutputRoot(outputRoot)
 	// проставляем localpath если он не заполнен
 	project.Config.LocalProjectPath = project.FillLocalPath()
 	// дефолтный токен для сервиса https://dadata.ru/
@@ -39,7 +41,6 @@ func readData(p types.ProjectType) {
 	}
 	// передаем project в папку types, чтобы иметь доступ из функций шаблонов к проекту
 	templates.SetProject(&project)
-	project.DistPath = "../src"
 	project.FillDocTemplatesFields()
 	project.GenerateGrid()
 	project.FillVueFlds()
@@ -51,46 +52,37 @@ func readData(p types.ProjectType) {
 		// проставляем дефолтное время сервера, если не задано в настройках проекта
 		project.Config.Postgres.TimeZone = "Europe/Moscow"
 	}
-	// проверяем что название проекта без пробелов
-	if strings.Contains(p.Name, " ") {
-		utils.CheckErr(errors.New(fmt.Sprintf("Wrong project name: '%s'. Remove spaces.", p.Name)), "")
-	}
-	// проверки правил для fld
-	for _, d := range p.Docs {
-		for _, fld := range d.Flds {
-			// проверяем чтобы не было поля user_id, потому что это служебное поле
-			if fld.Name == "user_id" {
-				utils.CheckErr(errors.New("field with name 'user_id' is not allowed. Rename field. "), "doc: " + d.Name)
-			}
-			for _, v := range fld.Vue.Options {
-				if strings.Contains(cast.ToString(v.Value), " ") {
-					utils.CheckErr(errors.New(fmt.Sprintf("field with name '%s' contains value '%s'. Remove spaces from value.", fld.Name, v.Value)), "doc: " + d.Name)
-				}
-			}
-		}
-	}
-	// проверяем что если авторизация через email, то должны быть заполнены необходимые поля
-	if p.Config.Auth.ByEmail {
-		if len(p.Config.Email.Sender) == 0 || len(p.Config.Email.Host) == 0 || p.Config.Email.Port == 0 {
-			utils.CheckErr(errors.New("in Config.Email fill fields: 'Sender', 'Host', 'Port'"), "")
-		}
-	}
-	// проверка что если документ - это уникальная связь двух таблиц, то в нем поле title если есть, то не должно быть уникальным
-	for _, d := range p.Docs {
-		if d.Sql.IsUniqLink {
-			for _, fld := range d.Flds {
-				if fld.Name == "title" && fld.Sql.IsUniq {
-					utils.CheckErr(errors.New("field 'title' must be not uniq. Remove fld 'title' or t.GetFldTitle().SetIsNotUniq()"), "doc: " + d.Name)
-				}
-			}
-		}
-	}
-
 	// передаем project в папку types, чтобы иметь доступ из функций шаблонов к проекту
 	types.SetProject(&project)
+
+	return project.Validate()
+}
+
+// Start генерирует проект в DefaultOutputRoot (на уровень выше директории projectTemplate). См. StartInDir
+func Start(p types.ProjectType, modifyFunc copyFileModifyFunc) error {
+	return StartInDir(p, types.DefaultOutputRoot, modifyFunc)
 }
 
-func Start(p types.ProjectType, modifyFunc copyFileModifyFunc) {
+// StartInDir генерирует проект в директорию outputRoot: исходники в outputRoot/src, остальные файлы в outputRoot.
+// Перед генерацией проверяется все описание проекта, и если найдены ошибки, то генерация не начинается, а возвращается *types.ValidationReport со всеми найденными проблемами.
+// Ошибки, возникшие уже в процессе генерации, также возвращаются как *types.ValidationReport.
+// Ошибка возвращается и в случае, если сгенерированные файлы изменены вручную и GeneratedFiles.OnHandEdit = refuse
+func StartInDir(p types.ProjectType, outputRoot string, modifyFunc copyFileModifyFunc) (err error) {
+	// вместо завершения процесса в utils.Fatalf / utils.CheckErr получаем panic и возвращаем ошибку
+	utils.SetIsPanicOnFatal(true)
+	defer utils.SetIsPanicOnFatal(false)
+	defer func() {
+		if r := recover(); r != nil {
+			fatalErr, ok := r.(utils.FatalError)
+			if !ok {
+				panic(r)
+			}
+			report := &types.ValidationReport{}
+			report.AddError("", "", "", fatalErr.Msg)
+			err = report
+		}
+	}()
+
 	// проставляем дефолтную авторизацию по email
 	if !p.Config.Auth.ByPhone {
 		p.Config.Auth.ByEmail = true
@@ -119,7 +111,25 @@ func Start(p types.ProjectType, modifyFunc copyFileModifyFunc) {
 	//}
 
 	// читаем данные для проекта
-	readData(p)
+	report := readData(p, outputRoot)
+	if report.HasErrors() {
+		return report
+	}
+	for _, v := range report.Warnings() {
+		log.Printf("warning: %s", v)
+	}
+	// описание проверено, дальше ошибки в описании (например, DocType.Fld из шаблона) прерывают генерацию
+	types.SetIsGenerating(true)
+	defer types.SetIsGenerating(false)
+	// манифест предыдущей генерации - для проверки ручных изменений и удаления устаревших файлов
+	prevManifest, err := prepareManifest(project)
+	if err != nil {
+		return err
+	}
+	curManifest := utils.NewManifest(project.OutputRoot(), project.GeneratedFiles.Keep)
+	utils.SetManifest(curManifest)
+	defer utils.SetManifest(nil)
+
 	// читаем темплейты
 	tmplMap = templates.ParseTemplates(project)
 
@@ -129,8 +139,11 @@ func Start(p types.ProjectType, modifyFunc copyFileModifyFunc) {
 	// генерим файлы для проекта
 	templates.WriteProjectFiles(project, tmplMap)
 
+	// миграции для таблиц документов, изменившихся с прошлой генерации
+	templates.WriteSqlMigrations(project)
+
 	// генерим файлы для документов
-	for _, d := range p.Docs {
+	for _, d := range project.Docs {
 		for _, dt := range d.Templates {
 			err := templates.ExecuteToFile(dt.Tmpl, d, dt.DistPath, dt.DistFilename)
 			utils.CheckErr(err, fmt.Sprintf("'%s' ExecuteToFile '%s'", d.Name, dt.DistFilename))
@@ -138,20 +151,23 @@ func Start(p types.ProjectType, modifyFunc copyFileModifyFunc) {
 	}
 
 	// копируем файлы проекта (которые не шаблоны)
-	err := copyFiles(project, getCurrentDir() + "/sourceFiles", "../", modifyFunc)
+	err = copyFiles(project, getCurrentDir() + "/sourceFiles", project.OutputRoot() + "/", modifyFunc)
 	utils.CheckErr(err, "Copy sourceFiles")
 
 	// отдельно копируем webClient в зависимости от версии quasar-framework
-	err = copyFiles(project, fmt.Sprintf("%s/webClient/quasar_%v", getCurrentDir(), project.GetQuasarVersion()), "../src/", modifyFunc)
+	err = copyFiles(project, fmt.Sprintf("%s/webClient/quasar_%v", getCurrentDir(), project.GetQuasarVersion()), project.DistPath + "/", modifyFunc)
 	utils.CheckErr(err, "Copy sourceFiles")
 
 	// в случае если quasar-framework v1 то копируем часть устаревших sql файлов. Для поддержания кода старых проектов
 	if p.GetQuasarVersion() == 1 {
-		err = copyFiles(project, getCurrentDir() + "/sourceFilesSQL_legacy", "../src/sql/", modifyFunc)
+		err = copyFiles(project, getCurrentDir() + "/sourceFilesSQL_legacy", project.DistPath + "/sql/", modifyFunc)
 		utils.CheckErr(err, "Copy sourceFiles")
 	}
 
 	templates.OtherTemplatesGenerate(project)
+
+	finishManifest(project, prevManifest, curManifest)
+	return nil
 }
 
 // функция для копирования файлов с возможностью модификаации содержимого файлов
@@ -168,8 +184,10 @@ func copyFiles(p types.ProjectType, source, dist string, modifyFunc copyFileModi
 				}
 				// для windows заменяем слэши в пути на обратные
 				dirPath := strings.TrimSuffix(strings.TrimPrefix(strings.Replace(path, "\\", "/", -1), source), info.Name())
+				// путь к исходному файлу относительно папки фреймворка - для манифеста
+				sourceRelPath := strings.TrimPrefix(strings.Replace(path, "\\", "/", -1), getCurrentDir()+"/")
 				// создаем директории
-				err = os.MkdirAll(dist+dirPath, os.ModePerm)
+				err = utils.Fs.MkdirAll(dist + dirPath)
 				if err != nil {
 					return err
 				}
@@ -241,16 +259,17 @@ func copyFiles(p types.ProjectType, source, dist string, modifyFunc copyFileModi
 				}
 				// для оптимизации записи файлов webClient (чтобы ускорить рестарт quasar), проверяем что файл изменен и только в этом случае его перезаписываем
 				if strings.Contains(dist+dirPath+info.Name(), "webClient") {
-					if existFile, err := ioutil.ReadFile(dist+dirPath+info.Name()); err == nil {
+					if existFile, err := utils.Fs.ReadFile(dist+dirPath+info.Name()); err == nil {
 						isEqual := utils.ByteSliceEqual(existFile, file)
 						if isEqual {
+							utils.RecordGeneratedFile(dist+dirPath+info.Name(), sourceRelPath, file)
 							return nil
 						}
 						//fmt.Printf("file changed: %s not equal\n", dist+dirPath+info.Name())
 					}
 				}
 				// записываем файл по новому пути
-				err = ioutil.WriteFile(dist+dirPath+info.Name(), file, 0644)
+				err = utils.WriteGeneratedFile(dist+dirPath+info.Name(), sourceRelPath, file)
 				if err != nil {
 					return err
 				}
@@ -260,9 +279,27 @@ func copyFiles(p types.ProjectType, source, dist string, modifyFunc copyFileModi
 	return
 }
 
+// копия документов с копиями шаблонов
+func copyDocs(docs []types.DocType) []types.DocType {
+	res := make([]types.DocType, len(docs))
+	for i, d := range docs {
+		if d.Templates != nil {
+			tmpls := map[string]*types.DocTemplate{}
+			for k, t := range d.Templates {
+				tCopy := *t
+				tmpls[k] = &tCopy
+			}
+			d.Templates = tmpls
+		}
+		res[i] = d
+	}
+	return res
+}
+
 func removeOldFiles(distPath string) {
-	// удаляем модели в sql, потому что могла изменится нумерация файлов и тогда риск дублирования
-	err := os.RemoveAll(distPath + "/sql/model")
+	// удаляем модели в sql, потому что могла изменится нумерация файлов и тогда риск дублирования.
+	// Файлы из GeneratedFiles.Keep остаются
+	err := utils.RemoveAllExceptKept(distPath + "/sql/model")
 	utils.CheckErr(err, "removeOldFiles")
 }
 
@@ -313,7 +350,7 @@ func routesJsModify() string {
 	res := "// for codeGenerate ##routes_slot1"
 	for _, r := range project.Vue.Routes {
 		if len(r) < 2 {
-			log.Fatalf("routesJsModify project.Vue.Route route array %v length < 2", r)
+			utils.Fatalf("routesJsModify project.Vue.Route route array %v length < 2", r)
 		}
 		res = fmt.Sprintf("%s\n\t{path: '/%s', component: () => import(`../app/components/%s`), props: true},", res, r[0], r[1])
 		//{path: '/users/:id', component: () => import(`../app/components/users/item.vue`), props: true},
@@ -6,36 +6,28 @@ import (
 	"fmt"
 )
 
-type cacheType struct {
-	Data        interface{}
-	ExpiredTime time.Time
-}
-
 var (
-	memCacheMap = map[string]cacheType{}
-	gc          = gcache.New(40).
+	// memCache кэш для произвольных данных приложения
+	memCache = NewCache("mem", 10000)
+	gc       = gcache.New(40).
 		LRU().
 		Build()
 )
 
 func MemCacheGet(key string) interface{} {
-	if res, ok := memCacheMap[key]; ok {
-		// проверяем не истекло ли время кэша
-		if res.ExpiredTime.After(time.Now()) {
-			return res.Data
-		} else {
-			delete(memCacheMap, key)
-		}
+	if res, ok := memCache.Get(key); ok {
+		return res
 	}
 	return nil
 }
 
-func MemCachePut(key string, duration int, data interface{}) {
-	memCacheMap[key] = cacheType{data, time.Now().Add(time.Duration(duration) * time.Second)}
+// MemCachePut сохранение данных на duration секунд. По tags запись сбрасывается через InvalidateTable (например "client" или TableTag("client", id))
+func MemCachePut(key string, duration int, data interface{}, tags ...string) {
+	memCache.Set(key, data, time.Duration(duration)*time.Second, tags...)
 }
 
 func MemCacheClear(key string) {
-	delete(memCacheMap, key)
+	memCache.Delete(key)
 }
 
 func GoCacheSet(key, value interface{}, t time.Duration) {
@@ -0,0 +1,216 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/tvitcom/nla_framework/types"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Единый лог приложения. Записи с уровнем и полями отправляются во все подключенные приемники (Sink):
+// stdout, файл и graylog (GELF). До вызова Init пишем в stdout с уровнем info
+
+type (
+	// Level уровень записи
+	Level int
+
+	// Fields дополнительные поля записи (request_id, user_id и т.п.)
+	Fields map[string]interface{}
+
+	// Record запись лога, передается в приемники
+	Record struct {
+		Time   time.Time
+		Level  Level
+		App    string
+		Msg    string
+		Fields Fields
+	}
+
+	// Sink приемник записей лога
+	Sink interface {
+		Write(r *Record) error
+		Close() error
+	}
+
+	// Entry запись с заранее заданными полями. Создается через With
+	Entry struct {
+		fields Fields
+	}
+)
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var (
+	mu       sync.RWMutex
+	appName  string
+	minLevel = LevelInfo
+	sinks    = []Sink{newWriterSink(os.Stdout, nil, true)}
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return "info"
+}
+
+// ParseLevel уровень по названию из config.toml. Неизвестное значение - info
+func ParseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// Init настройка лога по секциям logger и graylog из config.toml. app - название приложения, добавляется в каждую запись
+func Init(app string, config types.Logger, graylog types.GraylogConfig) error {
+	isJson := config.Format != "text"
+	list := []Sink{}
+	if !config.IsStdoutDisabled {
+		list = append(list, newWriterSink(os.Stdout, nil, isJson))
+	}
+	if len(config.File) > 0 {
+		s, err := newFileSink(config.File, isJson)
+		if err != nil {
+			return err
+		}
+		list = append(list, s)
+	}
+	if len(graylog.Host) > 0 {
+		s, err := newGelfSink(app, graylog)
+		if err != nil {
+			return err
+		}
+		list = append(list, s)
+	}
+	if len(list) == 0 {
+		// без приемников записи теряются, поэтому оставляем stdout
+		list = append(list, newWriterSink(os.Stdout, nil, isJson))
+	}
+
+	mu.Lock()
+	prev := sinks
+	appName = app
+	minLevel = ParseLevel(config.Level)
+	sinks = list
+	mu.Unlock()
+	closeSinks(prev)
+	return nil
+}
+
+// Close закрытие файла и соединения с graylog. Дальнейшие записи идут в stdout. Подходит для lifecycle.Register
+func Close(ctx context.Context) error {
+	mu.Lock()
+	prev := sinks
+	sinks = []Sink{newWriterSink(os.Stdout, nil, true)}
+	mu.Unlock()
+	closeSinks(prev)
+	return nil
+}
+
+func closeSinks(list []Sink) {
+	for _, s := range list {
+		if err := s.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: sink close error: %s\n", err)
+		}
+	}
+}
+
+// IsEnabled признак что записи уровня l попадают в лог. Для пропуска дорогой подготовки данных
+func IsEnabled(l Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return l >= minLevel
+}
+
+// With запись с дополнительными полями
+func With(fields Fields) *Entry {
+	return (&Entry{}).With(fields)
+}
+
+// With копия записи с добавленными полями
+func (e *Entry) With(fields Fields) *Entry {
+	res := &Entry{fields: Fields{}}
+	for k, v := range e.fields {
+		res.fields[k] = v
+	}
+	for k, v := range fields {
+		res.fields[k] = v
+	}
+	return res
+}
+
+func (e *Entry) Debugf(format string, v ...interface{}) {
+	e.log(LevelDebug, format, v...)
+}
+
+func (e *Entry) Infof(format string, v ...interface{}) {
+	e.log(LevelInfo, format, v...)
+}
+
+func (e *Entry) Warnf(format string, v ...interface{}) {
+	e.log(LevelWarn, format, v...)
+}
+
+func (e *Entry) Errorf(format string, v ...interface{}) {
+	e.log(LevelError, format, v...)
+}
+
+func (e *Entry) log(l Level, format string, v ...interface{}) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if l < minLevel {
+		return
+	}
+	msg := format
+	if len(v) > 0 {
+		msg = fmt.Sprintf(format, v...)
+	}
+	r := &Record{Time: time.Now(), Level: l, App: appName, Msg: msg, Fields: e.fields}
+	for _, s := range sinks {
+		if err := s.Write(r); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: sink write error: %s\n", err)
+		}
+	}
+}
+
+func Debugf(format string, v ...interface{}) {
+	(&Entry{}).log(LevelDebug, format, v...)
+}
+
+func Infof(format string, v ...interface{}) {
+	(&Entry{}).log(LevelInfo, format, v...)
+}
+
+func Warnf(format string, v ...interface{}) {
+	(&Entry{}).log(LevelWarn, format, v...)
+}
+
+func Errorf(format string, v ...interface{}) {
+	(&Entry{}).log(LevelError, format, v...)
+}
+
+// Fatalf запись с уровнем error и завершение приложения
+func Fatalf(format string, v ...interface{}) {
+	(&Entry{}).log(LevelError, format, v...)
+	_ = Close(context.Background())
+	os.Exit(1)
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"time"
+)
+
+// метрики, которые пишутся из разных пакетов проекта
+var (
+	PgListenerReconnects    = NewCounterVec("pg_listener_reconnects_total", "Reconnects of the postgres LISTEN connection")
+	PgOutboxEvents          = NewCounterVec("pg_outbox_events_total", "Postgres events delivered through event_outbox because NOTIFY payload was too large")
+	IntegrationSyncDuration = NewHistogramVec("integration_sync_duration_seconds", "Duration of integration sync (bitrix, odata)",
+		[]float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600}, "integration", "doc")
+	IntegrationSyncErrors = NewCounterVec("integration_sync_errors_total", "Failed integration syncs", "integration", "doc")
+)
+
+// ObserveIntegrationSync время синхронизации документа с внешней системой
+func ObserveIntegrationSync(integration, doc string, start time.Time, isOk bool) {
+	IntegrationSyncDuration.Observe(time.Since(start).Seconds(), integration, doc)
+	if !isOk {
+		IntegrationSyncErrors.Inc(integration, doc)
+	}
+}
@@ -0,0 +1,80 @@
+package pg
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"github.com/tvitcom/nla_framework/logger"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// директория с миграциями, которые генерируются при изменении таблиц документов
+const migrationsPath = "./sql/migrations"
+
+// применяем миграции, которые еще не были применены к базе. Каждая миграция выполняется в отдельной транзакции
+func applyMigrations(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migration (name TEXT PRIMARY KEY, applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now())`)
+	if err != nil {
+		return err
+	}
+	files, err := filepath.Glob(migrationsPath + "/*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), ".up.sql")
+		var isApplied bool
+		err = db.QueryRow(`SELECT exists(SELECT 1 FROM schema_migration WHERE name = $1)`, name).Scan(&isApplied)
+		if err != nil {
+			return err
+		}
+		if isApplied {
+			continue
+		}
+		err = execMigration(db, f, `INSERT INTO schema_migration (name) VALUES ($1)`, name)
+		if err != nil {
+			return fmt.Errorf("migration '%s': %s", name, err)
+		}
+		logger.Infof("migration '%s' applied", name)
+	}
+	return nil
+}
+
+// RollbackLastMigration откатывает последнюю примененную миграцию через соответствующий .down.sql файл
+func RollbackLastMigration() error {
+	var name string
+	err := Pg.QueryRow(`SELECT name FROM schema_migration ORDER BY name DESC LIMIT 1`).Scan(&name)
+	if err != nil {
+		return err
+	}
+	err = execMigration(Pg, fmt.Sprintf("%s/%s.down.sql", migrationsPath, name), `DELETE FROM schema_migration WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("rollback migration '%s': %s", name, err)
+	}
+	logger.Infof("migration '%s' rolled back", name)
+	return nil
+}
+
+// выполнение файла миграции и запись в schema_migration в одной транзакции
+func execMigration(db *sql.DB, filename, logQuery, name string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(string(data)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.Exec(logQuery, name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -1,11 +1,11 @@
 package pg
 
 import (
+	"context"
 	"fmt"
 	"github.com/tidwall/gjson"
+	"github.com/tvitcom/nla_framework/logger"
 	"encoding/json"
-	"errors"
-	"strings"
 )
 
 func CallPgSelectToJson(queryStr string, res interface{}) (err error) {
@@ -13,7 +13,7 @@ func CallPgSelectToJson(queryStr string, res interface{}) (err error) {
 
 	err = Pg.QueryRow(queryStr).Scan(&queryRes)
 	if err != nil {
-		fmt.Printf("queryRes err %s\n", err)
+		logger.Errorf("queryRes err %s", err)
 		return
 	}
 
@@ -33,35 +33,16 @@ func CallPgFuncWithStruct(funcName string, jsonStruct, res interface{}) error  {
 	return CallPgFunc(funcName, jsonStr, res, nil)
 }
 
+// CallPgFunc вызов postgres функции без контекста запроса, с таймаутом DefaultCallTimeout
 func CallPgFunc(funcName string, jsonStr []byte, res interface{}, metaInfo interface{}) (err error) {
-
-	var queryRes []byte
-	var queryStr string
-
-	if len(jsonStr) > 0 {
-		jsonStrMod := strings.Replace(string(jsonStr), "'", "''", -1)
-		queryStr = fmt.Sprintf("select * from %s('%s')", funcName, jsonStrMod)
-	} else {
-		queryStr = fmt.Sprintf("select * from %s()", funcName)
-	}
-
-	//fmt.Printf("funcName: %s, queryStr: %s\n", funcName, queryStr)
-
-	err = Pg.QueryRow(queryStr).Scan(&queryRes)
-	if err != nil {
-		return
-	}
-
-	//fmt.Printf("funcName: %s, queryRes: %s\n", funcName, queryRes)
-
-	return ParseResponseFromPostgresFunc(queryRes, res, metaInfo)
+	return CallPgFuncContext(context.Background(), funcName, jsonStr, res, metaInfo)
 }
 
 func ParseResponseFromPostgresFunc(queryRes []byte, tempRes interface{}, metaInfo interface{}) (err error) {
 	ok := gjson.Get(fmt.Sprintf("%s", queryRes), "ok").Bool()
 	if !ok {
 		errMsg := gjson.Get(fmt.Sprintf("%s", queryRes), "message").Str
-		err = errors.New(errMsg)
+		err = &PgFuncError{Message: errMsg}
 		return
 	}
 
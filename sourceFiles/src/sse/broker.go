@@ -1,97 +1,190 @@
 package sse
 
 import (
-	"encoding/json"
-	"fmt"
-	"github.com/gin-gonic/gin"
-	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// A single broker will be created in this program. It is responsible
-// for keeping a list of which clients (browsers) are currently attached
-// and broadcasting events (messages) to those clients.
-//
+// broker распределяет события по подключенным клиентам. Каждый клиент подписан на набор топиков
+// (свой пользователь, документы, чаты). Отправка не блокируется: у клиента своя очередь ограниченного размера,
+// при ее переполнении событие пропускается или соединение закрывается (SlowClientPolicy).
+// Последние события хранятся в кольцевом буфере для повтора после переподключения (Last-Event-ID)
 type broker struct {
-	// Create a map of clients, the keys of the map are the channels
-	// over which we can push messages to attached clients.  (The values
-	// are just booleans and are meaningless.)
-	clients map[chan string]bool
-	// Channel into which new clients can be pushed
-	newClients chan chan string
-	// Channel into which disconnected clients should be pushed
-	defunctClients chan chan string
-	// Channel into which messages are pushed to be broadcast out to attahed clients.
-	messages chan string
+	mu sync.Mutex
+	// номера событий уникальны только в пределах запуска приложения, поэтому в id события добавляется epoch.
+	// После перезапуска или переподключения к другому экземпляру повтор невозможен и клиент получает reset
+	epoch    string
+	lastId   int64
+	clients  map[*client]struct{}
+	byTopic  map[string]map[*client]struct{}
+	replay   []*Event // кольцевой буфер последних событий
+	replayAt int      // позиция для следующей записи в replay
+	// настройки
+	bufferSize       int
+	isDisconnectSlow bool
 }
 
-func (b *broker) handleEvents() {
-	go func() {
-		for {
-			select {
-			case s := <-b.newClients:
-				b.clients[s] = true
-			case s := <-b.defunctClients:
-				delete(b.clients, s)
-				close(s)
-			case msg := <-b.messages:
-				for s, _ := range b.clients {
-					s <- msg
-				}
-			}
-		}
-	}()
+// client одно SSE соединение
+type client struct {
+	userId string
+	topics []string
+	events chan *Event
+	// закрывается, если клиент не успевает получать события и политика disconnect
+	kick     chan struct{}
+	kickOnce sync.Once
 }
 
-// Send out a simple string to all clients.
-func (b *broker) sendString(msg string) {
-	b.messages <- msg
+func newBroker(bufferSize, replaySize int, isDisconnectSlow bool) *broker {
+	return &broker{
+		epoch:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		clients:          map[*client]struct{}{},
+		byTopic:          map[string]map[*client]struct{}{},
+		replay:           make([]*Event, replaySize),
+		bufferSize:       bufferSize,
+		isDisconnectSlow: isDisconnectSlow,
+	}
 }
 
-// Send out a JSON string object to all clients.
-func (b *broker) sendJSON(obj interface{}) {
-	tmp, err := json.Marshal(obj)
-	if err != nil {
-		fmt.Printf("broker.sendJSON error while sending JSON object: %s\n", err)
+// register подключение клиента. Возвращает события после lastEventId, которые нужно отправить до новых,
+// и признак что часть событий уже вытеснена из буфера (или получена от другого запуска) и клиенту нужно перечитать данные
+func (b *broker) register(cl *client, lastEventId string) (missed []*Event, isGap bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clients[cl] = struct{}{}
+	for _, t := range cl.topics {
+		m, ok := b.byTopic[t]
+		if !ok {
+			m = map[*client]struct{}{}
+			b.byTopic[t] = m
+		}
+		m[cl] = struct{}{}
+	}
+	atomic.AddInt64(&connectedClients, 1)
+	if len(lastEventId) == 0 {
+		return nil, false
 	}
-	b.messages <- string(tmp)
+	epoch, lastId := parseEventId(lastEventId)
+	if epoch != b.epoch {
+		return nil, true
+	}
+	if lastId >= b.lastId {
+		return nil, false
+	}
+	// события в буфере по порядку, начиная с самого старого
+	oldestId := b.lastId + 1
+	n := len(b.replay)
+	for i := 0; i < n; i++ {
+		ev := b.replay[(b.replayAt+i)%n]
+		if ev == nil {
+			continue
+		}
+		if ev.Id < oldestId {
+			oldestId = ev.Id
+		}
+		if ev.Id > lastId && cl.isSubscribed(ev.Topic) {
+			missed = append(missed, ev)
+		}
+	}
+	return missed, lastId+1 < oldestId
 }
 
-func (b *broker) subscribe(c *gin.Context) {
-	w := c.Writer
-	f, ok := w.(http.Flusher)
-	if !ok {
-		c.AbortWithError(http.StatusBadRequest, fmt.Errorf("Streaming unsupported"))
+func (b *broker) unregister(cl *client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.clients[cl]; !ok {
 		return
 	}
+	delete(b.clients, cl)
+	for _, t := range cl.topics {
+		if m, ok := b.byTopic[t]; ok {
+			delete(m, cl)
+			if len(m) == 0 {
+				delete(b.byTopic, t)
+			}
+		}
+	}
+	atomic.AddInt64(&connectedClients, -1)
+}
 
-	// Create a new channel, over which we can send this client messages.
-	messageChan := make(chan string)
-	// Add this client to the map of those that should receive updates
-	b.newClients <- messageChan
-
-	notify := w.CloseNotify()
-	go func() {
-		<-notify
-		// Remove this client from the map of attached clients
-		b.defunctClients <- messageChan
-	}()
-
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
+// addTopic подписка подключенного клиента на топик
+func (b *broker) addTopic(cl *client, topic string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.clients[cl]; !ok || cl.isSubscribed(topic) {
+		return
+	}
+	cl.topics = append(cl.topics, topic)
+	m, ok := b.byTopic[topic]
+	if !ok {
+		m = map[*client]struct{}{}
+		b.byTopic[topic] = m
+	}
+	m[cl] = struct{}{}
+}
 
-	for {
-		msg, open := <-messageChan
-		if !open {
-			// If our messageChan was closed, this means that
-			// the client has disconnected.
+// removeTopic отписка клиента от топика
+func (b *broker) removeTopic(cl *client, topic string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, t := range cl.topics {
+		if t == topic {
+			cl.topics = append(cl.topics[:i:i], cl.topics[i+1:]...)
 			break
 		}
+	}
+	if m, ok := b.byTopic[topic]; ok {
+		delete(m, cl)
+		if len(m) == 0 {
+			delete(b.byTopic, topic)
+		}
+	}
+}
 
-		fmt.Fprintf(w, "data: %s\n\n", msg)
-		// Flush the response. This is only possible if the repsonse supports streaming.
-		f.Flush()
+// publish присвоение номера, запись в буфер повтора и постановка в очереди подписчиков топика
+func (b *broker) publish(ev *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastId++
+	ev.Id = b.lastId
+	ev.epoch = b.epoch
+	if len(b.replay) > 0 {
+		b.replay[b.replayAt] = ev
+		b.replayAt = (b.replayAt + 1) % len(b.replay)
 	}
+	for cl := range b.byTopic[ev.Topic] {
+		select {
+		case cl.events <- ev:
+		default:
+			atomic.AddInt64(&droppedEvents, 1)
+			if b.isDisconnectSlow {
+				cl.kickOnce.Do(func() { close(cl.kick) })
+			}
+		}
+	}
+}
 
-	c.AbortWithStatus(http.StatusOK)
+func (cl *client) isSubscribed(topic string) bool {
+	for _, t := range cl.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// EventId значение поля id события: <epoch>-<номер>. По нему клиент запрашивает пропущенные события после переподключения
+func (ev *Event) EventId() string {
+	return ev.epoch + "-" + strconv.FormatInt(ev.Id, 10)
+}
+
+func parseEventId(s string) (epoch string, id int64) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return "", 0
+	}
+	id, _ = strconv.ParseInt(s[i+1:], 10, 64)
+	return s[:i], id
 }
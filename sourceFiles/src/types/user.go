@@ -18,6 +18,9 @@ type (
 		AuthProvider   string                 `json:"auth_provider"`
 		AuthProviderId string                 `json:"auth_provider_id"`
 		AuthToken      string                 `json:"auth_token,omitempty"`
+		RefreshToken   string                 `json:"refresh_token,omitempty"`     // только в ответе на вход и /auth/refresh
+		AccessExpiresIn int64                 `json:"access_expires_in,omitempty"` // через сколько секунд нужно обновить auth_token
+		SessionId      int64                  `json:"session_id,omitempty"`
 		Options        map[string]interface{} `json:"options"`
 		Deleted        bool                   `json:"deleted"`
 		Phone          string                 `json:"phone"`
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"github.com/gin-gonic/gin"
+	"github.com/tvitcom/nla_framework/logger"
 	"github.com/tvitcom/nla_framework/types"
 	"io/ioutil"
 	"mime/multipart"
@@ -13,7 +14,6 @@ import (
 	"encoding/json"
 	"crypto/rand"
 	"errors"
-	"log"
 	"strings"
 )
 
@@ -25,6 +25,8 @@ const (
 	GinContextGetRequestQueryId = "getRequestQueryId"
 	GinContextAppAuth           = "app_auth"
 	GinContextAppAuthId         = "app_auth_id"
+	GinContextRequestId         = "request_id"
+	GinContextSessionId         = "session_id"
 )
 
 var (
@@ -67,12 +69,24 @@ func HttpSuccess(c *gin.Context, res interface{}) {
 
 func CheckErr(err error, msg string) {
 	if err != nil {
-		log.Fatalf("%s: %s", msg, err)
+		logger.Fatalf("%s: %s", msg, err)
 	}
 }
 
 func Panic(msg string) {
-	log.Fatalf("%s", msg)
+	logger.Fatalf("%s", msg)
+}
+
+// RequestLog лог с полями запроса: request_id и user_id (если пользователь авторизован)
+func RequestLog(c *gin.Context) *logger.Entry {
+	fields := logger.Fields{}
+	if id, ok := c.Get(GinContextRequestId); ok {
+		fields["request_id"] = id
+	}
+	if userId, ok := c.Get(GinContextUserId); ok {
+		fields["user_id"] = userId
+	}
+	return logger.With(fields)
 }
 
 func MinInt(x, y int) int {
@@ -0,0 +1,247 @@
+package webServer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/tvitcom/nla_framework/lifecycle"
+	"github.com/tvitcom/nla_framework/logger"
+	"github.com/tvitcom/nla_framework/pg"
+	"github.com/tvitcom/nla_framework/types"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// apiAuditEntry запись журнала api_audit. Поля совпадают с колонками таблицы
+	apiAuditEntry struct {
+		UserId    int64       `json:"user_id"`
+		Role      []string    `json:"role"`
+		Method    string      `json:"method"`
+		Params    interface{} `json:"params"`
+		Ok        bool        `json:"ok"`
+		Message   string      `json:"message"`
+		LatencyMs int64       `json:"latency_ms"`
+		Ip        string      `json:"ip"`
+		IsCache   bool        `json:"is_cache"`
+		CreatedAt time.Time   `json:"created_at"`
+	}
+
+	// apiAuditCall вызов pg метода, который записывается в журнал при завершении (finish)
+	apiAuditCall struct {
+		c         *gin.Context
+		user      *types.User
+		jsonParam JsonParamType
+		startedAt time.Time
+		ok        bool
+		message   string
+		isCache   bool
+	}
+)
+
+var (
+	apiAuditConfig  = apiAuditWithDefaults(types.ApiAudit{})
+	apiAuditQueue   chan apiAuditEntry
+	apiAuditDropped uint64
+	apiAuditStop    = make(chan struct{})
+	apiAuditDone    = make(chan struct{})
+	// значения параметров, которые не сохраняются в журнал
+	apiAuditRedacted = "***"
+)
+
+// startApiAudit запуск записи журнала api_audit. Записи копятся в очереди и пишутся в postgres пачками в отдельной горутине,
+// чтобы не задерживать ответы api
+func startApiAudit(config types.ApiAudit) {
+	apiAuditConfig = apiAuditWithDefaults(config)
+	if !apiAuditConfig.IsEnabled {
+		return
+	}
+	apiAuditQueue = make(chan apiAuditEntry, apiAuditConfig.BufferSize)
+	go apiAuditWriter()
+	lifecycle.Register("api audit", stopApiAudit)
+}
+
+// stopApiAudit запись оставшейся очереди при остановке приложения. Вызывается после остановки веб-сервера
+func stopApiAudit(ctx context.Context) error {
+	close(apiAuditStop)
+	select {
+	case <-apiAuditDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func apiAuditWithDefaults(config types.ApiAudit) types.ApiAudit {
+	if len(config.RedactFields) == 0 {
+		config.RedactFields = []string{"password", "token", "auth_token", "refresh_token", "secret", "sms_code", "code"}
+	}
+	redactFields := make([]string, 0, len(config.RedactFields))
+	for _, v := range config.RedactFields {
+		redactFields = append(redactFields, strings.ToLower(v))
+	}
+	config.RedactFields = redactFields
+	if config.RetentionDays <= 0 {
+		config.RetentionDays = 90
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 1000
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+	return config
+}
+
+func newApiAuditCall(c *gin.Context, user *types.User, jsonParam JsonParamType) *apiAuditCall {
+	return &apiAuditCall{c: c, user: user, jsonParam: jsonParam, startedAt: time.Now()}
+}
+
+func (a *apiAuditCall) fail(message string) {
+	a.ok = false
+	a.message = message
+}
+
+func (a *apiAuditCall) done(ok bool, message string, isCache bool) {
+	a.ok = ok
+	a.message = message
+	a.isCache = isCache
+}
+
+// finish постановка записи в очередь. Параметры копируются с заменой скрытых значений, поэтому
+// дальнейшие изменения jsonParam.Params на запись не влияют
+func (a *apiAuditCall) finish() {
+	if apiAuditQueue == nil {
+		return
+	}
+	entry := apiAuditEntry{
+		Method:    a.jsonParam.Method,
+		Params:    apiAuditRedact(a.jsonParam.Params),
+		Ok:        a.ok,
+		Message:   a.message,
+		LatencyMs: time.Since(a.startedAt).Milliseconds(),
+		Ip:        a.c.ClientIP(),
+		IsCache:   a.isCache,
+		CreatedAt: a.startedAt,
+	}
+	if a.user != nil {
+		entry.UserId = a.user.Id
+		entry.Role = a.user.Role
+	}
+	select {
+	case apiAuditQueue <- entry:
+	default:
+		// очередь переполнена (postgres не успевает) - запись теряется, но запрос не ждет
+		if atomic.AddUint64(&apiAuditDropped, 1)%1000 == 1 {
+			logger.Warnf("api_audit queue is full, dropped %v entries", atomic.LoadUint64(&apiAuditDropped))
+		}
+	}
+}
+
+// apiAuditRedact копия параметров, в которой значения полей из RedactFields заменены на ***
+func apiAuditRedact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, fld := range val {
+			if apiAuditIsRedactField(k) {
+				res[k] = apiAuditRedacted
+			} else {
+				res[k] = apiAuditRedact(fld)
+			}
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, fld := range val {
+			res[i] = apiAuditRedact(fld)
+		}
+		return res
+	case string:
+		// параметры могут прийти строкой с json
+		trimmed := strings.TrimSpace(val)
+		if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+				return apiAuditRedact(parsed)
+			}
+		}
+	}
+	return v
+}
+
+func apiAuditIsRedactField(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range apiAuditConfig.RedactFields {
+		if name == f {
+			return true
+		}
+	}
+	return false
+}
+
+// запись очереди в postgres: при накоплении BatchSize записей или раз в FlushInterval. Раз в час удаляются старые записи
+func apiAuditWriter() {
+	defer close(apiAuditDone)
+	batch := make([]apiAuditEntry, 0, apiAuditConfig.BatchSize)
+	flushTicker := time.NewTicker(time.Duration(apiAuditConfig.FlushInterval) * time.Millisecond)
+	retentionTicker := time.NewTicker(time.Hour)
+	defer flushTicker.Stop()
+	defer retentionTicker.Stop()
+	apiAuditCleanup()
+	for {
+		select {
+		case entry := <-apiAuditQueue:
+			batch = append(batch, entry)
+			if int64(len(batch)) >= apiAuditConfig.BatchSize {
+				apiAuditFlush(batch)
+				batch = batch[:0]
+			}
+		case <-flushTicker.C:
+			if len(batch) > 0 {
+				apiAuditFlush(batch)
+				batch = batch[:0]
+			}
+		case <-retentionTicker.C:
+			apiAuditCleanup()
+		case <-apiAuditStop:
+			for len(apiAuditQueue) > 0 {
+				batch = append(batch, <-apiAuditQueue)
+				if int64(len(batch)) >= apiAuditConfig.BatchSize {
+					apiAuditFlush(batch)
+					batch = batch[:0]
+				}
+			}
+			if len(batch) > 0 {
+				apiAuditFlush(batch)
+			}
+			return
+		}
+	}
+}
+
+func apiAuditFlush(batch []apiAuditEntry) {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		logger.Errorf("api_audit marshal error: %s", err)
+		return
+	}
+	_, err = pg.Pg.Exec(`insert into api_audit (user_id, role, method, params, ok, message, latency_ms, ip, is_cache, created_at)
+		select user_id, role, method, params, ok, message, latency_ms, ip, is_cache, created_at
+		from jsonb_to_recordset($1::jsonb) as t(user_id int, role text[], method text, params jsonb, ok bool, message text,
+			latency_ms int, ip text, is_cache bool, created_at timestamptz)`, string(data))
+	if err != nil {
+		logger.Errorf("api_audit insert error: %s", err)
+	}
+}
+
+func apiAuditCleanup() {
+	_, err := pg.Pg.Exec("delete from api_audit where created_at < now() - $1::int * interval '1 day'", apiAuditConfig.RetentionDays)
+	if err != nil {
+		logger.Errorf("api_audit cleanup error: %s", err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/tvitcom/nla_framework/logger"
 	"github.com/tvitcom/nla_framework/pg"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
@@ -79,7 +80,7 @@ func EmailAuth(c *gin.Context) {
 		href := fmt.Sprintf("%s/check_user_email?t=%v", webServerConfig.Url, userRegData.Token)
 		err = utils.EmailSendRegistrationConfirm(userRegData.Email, href)
 		if err != nil {
-			fmt.Printf("utils.EmailSendRegistrationConfirm: %s", err)
+			logger.Errorf("utils.EmailSendRegistrationConfirm: %s", err)
 		}
 		// в независимости от результатов отправки письма отправляем, что данный этап регистрации успешно пройден
 		utils.HttpSuccess(c, nil)
@@ -90,19 +91,20 @@ func EmailAuth(c *gin.Context) {
 		jsonStr, err := json.Marshal(userRegData)
 		err = pg.CallPgFunc("user_get_by_email_with_password", jsonStr, &user, nil)
 		if err != nil {
+			authFailure(rateLimitKindLogin, userRegData.Login)
 			utils.HttpError(c, http.StatusOK, fmt.Sprintf("pg call user_get_by_email_with_password err %s", err))
 			return
 		}
 		// проверяем пароль
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(reqParams.Params.Password))
 		if err != nil {
+			authFailure(rateLimitKindLogin, userRegData.Login)
 			utils.HttpError(c, http.StatusOK, "wrong password")
 			return
 		}
-		// перед отправкой в данных пользователя стираем пароль
-		user.Password = ""
-
-		utils.HttpSuccess(c, user)
+		authSuccess(rateLimitKindLogin, userRegData.Login)
+		// создаем сессию, пароль стирается перед отправкой
+		SessionStart(c, &user)
 	}
 }
 
@@ -133,7 +135,7 @@ func EmailAuthCheckUserEmail(c *gin.Context) {
 		return
 	}
 
-	utils.HttpSuccess(c, user)
+	SessionStart(c, &user)
 }
 
 // функция начала сброса пароля. Создаем пару email-токен и отправляем пользователю письмо со ссылкой для восстановления пароля
@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"github.com/tvitcom/nla_framework/logger"
+	"github.com/tvitcom/nla_framework/pg"
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// RateLimitStore хранилище счетчиков запросов. По умолчанию в памяти, для нескольких экземпляров приложения - в postgres
+	RateLimitStore interface {
+		// Incr увеличивает счетчик и возвращает новое значение и время до сброса. Счетчик сбрасывается через window после первого увеличения
+		Incr(key string, window time.Duration) (cnt int64, ttl time.Duration, err error)
+		// Get текущее значение счетчика и время до сброса
+		Get(key string) (cnt int64, ttl time.Duration, err error)
+		Reset(key string) error
+	}
+
+	memRateLimitStore struct {
+		mu    sync.Mutex
+		items map[string]memRateLimitItem
+		calls int
+	}
+
+	memRateLimitItem struct {
+		cnt       int64
+		expiredAt time.Time
+	}
+
+	// хранение счетчиков в таблице auth_rate_limit
+	pgRateLimitStore struct {
+		calls uint64
+	}
+)
+
+const (
+	rateLimitKindIp    = "ip"
+	rateLimitKindLogin = "login"
+	rateLimitKindPhone = "phone"
+)
+
+var (
+	rateLimitConfig                = rateLimitWithDefaults(types.AuthRateLimit{})
+	rateLimitStore  RateLimitStore = &memRateLimitStore{items: map[string]memRateLimitItem{}}
+)
+
+// SetRateLimitConfig настройки ограничения запросов из секции authRateLimit в config.toml
+func SetRateLimitConfig(config types.AuthRateLimit) {
+	rateLimitConfig = rateLimitWithDefaults(config)
+	if config.IsPgStore {
+		rateLimitStore = &pgRateLimitStore{}
+	}
+}
+
+// SetRateLimitStore замена хранилища счетчиков, например на redis
+func SetRateLimitStore(store RateLimitStore) {
+	rateLimitStore = store
+}
+
+func rateLimitWithDefaults(config types.AuthRateLimit) types.AuthRateLimit {
+	if config.Window <= 0 {
+		config.Window = 600
+	}
+	if config.PerIp <= 0 {
+		config.PerIp = 100
+	}
+	if config.PerLogin <= 0 {
+		config.PerLogin = 10
+	}
+	if config.PerPhone <= 0 {
+		config.PerPhone = 5
+	}
+	if config.LockoutFailures <= 0 {
+		config.LockoutFailures = 5
+	}
+	if config.LockoutDuration <= 0 {
+		config.LockoutDuration = 900
+	}
+	return config
+}
+
+// RateLimitByIp ограничение количества запросов к /auth с одного ip
+func RateLimitByIp(c *gin.Context) {
+	if rateLimitConfig.IsDisabled {
+		return
+	}
+	rateLimitCheck(c, rateLimitKindIp+":"+c.ClientIP(), rateLimitConfig.PerIp)
+}
+
+// RateLimitByLogin ограничение количества запросов на один email (params.login или params.email) и проверка блокировки логина
+func RateLimitByLogin(c *gin.Context) {
+	if rateLimitConfig.IsDisabled {
+		return
+	}
+	login := normalizeLogin(rateLimitBodyParam(c, "login", "email"))
+	rateLimitByValue(c, rateLimitKindLogin, login, rateLimitConfig.PerLogin)
+}
+
+// RateLimitByPhone ограничение количества запросов на один телефон (params.phone или params.login) и проверка блокировки телефона.
+// Ограничивает в том числе количество отправленных sms
+func RateLimitByPhone(c *gin.Context) {
+	if rateLimitConfig.IsDisabled {
+		return
+	}
+	phone := normalizePhone(rateLimitBodyParam(c, "phone", "login"))
+	rateLimitByValue(c, rateLimitKindPhone, phone, rateLimitConfig.PerPhone)
+}
+
+func rateLimitByValue(c *gin.Context, kind, value string, limit int64) {
+	if len(value) == 0 {
+		return
+	}
+	if ttl, locked := isAuthLocked(kind, value); locked {
+		rateLimitReject(c, ttl, "too many failed attempts, try later")
+		return
+	}
+	// для каждого роута свой счетчик, чтобы, например, ввод кода из sms не расходовал лимит на отправку sms
+	rateLimitCheck(c, fmt.Sprintf("%s:%s:%s", kind, c.FullPath(), value), limit)
+}
+
+func rateLimitCheck(c *gin.Context, key string, limit int64) {
+	cnt, ttl, err := rateLimitStore.Incr(key, time.Duration(rateLimitConfig.Window)*time.Second)
+	if err != nil {
+		// при недоступности хранилища запросы не блокируем
+		logger.Errorf("rate limit store error: %s", err)
+		return
+	}
+	if cnt > limit {
+		rateLimitReject(c, ttl, "too many requests, try later")
+	}
+}
+
+func rateLimitReject(c *gin.Context, ttl time.Duration, msg string) {
+	c.Header("Retry-After", strconv.Itoa(int(ttl.Seconds())+1))
+	utils.HttpError(c, http.StatusTooManyRequests, msg)
+}
+
+// authFailure неудачная попытка входа или проверки кода. После LockoutFailures попыток за период логин блокируется на LockoutDuration
+func authFailure(kind, value string) {
+	if rateLimitConfig.IsDisabled || len(value) == 0 {
+		return
+	}
+	cnt, _, err := rateLimitStore.Incr("fail:"+kind+":"+value, time.Duration(rateLimitConfig.Window)*time.Second)
+	if err != nil {
+		logger.Errorf("rate limit store error: %s", err)
+		return
+	}
+	if cnt >= rateLimitConfig.LockoutFailures {
+		logger.Warnf("auth %s '%s' locked after %v failed attempts", kind, value, cnt)
+		rateLimitStore.Incr("lock:"+kind+":"+value, time.Duration(rateLimitConfig.LockoutDuration)*time.Second)
+		rateLimitStore.Reset("fail:" + kind + ":" + value)
+	}
+}
+
+// authSuccess успешный вход - сбрасываем счетчик неудачных попыток
+func authSuccess(kind, value string) {
+	if rateLimitConfig.IsDisabled || len(value) == 0 {
+		return
+	}
+	rateLimitStore.Reset("fail:" + kind + ":" + value)
+}
+
+func isAuthLocked(kind, value string) (time.Duration, bool) {
+	cnt, ttl, err := rateLimitStore.Get("lock:" + kind + ":" + value)
+	if err != nil {
+		logger.Errorf("rate limit store error: %s", err)
+		return 0, false
+	}
+	return ttl, cnt > 0
+}
+
+// значение params.<name> из json тела запроса. Тело восстанавливается, чтобы его можно было прочитать в обработчике
+func rateLimitBodyParam(c *gin.Context, names ...string) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	for _, name := range names {
+		if v := gjson.GetBytes(body, "params."+name).String(); len(v) > 0 {
+			return v
+		}
+	}
+	return ""
+}
+
+func normalizeLogin(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
+
+// приводим телефон к виду 7XXXXXXXXXX, как в обработчиках авторизации по телефону
+func normalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	if strings.HasPrefix(phone, "8") {
+		phone = "7" + strings.TrimPrefix(phone, "8")
+	}
+	return phone
+}
+
+func (s *memRateLimitStore) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	// периодически удаляем просроченные счетчики
+	s.calls++
+	if s.calls%1000 == 0 {
+		for k, v := range s.items {
+			if !v.expiredAt.After(now) {
+				delete(s.items, k)
+			}
+		}
+	}
+	item, ok := s.items[key]
+	if !ok || !item.expiredAt.After(now) {
+		item = memRateLimitItem{expiredAt: now.Add(window)}
+	}
+	item.cnt++
+	s.items[key] = item
+	return item.cnt, item.expiredAt.Sub(now), nil
+}
+
+func (s *memRateLimitStore) Get(key string) (int64, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	item, ok := s.items[key]
+	if !ok || !item.expiredAt.After(now) {
+		return 0, 0, nil
+	}
+	return item.cnt, item.expiredAt.Sub(now), nil
+}
+
+func (s *memRateLimitStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, key)
+	return nil
+}
+
+func (s *pgRateLimitStore) Incr(key string, window time.Duration) (int64, time.Duration, error) {
+	// периодически удаляем просроченные счетчики
+	if atomic.AddUint64(&s.calls, 1)%1000 == 0 {
+		if _, err := pg.Pg.Exec("delete from auth_rate_limit where expired_at <= now()"); err != nil {
+			logger.Errorf("auth_rate_limit cleanup error: %s", err)
+		}
+	}
+	var cnt int64
+	var ttl float64
+	err := pg.Pg.QueryRow(`insert into auth_rate_limit as t (bucket, cnt, expired_at) values ($1, 1, now() + $2::float8 * interval '1 second')
+		on conflict (bucket) do update set
+			cnt = case when t.expired_at <= now() then 1 else t.cnt + 1 end,
+			expired_at = case when t.expired_at <= now() then excluded.expired_at else t.expired_at end
+		returning cnt, extract(epoch from expired_at - now())::float8`, key, window.Seconds()).Scan(&cnt, &ttl)
+	return cnt, time.Duration(ttl * float64(time.Second)), err
+}
+
+func (s *pgRateLimitStore) Get(key string) (int64, time.Duration, error) {
+	var cnt int64
+	var ttl float64
+	err := pg.Pg.QueryRow("select cnt, extract(epoch from expired_at - now())::float8 from auth_rate_limit where bucket = $1 and expired_at > now()", key).Scan(&cnt, &ttl)
+	if err == sql.ErrNoRows {
+		return 0, 0, nil
+	}
+	return cnt, time.Duration(ttl * float64(time.Second)), err
+}
+
+func (s *pgRateLimitStore) Reset(key string) error {
+	_, err := pg.Pg.Exec("delete from auth_rate_limit where bucket = $1", key)
+	return err
+}
@@ -3,13 +3,14 @@ package webServer
 import (
 	"github.com/gin-gonic/gin"
 	"fmt"
-	"github.com/tvitcom/nla_framework/graylog"
+	"github.com/tvitcom/nla_framework/logger"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
 	"net/http"
 )
 
-func logToGraylog(c *gin.Context) {
+// logFromClient запись в лог приложения с клиента. type: error, warn или info
+func logFromClient(c *gin.Context) {
 
 	var user *types.User
 
@@ -37,25 +38,26 @@ func logToGraylog(c *gin.Context) {
 		}
 	}
 
-	msgAttrs := map[string]interface{}{"type": logMsg.Code}
+	msgAttrs := logger.Fields{"source": "client", "type": logMsg.Code}
 	if user != nil {
-		msgAttrs["user_id"] = user.Id
 		msgAttrs["fullname"] = user.Fullname
 	}
 	for k, v := range logMsg.Msg {
+		// request_id и user_id берутся из запроса, клиент их не подменяет
+		if k == "request_id" || k == "user_id" {
+			continue
+		}
 		msgAttrs[k] = v
 	}
 
-	if logMsg.Type == "error" {
-		err := graylog.Graylog.L().Errm(msgAttrs, fmt.Sprintf("%s", logMsg.Msg))
-		if err != nil {
-			fmt.Printf("logToGraylog send to graylog error %s\n", err)
-		}
-	} else {
-		err := graylog.Graylog.L().Infom(msgAttrs, fmt.Sprintf("%s", logMsg.Msg))
-		if err != nil {
-			fmt.Printf("logToGraylog send to graylog error %s\n", err)
-		}
+	l := utils.RequestLog(c).With(msgAttrs)
+	switch logMsg.Type {
+	case "error":
+		l.Errorf("%s", logMsg.Msg)
+	case "warn":
+		l.Warnf("%s", logMsg.Msg)
+	default:
+		l.Infof("%s", logMsg.Msg)
 	}
 	utils.HttpSuccess(c, "ok")
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/tvitcom/nla_framework/pg"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
+	"github.com/tvitcom/nla_framework/webServer/auth"
 	"net/http"
 	"strings"
 	"time"
@@ -57,19 +58,48 @@ func authRequired(c *gin.Context) {
 		return
 	}
 
-	user, err := userFindByAuthToken(authToken)
+	user, err := userFindByToken(authToken)
 	if err != nil {
 		utils.HttpError(c, http.StatusOK, fmt.Sprintf("%s", err))
 		return
 	}
 	c.Set(utils.GinContextUser, user)
 	c.Set(utils.GinContextUserId, user.Id)
+	c.Set(utils.GinContextSessionId, user.SessionId)
 }
 
-// LiberalCORS is a very allowing CORS middleware.
+// проверка токена авторизации для REST api (/api/v1). В отличие от authRequired тело запроса не читается - в нем передается документ.
+// Токен ищется в header'ах Auth-token и Authorization: Bearer, затем в query параметре authToken
+func restAuthRequired(c *gin.Context) {
+	authToken := c.Request.Header.Get("Auth-token")
+	if len(authToken) == 0 {
+		if h := c.Request.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			authToken = strings.TrimPrefix(h, "Bearer ")
+		}
+	}
+	if len(authToken) == 0 {
+		authToken = c.Query("authToken")
+	}
+	if len(authToken) == 0 {
+		utils.HttpError(c, http.StatusUnauthorized, "missed auth_token")
+		return
+	}
+
+	user, err := userFindByToken(authToken)
+	if err != nil {
+		utils.HttpError(c, http.StatusUnauthorized, fmt.Sprintf("%s", err))
+		return
+	}
+	c.Set(utils.GinContextUser, user)
+	c.Set(utils.GinContextUserId, user.Id)
+	c.Set(utils.GinContextSessionId, user.SessionId)
+}
+
+// LiberalCORS is a very allowing CORS middleware. Используется в dev режиме, в production - Cors
 func LiberalCORS(c *gin.Context) {
 	c.Header("Access-Control-Allow-Origin", "*")
 	if c.Request.Method == "OPTIONS" {
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
 		if len(c.Request.Header["Access-Control-Request-Headers"]) > 0 {
 			c.Header("Access-Control-Allow-Headers", c.Request.Header["Access-Control-Request-Headers"][0])
 		}
@@ -77,6 +107,19 @@ func LiberalCORS(c *gin.Context) {
 	}
 }
 
+// поиск пользователя по access токену сессии (auth.SessionFindByAccessToken). Если разрешено в authSession.isLegacyTokenAllowed,
+// то затем по бессрочному токену из user_auth. Если сессии отключены - только по бессрочному токену
+func userFindByToken(token string) (*types.User, error) {
+	if auth.IsSessionDisabled() {
+		return userFindByAuthToken(token)
+	}
+	user, err := auth.SessionFindByAccessToken(token)
+	if err == auth.ErrInvalidToken && auth.IsLegacyTokenAllowed() {
+		return userFindByAuthToken(token)
+	}
+	return user, err
+}
+
 func userFindByAuthToken(token string) (user *types.User, err error) {
 	// ищем пользователя в кэше
 	userIntreface, _ := cacheUtil.GoCacheGet(cacheUtil.GetCacheKeyUserToken(token))
@@ -0,0 +1,45 @@
+package webServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/xid"
+	"github.com/tvitcom/nla_framework/logger"
+	"github.com/tvitcom/nla_framework/utils"
+	"strings"
+	"time"
+)
+
+// requestId идентификатор запроса для связи записей лога. Берется из заголовка X-Request-Id (от балансировщика) или генерируется.
+// Возвращается клиенту в том же заголовке
+func requestId(c *gin.Context) {
+	id := c.GetHeader("X-Request-Id")
+	if len(id) == 0 || len(id) > 64 {
+		id = xid.New().String()
+	}
+	c.Set(utils.GinContextRequestId, id)
+	c.Header("X-Request-Id", id)
+	c.Next()
+}
+
+// requestLog запись о каждом запросе. Запросы к api и авторизации - уровень info, статика - debug, ошибки сервера - error
+func requestLog(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	status := c.Writer.Status()
+	path := c.Request.URL.Path
+	l := utils.RequestLog(c).With(logger.Fields{
+		"method":     c.Request.Method,
+		"path":       path,
+		"status":     status,
+		"latency_ms": time.Since(start).Milliseconds(),
+		"ip":         c.ClientIP(),
+	})
+	switch {
+	case status >= 500:
+		l.Errorf("%s %s %v", c.Request.Method, path, status)
+	case strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/auth/"):
+		l.Infof("%s %s %v", c.Request.Method, path, status)
+	default:
+		l.Debugf("%s %s %v", c.Request.Method, path, status)
+	}
+}
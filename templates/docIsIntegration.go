@@ -6,7 +6,6 @@ import (
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
 	"github.com/serenize/snaker"
-	"log"
 	"text/template"
 )
 
@@ -80,7 +79,7 @@ func docIsIntegrationBitrixProccess(p types.ProjectType, d *types.DocType) {
 	for k, v := range funcMap {
 		localFuncMap[k] = v
 	}
-	t, err := template.New("bitrixDoc.go").Funcs(localFuncMap).Delims("[[", "]]").ParseFiles(sourcePath)
+	t, err := utils.ParseTemplateFiles(template.New("bitrixDoc.go").Funcs(localFuncMap).Delims("[[", "]]"), sourcePath)
 	utils.CheckErr(err, "bitrixDoc.go")
 	distPath := fmt.Sprintf("%s/bitrix", p.DistPath)
 	d.Templates["webClient_comp_bitrixDoc.go"] = &types.DocTemplate{Tmpl: t, DistPath: distPath, DistFilename: snaker.SnakeToCamelLower(d.Name) + ".go"}
@@ -91,7 +90,7 @@ func getBtxFld(d types.DocType, fld types.FldType) types.BitrixFld  {
 		if btxFld, ok := btxFldInt.(types.BitrixFld); ok {
 			return btxFld
 		} else {
-			log.Fatalf("docIsIntegrationBitrixProccess doc: '%s' fld: '%s' not BitrixFld", d.Name, fld.Name)
+			utils.Fatalf("docIsIntegrationBitrixProccess doc: '%s' fld: '%s' not BitrixFld", d.Name, fld.Name)
 		}
 	}
 	return types.BitrixFld{}
@@ -168,7 +167,7 @@ func docIsIntegrationOdataProccess(p types.ProjectType, d *types.DocType) {
 	for k, v := range funcMap {
 		localFuncMap[k] = v
 	}
-	t, err := template.New("odataDoc.go").Funcs(localFuncMap).Delims("[[", "]]").ParseFiles(sourcePath)
+	t, err := utils.ParseTemplateFiles(template.New("odataDoc.go").Funcs(localFuncMap).Delims("[[", "]]"), sourcePath)
 	utils.CheckErr(err, "odataDoc.go")
 	distPath := fmt.Sprintf("%s/odata", p.DistPath)
 	d.Templates["webClient_comp_odataDoc.go"] = &types.DocTemplate{Tmpl: t, DistPath: distPath, DistFilename: snaker.SnakeToCamelLower(d.Name) + ".go"}
@@ -179,7 +178,7 @@ func getOdataFld(d types.DocType, fld types.FldType) types.OdataFld  {
 		if odataFld, ok := odataFldInt.(types.OdataFld); ok {
 			return odataFld
 		} else {
-			log.Fatalf("docIsIntegrationOdataProccess doc: '%s' fld: '%s' not OdataFld", d.Name, fld.Name)
+			utils.Fatalf("docIsIntegrationOdataProccess doc: '%s' fld: '%s' not OdataFld", d.Name, fld.Name)
 		}
 	}
 	return types.OdataFld{}
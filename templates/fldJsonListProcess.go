@@ -27,7 +27,7 @@ func fldJsonListProccess(p types.ProjectType, d *types.DocType, fld *types.FldTy
 				}
 			}
 		}
-		t, err := template.New("fldJsonList.vue").Funcs(funcMap).Delims("[[", "]]").ParseFiles(sourcePath)
+		t, err := utils.ParseTemplateFiles(template.New("fldJsonList.vue").Funcs(funcMap).Delims("[[", "]]"), sourcePath)
 		utils.CheckErr(err, "fldJsonList.vue")
 		dPath := d.Name
 		if len(d.Vue.Path) > 0 {
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"text/template"
+)
+
+type (
+	// GeneratorTarget - подсистема проекта, которая генерирует свой набор файлов (авторизация по телефону, интеграции и т.д.).
+	// Таргеты генерируются после основных файлов проекта в порядке регистрации
+	GeneratorTarget interface {
+		Name() string
+		IsEnabled(p types.ProjectType) bool
+		Files(p types.ProjectType) []TargetFile
+		FuncMap() template.FuncMap // дополнительные функции для шаблонов таргета. Добавляются к стандартному funcMap
+	}
+
+	// TargetFile - шаблон и место, куда пишется результат
+	TargetFile struct {
+		Source   string // путь к файлу шаблона
+		DistPath string // директория относительно DistPath проекта, например "/webServer"
+		Filename string
+	}
+
+	// Target - реализация GeneratorTarget через поля структуры, чтобы не объявлять отдельный тип под каждый таргет
+	Target struct {
+		TargetName string
+		Enabled    func(p types.ProjectType) bool // если nil, то таргет генерируется всегда
+		FileList   func(p types.ProjectType) []TargetFile
+		Funcs      template.FuncMap
+	}
+)
+
+// зарегистрированные таргеты в порядке генерации
+var targets []GeneratorTarget
+
+// RegisterTarget добавляет таргет в конец списка генерации. Имя таргета должно быть уникальным.
+// Регистрировать нужно до вызова Start
+func RegisterTarget(t GeneratorTarget) {
+	if t == nil || len(t.Name()) == 0 {
+		utils.Fatalf("RegisterTarget: target name is empty")
+	}
+	for _, v := range targets {
+		if v.Name() == t.Name() {
+			utils.Fatalf("RegisterTarget: target '%s' already registered", t.Name())
+		}
+	}
+	targets = append(targets, t)
+}
+
+// GetTargets список зарегистрированных таргетов в порядке генерации
+func GetTargets() []GeneratorTarget {
+	return append([]GeneratorTarget{}, targets...)
+}
+
+// GetEnabledTargets таргеты, которые будут сгенерированы для проекта
+func GetEnabledTargets(p types.ProjectType) []GeneratorTarget {
+	res := []GeneratorTarget{}
+	for _, t := range targets {
+		if t.IsEnabled(p) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+func generateTargets(p types.ProjectType) {
+	for _, t := range GetEnabledTargets(p) {
+		for _, f := range t.Files(p) {
+			ReadTmplAndPrint(p, f.Source, f.DistPath, f.Filename, t.FuncMap())
+		}
+	}
+}
+
+func (t Target) Name() string {
+	return t.TargetName
+}
+
+func (t Target) IsEnabled(p types.ProjectType) bool {
+	return t.Enabled == nil || t.Enabled(p)
+}
+
+func (t Target) Files(p types.ProjectType) []TargetFile {
+	if t.FileList == nil {
+		return nil
+	}
+	return t.FileList(p)
+}
+
+func (t Target) FuncMap() template.FuncMap {
+	return t.Funcs
+}
@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"fmt"
+	"github.com/tvitcom/nla_framework/types"
+)
+
+// встроенные таргеты фреймворка. Порядок регистрации - порядок генерации
+func init() {
+	RegisterTarget(Target{TargetName: "openApi", FileList: openApiTargetFiles})
+	RegisterTarget(Target{TargetName: "pgMethodPolicy", Enabled: types.ProjectType.IsPgMethodPolicy, FileList: pgMethodPolicyTargetFiles})
+	RegisterTarget(Target{TargetName: "restApi", Enabled: types.ProjectType.IsRestApi, FileList: restApiTargetFiles})
+	RegisterTarget(Target{TargetName: "realtime", Enabled: types.ProjectType.IsRealtime, FileList: realtimeTargetFiles})
+	RegisterTarget(Target{TargetName: "goClient", Enabled: types.ProjectType.IsGoClient, FileList: goClientTargetFiles})
+	RegisterTarget(Target{TargetName: "tsTypes", FileList: tsTypesTargetFiles})
+	RegisterTarget(Target{TargetName: "authRateLimitPgStore", Enabled: func(p types.ProjectType) bool { return p.Config.Auth.RateLimit.IsPgStore }, FileList: authRateLimitPgStoreTargetFiles})
+	RegisterTarget(Target{TargetName: "userSession", Enabled: types.ProjectType.IsAuthSession, FileList: userSessionTargetFiles})
+	RegisterTarget(Target{TargetName: "authPhone", Enabled: func(p types.ProjectType) bool { return p.Config.Auth.ByPhone }, FileList: authPhoneTargetFiles})
+	RegisterTarget(Target{TargetName: "apiAudit", Enabled: types.ProjectType.IsApiAudit, FileList: apiAuditTargetFiles})
+	RegisterTarget(Target{TargetName: "telegram", Enabled: types.ProjectType.IsTelegramIntegration, FileList: telegramTargetFiles})
+	RegisterTarget(Target{TargetName: "yandexDiskBackup", Enabled: types.ProjectType.IsBackupOnYandexDisk, FileList: yandexDiskBackupTargetFiles})
+	RegisterTarget(Target{TargetName: "bitrix", Enabled: types.ProjectType.IsBitrixIntegration, FileList: bitrixTargetFiles})
+	RegisterTarget(Target{TargetName: "odata", Enabled: types.ProjectType.IsOdataIntegration, FileList: odataTargetFiles})
+}
+
+func openApiTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/webServer/openapi.json", "/webServer", "openapi.json"},
+	}
+}
+
+// кэш и цепочки hook'ов pg методов (DocSqlMethod.Cache, DocSqlMethod.Hooks)
+func pgMethodPolicyTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/webServer/pgMethodHooks.go", "/webServer", "pgMethodHooks.go"},
+	}
+}
+
+// роуты /api/v1/<doc> документов с методами list, get_by_id или update
+func restApiTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/webServer/restApi.go", "/webServer", "restApi.go"},
+	}
+}
+
+// проверка подписки на топики документов с рассылкой изменений (DocType.Realtime)
+func realtimeTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/webServer/realtime.go", "/webServer", "realtime.go"},
+	}
+}
+
+func goClientTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/pgClient/main.go", "/pgClient", "main.go"},
+		{getCurrentDir() + "/project/pgClient/docs.go", "/pgClient", "docs.go"},
+	}
+}
+
+// модуль подключается и в quasar_1 и в quasar_2: import {callPgFunc, PgMethod} from 'src/app/plugins/pgApi'
+func tsTypesTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/webClient/pgApi.js", "/webClient/src/app/plugins", "pgApi.js"},
+		{getCurrentDir() + "/project/webClient/pgApi.d.ts", "/webClient/src/app/plugins", "pgApi.d.ts"},
+	}
+}
+
+// таблица auth_rate_limit для счетчиков запросов авторизации в postgres
+func authRateLimitPgStoreTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/project/sql/05_AuthRateLimit/main.toml", "/sql/model/05_AuthRateLimit", "main.toml"},
+	}
+}
+
+// сессии пользователей: таблица user_session, sql функции и экран /user_session
+func userSessionTargetFiles(p types.ProjectType) []TargetFile {
+	projectTmplPath := getCurrentDir() + "/project"
+	webClient := fmt.Sprintf("%s/webClient/quasar_%v", projectTmplPath, p.GetQuasarVersion())
+	res := []TargetFile{
+		{projectTmplPath + "/sql/06_UserSession/main.toml", "/sql/model/06_UserSession", "main.toml"},
+	}
+	for _, name := range []string{"user_session_create", "user_session_get_by_access_token", "user_session_refresh", "user_session_list", "user_session_revoke", "current_user_session_list", "current_user_session_revoke"} {
+		res = append(res, TargetFile{projectTmplPath + "/sql/06_UserSession/" + name + ".sql", "/sql/template/function/_UserSession", name + ".sql"})
+	}
+	return append(res, TargetFile{webClient + "/app/components/userSession/index.vue", "/webClient/src/app/components/userSession", "index.vue"})
+}
+
+func authPhoneTargetFiles(p types.ProjectType) []TargetFile {
+	projectTmplPath := getCurrentDir() + "/project"
+	webClient := fmt.Sprintf("%s/webClient/quasar_%v", projectTmplPath, p.GetQuasarVersion())
+	return []TargetFile{
+		{projectTmplPath + "/sql/01_User/user_get_by_phone_with_password.sql", "/sql/template/function/_User", "user_get_by_phone_with_password.sql"},
+		{projectTmplPath + "/sql/03_UserTempEmailAuth/user_temp_phone_auth_create.sql", "/sql/template/function/_UserTempEmailAuth", "user_temp_phone_auth_create.sql"},
+		{projectTmplPath + "/sql/03_UserTempEmailAuth/user_temp_phone_auth_check_sms_code.sql", "/sql/template/function/_UserTempEmailAuth", "user_temp_phone_auth_check_sms_code.sql"},
+		{projectTmplPath + "/webServer/auth/phone.go", "/webServer/auth", "phone.go"},
+		{webClient + "/app/components/auth/phone/phoneAuthBtn.vue", "/webClient/src/app/components/auth/phone", "phoneAuthBtn.vue"},
+		{webClient + "/app/components/auth/phone/components/compLoginForm.vue", "/webClient/src/app/components/auth/phone/components", "compLoginForm.vue"},
+		{webClient + "/app/components/auth/phone/components/compRecoverPasswordForm.vue", "/webClient/src/app/components/auth/phone/components", "compRecoverPasswordForm.vue"},
+		{webClient + "/app/components/auth/phone/components/compRegisterForm.vue", "/webClient/src/app/components/auth/phone/components", "compRegisterForm.vue"},
+	}
+}
+
+func apiAuditTargetFiles(p types.ProjectType) []TargetFile {
+	projectTmplPath := getCurrentDir() + "/project"
+	webClient := fmt.Sprintf("%s/webClient/quasar_%v", projectTmplPath, p.GetQuasarVersion())
+	return []TargetFile{
+		{projectTmplPath + "/sql/07_ApiAudit/main.toml", "/sql/model/07_ApiAudit", "main.toml"},
+		{projectTmplPath + "/sql/07_ApiAudit/api_audit_list.sql", "/sql/template/function/_ApiAudit", "api_audit_list.sql"},
+		{webClient + "/app/components/apiAudit/index.vue", "/webClient/src/app/components/apiAudit", "index.vue"},
+	}
+}
+
+func telegramTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/integrations/telegram/telegramAuth.go", "/webServer", "telegramAuth.go"},
+		{getCurrentDir() + "/integrations/telegram/user_telegram_auth.sql", "/sql/template/function/_User", "user_telegram_auth.sql"},
+		{getCurrentDir() + "/integrations/telegram/user_get_by_telegram_id.sql", "/sql/template/function/_User", "user_get_by_telegram_id.sql"},
+		{getCurrentDir() + "/project/tgBot/main.go", "/tgBot", "main.go"},
+	}
+}
+
+func yandexDiskBackupTargetFiles(p types.ProjectType) []TargetFile {
+	path := getCurrentDir() + "/project/yandexDiskBackup"
+	return []TargetFile{
+		{path + "/main.go", "/yandexDiskBackup", "main.go"},
+		{path + "/yandexApi.go", "/yandexDiskBackup", "yandexApi.go"},
+		{path + "/dbBackup.go", "/yandexDiskBackup", "dbBackup.go"},
+		{path + "/systemdService.service", "/yandexDiskBackup", p.Config.Postgres.DbName + "_yandexBackup.service"},
+		{path + "/startYandexBackupService.sh", "/yandexDiskBackup", "startYandexBackupService.sh"},
+	}
+}
+
+func bitrixTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/integrations/bitrix/bitrixMain.go", "/bitrix", "main.go"},
+	}
+}
+
+func odataTargetFiles(p types.ProjectType) []TargetFile {
+	return []TargetFile{
+		{getCurrentDir() + "/integrations/odata/main.go", "/odata", "main.go"},
+		{getCurrentDir() + "/integrations/odata/odataQueryType.go", "/odata", "odataQueryType.go"},
+	}
+}
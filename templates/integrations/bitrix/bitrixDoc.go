@@ -9,6 +9,8 @@ import (
 	"time"
 	"encoding/json"
 	"[[LocalProjectPath]]/pg"
+	"[[LocalProjectPath]]/metrics"
+	"[[LocalProjectPath]]/logger"
 	"github.com/spf13/cast"
 )
 
@@ -39,26 +41,30 @@ func Get[[DocNameCamel]]History(c *gin.Context) {
 	userId, _ := utils.ExtractUserIdString(c)
 
 	go func() {
+		start := time.Now()
 		nextId := 0
 		lastProcessedId := 0
 		for {
 			lastId := 0
-			fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave nextId: %v\n", nextId)
+			logger.Debugf("getAll[[DocNameCamel]]HistoryAndSave nextId: %v", nextId)
 			nextId, lastId, err = getAll[[DocNameCamel]]HistoryAndSave(nextId, nil)
 			if err != nil {
-				fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave err %s\n", err)
+				logger.Errorf("getAll[[DocNameCamel]]HistoryAndSave err %s", err)
+				metrics.ObserveIntegrationSync("bitrix", "[[.Name]]", start, false)
 				return
 			}
 			[[if .Integrations.Bitrix.IsNoPagination]]
-			fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave finished")
+			logger.Infof("getAll[[DocNameCamel]]HistoryAndSave finished")
 			saveResultMsgToPg(userId, "[[DocNameCamel]] импортированы из Битрикс")
-			fmt.Printf("lastProcessedId: %v lastId: %v %v\n", lastProcessedId, lastId, time.Second)
+			metrics.ObserveIntegrationSync("bitrix", "[[.Name]]", start, true)
+			logger.Debugf("lastProcessedId: %v lastId: %v %v", lastProcessedId, lastId, time.Second)
 			return
 			[[else]]
 			// прерываем процесс когда id'шники пошли на второй круг. Определяем это по тому что новый lastId меньше последнего обработанного id'шника
 			if lastProcessedId > 0 && lastId < lastProcessedId {
-				fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave finished")
+				logger.Infof("getAll[[DocNameCamel]]HistoryAndSave finished")
 				saveResultMsgToPg(userId, "[[DocNameCamel]] импортированы из Битрикс")
+				metrics.ObserveIntegrationSync("bitrix", "[[.Name]]", start, true)
 				return
 			}
 			lastProcessedId = lastId
@@ -102,7 +108,7 @@ func getAll[[DocNameCamel]]HistoryAndSave(startId int, errResultArr *[]errResult
 		//fmt.Printf("process %s %s %s %s\n", v.ID, v.TITLE, v.PHONE, v.EMAIL)
 		doc, err := v.ConvertFromBitrix()
 		if err != nil {
-			fmt.Printf("ConvertFromBitrix err %s %s\n", err, v)
+			logger.Errorf("ConvertFromBitrix err %s %s", err, v)
 			if errResultArr != nil {
 				*errResultArr = append(*errResultArr, errResult{
 					JsonParams: doc,
@@ -113,14 +119,14 @@ func getAll[[DocNameCamel]]HistoryAndSave(startId int, errResultArr *[]errResult
 		}
 		lastId = cast.ToInt(v.BtxId)
 		if lastId == 0 {
-			fmt.Printf("cast.ToInt err %s %s\n", err, v)
+			logger.Errorf("cast.ToInt err %s %s", err, v)
 			continue
 		}
 
 		jsonData, _ := json.Marshal(doc)
 		err = pg.CallPgFunc("[[.Name]]_update", jsonData, doc, nil)
 		if err != nil {
-			fmt.Printf("[[.Name]]_update error: %s %s\n", err, jsonData)
+			logger.Errorf("[[.Name]]_update error: %s %s", err, jsonData)
 			if errResultArr != nil {
 				*errResultArr = append(*errResultArr, errResult{
 					JsonParams: doc,
@@ -156,10 +162,10 @@ func Get[[DocNameCamel]]HistoryDebug(c *gin.Context) {
 	errResultArr := []errResult{}
 	for {
 		lastId := 0
-		fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave nextId: %v\n", nextId)
+		logger.Debugf("getAll[[DocNameCamel]]HistoryAndSave nextId: %v", nextId)
 		nextId, lastId, err = getAll[[DocNameCamel]]HistoryAndSave(nextId, &errResultArr)
 		if err != nil {
-			fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave err %s\n", err)
+			logger.Errorf("getAll[[DocNameCamel]]HistoryAndSave err %s", err)
 			break
 		}
 
@@ -168,13 +174,13 @@ func Get[[DocNameCamel]]HistoryDebug(c *gin.Context) {
 		}
 
 		[[if .Integrations.Bitrix.IsNoPagination]]
-		fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave finished")
-		fmt.Printf("lastProcessedId: %v lastId: %v %v\n", lastProcessedId, lastId, time.Second)
+		logger.Infof("getAll[[DocNameCamel]]HistoryAndSave finished")
+		logger.Debugf("lastProcessedId: %v lastId: %v %v", lastProcessedId, lastId, time.Second)
 		break
 		[[else]]
 		// прерываем процесс когда id'шники пошли на второй круг. Определяем это по тому что новый lastId меньше последнего обработанного id'шника
 		if lastProcessedId > 0 && lastId < lastProcessedId {
-			fmt.Printf("getAll[[DocNameCamel]]HistoryAndSave finished")
+			logger.Infof("getAll[[DocNameCamel]]HistoryAndSave finished")
 			break
 		}
 		lastProcessedId = lastId
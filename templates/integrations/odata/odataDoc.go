@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"[[LocalProjectPath]]/metrics"
 	"[[LocalProjectPath]]/pg"
 	"[[LocalProjectPath]]/utils"
+	"[[LocalProjectPath]]/logger"
 	"time"
 	[[range .Integrations.Odata.Import]]
 	"[[.]]"
@@ -40,7 +42,7 @@ func Start[[DocNameCamel]]Sync(c *gin.Context) {
 		userId, _ := utils.ExtractUserIdString(c)
 		err := saveResultMsgToPg(userId, "Синхронизация с 1С: [[.NameRu]]", resMsg)
 		if err != nil {
-			fmt.Printf("Start[[DocNameCamel]]Sync saveResultMsgToPg error: %s\n", err)
+			logger.Errorf("Start[[DocNameCamel]]Sync saveResultMsgToPg error: %s", err)
 		}
 	}()
 	utils.HttpSuccess(c, "ok")
@@ -51,8 +53,9 @@ func sync[[DocNameCamel]]With1C() ([]resultMsgType) {
 	resMsg := newResultMsgType("Синхронизация: [[.NameRu]]")
 	resList, err := get[[DocNameCamel]]()
 	if err != nil {
-		fmt.Printf("sync[[DocNameCamel]]With1C get[[DocNameCamel]] error: %s\n", err)
+		logger.Errorf("sync[[DocNameCamel]]With1C get[[DocNameCamel]] error: %s", err)
 		resMsg.addErr(err.Error())
+		metrics.ObserveIntegrationSync("odata", "[[.Name]]", start, false)
 		return []resultMsgType{resMsg}
 	}
 	cnt := 0 //счетчик записей
@@ -60,7 +63,7 @@ func sync[[DocNameCamel]]With1C() ([]resultMsgType) {
 		jsonStr, _ := json.Marshal(v)
 		err := pg.CallPgFunc("[[.Name]]_update", jsonStr, nil, nil)
 		if err != nil {
-			fmt.Printf("sync[[DocNameCamel]]With1C [[.Name]]_update error: %s jsonStr: %s\n", err, jsonStr)
+			logger.Errorf("sync[[DocNameCamel]]With1C [[.Name]]_update error: %s jsonStr: %s", err, jsonStr)
 			resMsg.Errors = append(resMsg.Errors, fmt.Sprintf("%s uuid: %s", err, v.Uuid))
 			continue
 		}
@@ -69,6 +72,7 @@ func sync[[DocNameCamel]]With1C() ([]resultMsgType) {
 	resMsg.addResult(fmt.Sprintf("синхронизировано записей: <strong>%v</strong>", cnt))
 	elapsed := time.Since(start)
 	resMsg.setDuration(fmt.Sprintf("%s", elapsed))
+	metrics.ObserveIntegrationSync("odata", "[[.Name]]", start, len(resMsg.Errors) == 0)
 	return []resultMsgType{resMsg}
 }
 
@@ -93,7 +97,7 @@ func get[[DocNameCamel]]() ([][[DocNameCamel]]ForPgType, error) {
 		return res, err
 	}
 	elapsed := time.Since(start)
-	fmt.Printf("get[[DocNameCamel]] len: %v took time: %s\n", len(tempRes.Value), elapsed)
+	logger.Infof("get[[DocNameCamel]] len: %v took time: %s", len(tempRes.Value), elapsed)
 	for _, v := range tempRes.Value {
 		c := [[DocNameCamel]]ForPgType{}
 		[[- range .Flds]]
@@ -131,7 +135,7 @@ func Sync[[DocNameCamel]]With1CDebug(c *gin.Context) {
 		return
 	}
 	elapsed := time.Since(start)
-	fmt.Printf("get[[DocNameCamel]] len: %v took time: %s\n", len(tempRes.Value), elapsed)
+	logger.Infof("get[[DocNameCamel]] len: %v took time: %s", len(tempRes.Value), elapsed)
 	for _, v := range tempRes.Value {
 		c := [[DocNameCamel]]ForPgType{}
 		[[- range .Flds]]
@@ -148,7 +152,7 @@ func Sync[[DocNameCamel]]With1CDebug(c *gin.Context) {
 		jsonStr, _ := json.Marshal(v)
 		err := pg.CallPgFunc("[[.Name]]_update", jsonStr, nil, nil)
 		if err != nil {
-			fmt.Printf("sync[[DocNameCamel]]With1C [[.Name]]_update error: %s jsonStr: %s\n", err, jsonStr)
+			logger.Errorf("sync[[DocNameCamel]]With1C [[.Name]]_update error: %s jsonStr: %s", err, jsonStr)
 			continue
 		}
 		cnt++
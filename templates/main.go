@@ -8,9 +8,7 @@ import (
 	"github.com/iancoleman/strcase"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
-	"io/ioutil"
 	"log"
-	"os"
 	"path"
 	"runtime"
 	"strings"
@@ -47,7 +45,7 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 
 	// функция которая: файлы с шаблонами -> map[string]*template.Template
 	readFiles := func(prefix, delimLeft, delimRight string, path ...string) {
-		tmpls, err := template.New("").Funcs(funcMap).Delims(delimLeft, delimRight).ParseFiles(path...)
+		tmpls, err := utils.ParseTemplateFiles(template.New("").Funcs(funcMap).Delims(delimLeft, delimRight), path...)
 		utils.CheckErr(err, "ParseFiles")
 		for _, t := range tmpls.Templates() {
 			res[prefix+t.Name()] = t
@@ -73,7 +71,7 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 	path = currentDir + "/sql/"
 	readFiles("sql_", "{{", "}}", path+"main.toml")
 	path = currentDir + "/sql/function/"
-	readFiles("sql_function_", "{{", "}}", path+"get_by_id.sql", path+"list.sql", path+"update.sql", path+"trigger_before.sql", path+"trigger_after.sql")
+	readFiles("sql_function_", "{{", "}}", path+"get_by_id.sql", path+"list.sql", path+"update.sql", path+"trigger_before.sql", path+"trigger_after.sql", path+"realtime.sql")
 	readFiles("sql_function_", "[[", "]]", path+"create.sql")
 	// отдельно читаем шаблон action для stateMachine. Там нужно передавать свой map с параметрами
 	res["sql_function_action.sql"] = stateMachineReadTmplAction(funcMap, path+"action.sql")
@@ -93,7 +91,7 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 			// извлекаем имя файла шаблона, чтобы использовать его в качестве имени шабона. Иначе могут быть ошибки
 			path := strings.Split(dt.Source, "/")
 			fName := path[len(path)-1]
-			t, err := template.New(fName).Funcs(fMap).Delims("[[", "]]").ParseFiles(dt.Source)
+			t, err := utils.ParseTemplateFiles(template.New(fName).Funcs(fMap).Delims("[[", "]]"), dt.Source)
 			utils.CheckErr(err, fmt.Sprintf("ParseTemplates doc: %s tmpl: %s parse template error: %s", d.Name, tName, err))
 			// сохраняем template в поле структуры
 			dt.Tmpl = t
@@ -110,6 +108,10 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 		if d.StateMachine != nil {
 			baseTmplNames = append(baseTmplNames, "sql_function_action.sql", "sql_function_create.sql")
 		}
+		// правила рассылки изменений: функция <doc>_realtime вызывается из notify_event
+		if d.Realtime != nil {
+			baseTmplNames = append(baseTmplNames, "sql_function_realtime.sql")
+		}
 		// если документ отмечен свойством рекурсии, то дополнительные шаблоны
 		if d.IsRecursion {
 			docIsRecursionProccess(p, &d)
@@ -129,7 +131,7 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 				t = t1.Tmpl
 			}
 			if t == nil {
-				log.Fatalf("ParseTemplates: Template not found for tab %s webClient_%s", d.Name, tab.TmplName)
+				utils.Fatalf("ParseTemplates: Template not found for tab %s webClient_%s", d.Name, tab.TmplName)
 			}
 
 			tName := "webClient_tabs_" + tab.Title
@@ -168,7 +170,7 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 				// возможность переопределить шаблон
 				// если указаны табы, то подменяем шаблон item.vue на itemWithTabs.vue
 				if len(d.Vue.Tabs) > 0 {
-					if strings.HasPrefix(distPath, "../src/webClient/src/app/components") && distFilename == "item.vue" {
+					if strings.HasPrefix(distPath, p.DistPath+"/webClient/src/app/components") && distFilename == "item.vue" {
 						tmpl = res["webClient_itemWithTabs.vue"]
 					}
 				}
@@ -215,9 +217,9 @@ func ParseTemplates(p types.ProjectType) map[string]*template.Template {
 
 func ExecuteToFile(t *template.Template, d interface{}, path, filename string) error {
 	if t == nil {
-		log.Fatalf("template is nil for path '%s/%s'\n", path, filename)
+		utils.Fatalf("template is nil for path '%s/%s'\n", path, filename)
 	}
-	err := os.MkdirAll(path, os.ModePerm)
+	err := utils.Fs.MkdirAll(path)
 	if err != nil {
 		return err
 	}
@@ -228,15 +230,16 @@ func ExecuteToFile(t *template.Template, d interface{}, path, filename string) e
 	}
 	// для оптимизации записи файлов webClient (чтобы ускорить рестарт quasar), проверяем что файл изменен и только в этом случае его перезаписываем
 	if strings.Contains(path, "webClient") {
-		if existFile, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", path, filename)); err == nil {
+		if existFile, err := utils.Fs.ReadFile(fmt.Sprintf("%s/%s", path, filename)); err == nil {
 			isEqual := utils.ByteSliceEqual(existFile, []byte(tpl.String()))
 			if isEqual {
+				utils.RecordGeneratedFile(path+"/"+filename, utils.TemplateSource(t), existFile)
 				return nil
 			}
 			//fmt.Printf("file changed: %s/%s not equal\n", path, filename)
 		}
 	}
-	return ioutil.WriteFile(path+"/"+filename, []byte(tpl.String()), 0644)
+	return utils.WriteGeneratedFile(path+"/"+filename, utils.TemplateSource(t), []byte(tpl.String()))
 }
 
 // печать vue темплейтов для
@@ -359,7 +362,7 @@ func PrintVueFldTemplate(fld types.FldType) string {
 		return fmt.Sprintf(`<q-select %s :label="$t('%s')" v-model='item.%s' :options='%s' %s %s :readonly='%s' %s/>`, borderStyle, labelI18n, name, options, multiple, isClearable, readonly, params)
 	case types.FldTypeVueComposition:
 		if fld.Vue.Composition == nil {
-			log.Fatal(fmt.Sprintf("fld have type '%s', but fld.Vue.Composition function is nil", types.FldTypeVueComposition))
+			utils.Fatalf("fld have type '%s', but fld.Vue.Composition function is nil", types.FldTypeVueComposition)
 		}
 		// возможен вариант что функция рендеринга поля шаблона вызываается до того как сам документ был инициализирован и соответственно была заполнена ссылка на него в поле fld.Doc
 		// в таком случае в функуию передаем пустой документ. Если функция не использует ссылку на документ, то все ок. Но если в функции идет обращение к инфе о документе, то функция отработает некорректно.
@@ -432,11 +435,11 @@ func PrintFldSelectOptions(doc types.DocType, fldName string) string {
 	}
 	// проверка что поле нашлось
 	if len(fld.Name) == 0 {
-		log.Fatalf("PrintFldSelectOptions fld: '%s' not found in doc: '%s'", fldName, doc.Name)
+		utils.Fatalf("PrintFldSelectOptions fld: '%s' not found in doc: '%s'", fldName, doc.Name)
 	}
 	// проверка что поле имеет тип
 	if fld.Vue.Type != types.FldVueTypeSelect {
-		log.Fatalf("PrintFldSelectOptions fld: '%s' not type GetFldSelectString in doc: '%s'", fldName, doc.Name)
+		utils.Fatalf("PrintFldSelectOptions fld: '%s' not type GetFldSelectString in doc: '%s'", fldName, doc.Name)
 	}
 	res := []string{}
 	for _, v := range fld.Vue.Options {
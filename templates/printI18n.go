@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
+	"sort"
 	"strings"
 	"text/template"
 )
 
+// файлы локализации собираются в коде, а не из файла шаблона, поэтому в манифесте источником указывается этот файл (см. utils.TemplateSource)
+const i18nTemplateSource = "templates/printI18n.go"
 
 // заполняем словарь локализации
 func FillDocI18n(p types.ProjectType) {
@@ -95,7 +98,7 @@ func PrintI18nJs(p types.ProjectType)  {
 	}
 	resStr = resStr + "\n}\n"
 
-	t, _ := template.New("").Parse(resStr)
+	t, _ := template.New(i18nTemplateSource).Parse(resStr)
 	err := ExecuteToFile(t, p,  p.DistPath + "/webClient/src/i18n", "index.js")
 	utils.CheckErr(err, "ExecuteToFile template /i18n/index.js")
 }
@@ -109,10 +112,17 @@ func PrintDocI18nJs(p types.ProjectType, lang string)  {
 	resStr = resStr + "\nexport default {"
 	// печатаем сообщения на уровне проекта
 	if _, ok := p.I18n.Data[lang]; ok {
-		for m, list := range p.I18n.Data[lang] {
+		// ключи сортируем, чтобы файл не менялся от генерации к генерации
+		sections := []string{}
+		for m := range p.I18n.Data[lang] {
+			sections = append(sections, m)
+		}
+		sort.Strings(sections)
+		for _, m := range sections {
+			list := p.I18n.Data[lang][m]
 			resStr = fmt.Sprintf("%s\n	%s: {", resStr, m)
-			for k, v := range list {
-				resStr = fmt.Sprintf("%s\n 		%s: '%s',", resStr, k, v)
+			for _, k := range sortedI18nKeys(list) {
+				resStr = fmt.Sprintf("%s\n 		%s: '%s',", resStr, k, list[k])
 			}
 			resStr = resStr + "\n	},"
 		}
@@ -123,7 +133,7 @@ func PrintDocI18nJs(p types.ProjectType, lang string)  {
 	}
 	resStr = resStr + "\n}\n"
 
-	t, _ := template.New("").Parse(resStr)
+	t, _ := template.New(i18nTemplateSource).Parse(resStr)
 	langDirName := lang
 	if langDirName == "en" {
 		langDirName = "en-US"
@@ -136,16 +146,25 @@ func PrintDocI18nJs(p types.ProjectType, lang string)  {
 		resStr := ""
 		resStr = resStr + "\nexport default {"
 		if _, ok := doc.I18n[lang]; ok {
-			for k, v := range doc.I18n[lang] {
-				resStr = fmt.Sprintf("%s\n 		%s: '%s',", resStr, k, v)
+			for _, k := range sortedI18nKeys(doc.I18n[lang]) {
+				resStr = fmt.Sprintf("%s\n 		%s: '%s',", resStr, k, doc.I18n[lang][k])
 			}
 		}
 		resStr = resStr + "\n}\n"
 
-		t, _ := template.New("").Parse(resStr)
+		t, _ := template.New(i18nTemplateSource).Parse(resStr)
 		err = ExecuteToFile(t, p,  p.DistPath + "/webClient/src/i18n/" + langDirName, doc.Name + ".js")
 		utils.CheckErr(err, fmt.Sprintf("ExecuteToFile template /i18n/%s/%s.js", langDirName, doc.Name))
 	}
 
 
 }
+
+func sortedI18nKeys(m map[string]string) []string {
+	res := []string{}
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
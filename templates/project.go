@@ -13,9 +13,9 @@ func WriteProjectFiles(p types.ProjectType, tmplMap map[string]*template.Templat
 	for name, t := range tmplMap {
 		if strings.HasPrefix(name, "project_") {
 			filename := strings.TrimPrefix(name, "project_")
-			path := ".."
+			path := p.OutputRoot()
 			if filename == "config.toml" || filename == "main.go" {
-				path = "../src"
+				path = p.DistPath
 			}
 			err := ExecuteToFile(t, p, path, filename)
 			utils.CheckErr(err, fmt.Sprintf("'project' ExecuteToFile '%s'", name))
@@ -27,8 +27,8 @@ func WriteProjectFiles(p types.ProjectType, tmplMap map[string]*template.Templat
 		for _, v := range m {
 			if len(v.Tmpl.Source) > 0 && len(v.Tmpl.Dist) > 0 {
 				distPath, filename := utils.PathExtractFilename(v.Tmpl.Dist)
-				distPath = "../src" + distPath
-				t, err := template.New(filename).Delims("[[", "]]").ParseFiles(v.Tmpl.Source)
+				distPath = p.DistPath + distPath
+				t, err := utils.ParseTemplateFiles(template.New(filename).Delims("[[", "]]"), v.Tmpl.Source)
 				utils.CheckErr(err, "p.Sql.Methods")
 
 				err = ExecuteToFile(t, p, distPath, filename)
@@ -74,48 +74,8 @@ func WriteProjectFiles(p types.ProjectType, tmplMap map[string]*template.Templat
 	}
 
 
-	if p.Config.Auth.ByPhone {
-		ReadTmplAndPrint(p, projectTmplPath + "/sql/01_User/user_get_by_phone_with_password.sql", "/sql/template/function/_User",  "user_get_by_phone_with_password.sql", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/sql/03_UserTempEmailAuth/user_temp_phone_auth_create.sql", "/sql/template/function/_UserTempEmailAuth",  "user_temp_phone_auth_create.sql", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/sql/03_UserTempEmailAuth/user_temp_phone_auth_check_sms_code.sql", "/sql/template/function/_UserTempEmailAuth",  "user_temp_phone_auth_check_sms_code.sql", nil)
-		ReadTmplAndPrint(p, projectTmplPath + webClient + "/auth/phone.go", "/webServer/auth",  "phone.go", nil)
-		ReadTmplAndPrint(p, projectTmplPath + webClient + "/app/components/auth/phone/phoneAuthBtn.vue", "/webClient/src/app/components/auth/phone",  "phoneAuthBtn.vue", nil)
-		ReadTmplAndPrint(p, projectTmplPath + webClient + "/app/components/auth/phone/components/compLoginForm.vue", "/webClient/src/app/components/auth/phone/components",  "compLoginForm.vue", nil)
-		ReadTmplAndPrint(p, projectTmplPath + webClient + "/app/components/auth/phone/components/compRecoverPasswordForm.vue", "/webClient/src/app/components/auth/phone/components",  "compRecoverPasswordForm.vue", nil)
-		ReadTmplAndPrint(p, projectTmplPath + webClient + "/app/components/auth/phone/components/compRegisterForm.vue", "/webClient/src/app/components/auth/phone/components",  "compRegisterForm.vue", nil)
-	}
-
-	if p.IsTelegramIntegration() {
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/telegram/telegramAuth.go", "/webServer", "telegramAuth.go", nil)
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/telegram/user_telegram_auth.sql", "/sql/template/function/_User", "user_telegram_auth.sql", nil)
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/telegram/user_get_by_telegram_id.sql", "/sql/template/function/_User", "user_get_by_telegram_id.sql", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/tgBot/main.go", "/tgBot", "main.go", nil)
-	}
-
-	if p.IsBackupOnYandexDisk() {
-		ReadTmplAndPrint(p, projectTmplPath + "/yandexDiskBackup/main.go", "/yandexDiskBackup",  "main.go", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/yandexDiskBackup/yandexApi.go", "/yandexDiskBackup",  "yandexApi.go", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/yandexDiskBackup/dbBackup.go", "/yandexDiskBackup",  "dbBackup.go", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/yandexDiskBackup/systemdService.service", "/yandexDiskBackup",  p.Config.Postgres.DbName + "_yandexBackup.service", nil)
-		ReadTmplAndPrint(p, projectTmplPath + "/yandexDiskBackup/startYandexBackupService.sh", "/yandexDiskBackup","startYandexBackupService.sh", nil)
-	}
-
-	// в случае коннекта к Битрикс генерим файлы
-	if p.IsBitrixIntegration() {
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/bitrix/bitrixMain.go", "/bitrix", "main.go", nil)
-		//sourcePath := "../../../pepelazz/nla_framework/templates/integrations/bitrix/bitrixMain.go"
-		//t, err := template.New("bitrixMain.go").Funcs(funcMap).Delims("[[", "]]").ParseFiles(sourcePath)
-		//utils.CheckErr(err, "bitrixMain.go")
-		//distPath := fmt.Sprintf("%s/bitrix", p.DistPath)
-		//err = ExecuteToFile(t, p, distPath, "main.go")
-		//utils.CheckErr(err, fmt.Sprintf("'project' ExecuteToFile '%s'", "bitrix/main.go"))
-	}
-
-	// в случае коннекта к 1 Odata генерим файлы
-	if p.IsOdataIntegration() {
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/odata/main.go", "/odata", "main.go", nil)
-		ReadTmplAndPrint(p, getCurrentDir() + "/integrations/odata/odataQueryType.go", "/odata", "odataQueryType.go", nil)
-	}
+	// подсистемы проекта: авторизация по телефону, интеграции, бэкап и таргеты, зарегистрированные через RegisterTarget
+	generateTargets(p)
 }
 
 func OtherTemplatesGenerate(p types.ProjectType)  {
@@ -142,7 +102,7 @@ func ReadTmplAndPrint(p types.ProjectType, sourcePath, distPath, filename string
 		sourcePath = newSourcePath
 	}
 	_, sourceFilename := utils.PathExtractFilename(sourcePath)
-	t, err := template.New(sourceFilename).Funcs(fMap).Delims("[[", "]]").ParseFiles(sourcePath)
+	t, err := utils.ParseTemplateFiles(template.New(sourceFilename).Funcs(fMap).Delims("[[", "]]"), sourcePath)
 	utils.CheckErr(err, "readFileWithDist")
 	err = ExecuteToFile(t, p, p.DistPath + distPath, filename)
 	utils.CheckErr(err, fmt.Sprintf("ReadTmplAndPrint ExecuteToFile '%s/%s'", distPath, filename))
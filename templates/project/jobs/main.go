@@ -1,5 +1,49 @@
 package jobs
 
+import (
+	"context"
+	"[[.Config.LocalProjectPath]]/lifecycle"
+	"[[.Config.LocalProjectPath]]/logger"
+	"sync"
+)
+
+var (
+	wg sync.WaitGroup
+)
+
 func StartJobs()  {
 	[[.PrintGoJobList]]
-}
\ No newline at end of file
+	lifecycle.Register("jobs", Stop)
+}
+
+// Go запуск фоновой задачи. ctx отменяется при остановке приложения, задача должна завершиться по ctx.Done()
+func Go(f func(ctx context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			// паника в задаче по-прежнему завершает приложение, но перед этим попадает в лог (в том числе в graylog)
+			if r := recover(); r != nil {
+				logger.Errorf("job panic: %v", r)
+				_ = logger.Close(context.Background())
+				panic(r)
+			}
+		}()
+		f(lifecycle.Context())
+	}()
+}
+
+// Stop ожидание завершения задач, запущенных через Go
+func Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -3,10 +3,9 @@ package main
 import (
 	"encoding/gob"
 	"flag"
-	{{ if .Config.Graylog.Host -}}
-	"{{.Config.LocalProjectPath}}/graylog"
-	{{end -}}
 	"{{.Config.LocalProjectPath}}/jobs"
+	"{{.Config.LocalProjectPath}}/lifecycle"
+	"{{.Config.LocalProjectPath}}/logger"
 	"{{.Config.LocalProjectPath}}/pg"
 	"{{.Config.LocalProjectPath}}/types"
 	"{{.Config.LocalProjectPath}}/utils"
@@ -73,16 +72,15 @@ func main() {
 	config, err = types.ReadConfigFile("./config.toml")
 	utils.CheckErr(err, "Read config")
 
+	// лог приложения: stdout, файл и graylog по настройкам секций logger и graylog. Закрывается последним при остановке
+	err = logger.Init({{printf "%q" .Name}}, config.Logger, config.Graylog)
+	utils.CheckErr(err, "Init logger")
+	lifecycle.Register("logger", logger.Close)
+
 	// postgres
 	err = pg.StartPostgres(config.Postgres)
 	utils.CheckErr(err, "StartPostgres")
 
-	{{ if .Config.Graylog.Host -}}
-	// подключаемся к серверу сбора логов
-	err = graylog.Init(config.Graylog)
-	utils.CheckErr(err, "Connect to GraylogConfig")
-	{{- end}}
-
 	// инициализируем генератор случайных чисел
 	rand.Seed(time.Now().UnixNano())
 	//
@@ -103,10 +101,14 @@ func main() {
 	//go pg.GenerateFakeUsers(100)
 	{{if .IsTelegramIntegration -}}
 	go tgBot.Start(*config)
+	lifecycle.Register("telegram bot", tgBot.Stop)
 	{{- end}}
 
 	// инициализируем брокера для обработки подключений по SSE
-	sse.Init()
+	sse.Init(config.Sse)
 
 	webServer.StartWebServer(*config)
+
+	// ожидаем SIGINT/SIGTERM и останавливаем подсистемы в обратном порядке запуска
+	lifecycle.WaitForSignal(time.Duration(config.WebServer.ShutdownTimeout) * time.Second)
 }
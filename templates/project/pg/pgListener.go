@@ -1,15 +1,21 @@
 package pg
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/lib/pq"
 	"[[.Config.LocalProjectPath]]/cacheUtil"
+	"[[.Config.LocalProjectPath]]/metrics"
 	"[[.Config.LocalProjectPath]]/types"
 	"[[.Config.LocalProjectPath]]/utils"
 	"[[.Config.LocalProjectPath]]/sse"
+	"[[.Config.LocalProjectPath]]/logger"
 	"github.com/tidwall/gjson"
+	"encoding/json"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,29 +25,44 @@ type (
 
 var (
 	pgListeners = []PgEventListener{}
+	// состояние LISTEN соединения, проверяется в /readyz
+	listenerConnected int32
+	listenerStop      = make(chan struct{})
+	listenerStopOnce  sync.Once
+	listenerDone      = make(chan struct{})
 )
 
-func waitForNotification(l *pq.Listener) {
+// waitForNotification ожидание события. false - прослушивание остановлено (StopListener)
+func waitForNotification(l *pq.Listener) bool {
 	for {
 		select {
+		case <-listenerStop:
+			return false
 		case n := <-l.Notify:
-			processPgEvent(n.Extra)
-			for _, f := range pgListeners {
-				f(n.Extra)
+			// после переподключения приходит nil. Пока соединения не было, могли остаться необработанные большие события
+			if n == nil {
+				processOutboxPending()
+				return true
+			}
+			if id := outboxEventId(n.Extra); id > 0 {
+				processOutboxEvent(id)
+			} else {
+				handlePgEvent(n.Extra)
 			}
 			//printEventJson(n)
-			return
+			return true
 		case <-time.After(90 * time.Second):
 			//fmt.Println("Received no events for 90 seconds, checking connection")
 			go func() {
 				l.Ping()
 			}()
-			return
+			return true
 		}
 	}
 }
 
 func pgListen(config types.Postgres) {
+	defer close(listenerDone)
 
 	dbinfo := fmt.Sprintf("postgres://%s:%s@%s:%v/%s?sslmode=disable", config.User, config.Password, config.Host, config.Port, config.DbName)
 	db, err := sql.Open("postgres", dbinfo)
@@ -51,7 +72,16 @@ func pgListen(config types.Postgres) {
 
 	reportProblem := func(ev pq.ListenerEventType, err error) {
 		if err != nil {
-			fmt.Println(err.Error())
+			logger.Warnf("pg listener: %s", err)
+		}
+		switch ev {
+		case pq.ListenerEventConnected:
+			atomic.StoreInt32(&listenerConnected, 1)
+		case pq.ListenerEventReconnected:
+			atomic.StoreInt32(&listenerConnected, 1)
+			metrics.PgListenerReconnects.Inc()
+		case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+			atomic.StoreInt32(&listenerConnected, 0)
 		}
 	}
 
@@ -61,9 +91,33 @@ func pgListen(config types.Postgres) {
 		panic(err)
 	}
 
-	fmt.Println("Start monitoring PostgreSQL...")
-	for {
-		waitForNotification(listener)
+	logger.Infof("Start monitoring PostgreSQL...")
+	// большие события, которые не успели обработать до остановки приложения
+	processOutboxPending()
+	go outboxCleanup()
+	for waitForNotification(listener) {
+	}
+	atomic.StoreInt32(&listenerConnected, 0)
+	if err := listener.Close(); err != nil {
+		logger.Errorf("pg listener close error: %s", err)
+	}
+}
+
+// IsListenerConnected признак что LISTEN соединение с postgres установлено
+func IsListenerConnected() bool {
+	return atomic.LoadInt32(&listenerConnected) == 1
+}
+
+// StopListener остановка прослушивания событий postgres
+func StopListener(ctx context.Context) error {
+	listenerStopOnce.Do(func() {
+		close(listenerStop)
+	})
+	select {
+	case <-listenerDone:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
@@ -71,10 +125,26 @@ func AddPgEventListener(f PgEventListener) {
 	pgListeners = append(pgListeners, f)
 }
 
+// handlePgEvent обработка события: сброс кэша, рассылка клиентам и обработчики из AddPgEventListener
+func handlePgEvent(event string) {
+	processPgEvent(event)
+	for _, f := range pgListeners {
+		f(event)
+	}
+}
+
 func processPgEvent(event string) {
-	fmt.Printf("event %s\n", event)
+	logger.Debugf("event %s", event)
 	// извлекаем тип документа для которого произошли изменения в базе
 	tableName := gjson.Get(event, "table").Str
+	// сбрасываем кэш, который зависит от измененной таблицы
+	if len(tableName) > 0 {
+		cacheUtil.InvalidateTable(tableName, gjson.Get(event, "id").Int())
+	}
+	// рассылка изменений по правилам документа (DocType.Realtime)
+	if realtime := gjson.Get(event, "realtime"); realtime.Exists() {
+		publishRealtime(tableName, event, realtime)
+	}
 	//обрабатываем изменения
 	switch tableName {
 	case "user":
@@ -92,6 +162,33 @@ func processPgEvent(event string) {
 		userIdInt := gjson.Get(event, "flds.executor_id").Int()
 		sse.SendJson(strconv.FormatInt(userIdInt, 10), gjson.Get(event, "flds").Value())
 	case "process_error":
-		fmt.Printf("postgres event %s\n", event)
+		logger.Warnf("postgres event %s", event)
+	}
+}
+
+// publishRealtime событие doc {table, id, op, data} получателям из <таблица>_realtime. Если получатели в документе
+// не указаны (recipients_only = false), то в топики doc:<таблица> и doc:<таблица>:<id>
+func publishRealtime(tableName, event string, realtime gjson.Result) {
+	id := gjson.Get(event, "id").Int()
+	data := realtime.Get("data")
+	if data.Type == gjson.Null {
+		data = gjson.Get(event, "flds")
+	}
+	msg, err := json.Marshal(map[string]interface{}{
+		"table": tableName,
+		"id":    id,
+		"op":    realtime.Get("op").Str,
+		"data":  data.Value(),
+	})
+	if err != nil {
+		logger.Errorf("realtime event of %s error while marshaling JSON: %s", tableName, err)
+		return
+	}
+	if !realtime.Get("recipients_only").Bool() {
+		sse.PublishString(sse.TableTopic(tableName), "doc", string(msg))
+		sse.PublishString(sse.DocTopic(tableName, id), "doc", string(msg))
+	}
+	for _, u := range realtime.Get("users").Array() {
+		sse.PublishString(sse.UserTopic(u.String()), "doc", string(msg))
 	}
 }
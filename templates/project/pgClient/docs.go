@@ -0,0 +1,5 @@
+package pgClient
+
+// файл генерируется автоматически по описанию документов
+
+[[.PrintGoClientDocs]]
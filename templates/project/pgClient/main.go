@@ -0,0 +1,81 @@
+package pgClient
+
+import (
+	"[[.Config.LocalProjectPath]]/pg"
+	"encoding/json"
+)
+
+type (
+	// Json - значение jsonb колонки
+	Json = json.RawMessage
+
+	// ListFilter параметры для функций *List
+	ListFilter struct {
+		Deleted    bool                   // удаленные / существующие
+		OrderBy    string                 // поле для сортировки и направление сортировки. Например, "id desc"
+		Page       int                    // номер страницы. Дефолт: 1
+		PerPage    int                    // количество записей на странице. Дефолт: 1000
+		SearchText string                 // текстовый поиск
+		Params     map[string]interface{} // дополнительные условия выборки по полям документа
+	}
+)
+
+func (f ListFilter) params(userId int64) map[string]interface{} {
+	res := map[string]interface{}{}
+	for k, v := range f.Params {
+		res[k] = v
+	}
+	res["user_id"] = userId
+	res["deleted"] = f.Deleted
+	if len(f.OrderBy) > 0 {
+		res["order_by"] = f.OrderBy
+	}
+	if f.Page > 0 {
+		res["page"] = f.Page
+	}
+	if f.PerPage > 0 {
+		res["per_page"] = f.PerPage
+	}
+	if len(f.SearchText) > 0 {
+		res["search_text"] = f.SearchText
+	}
+	return res
+}
+
+// параметры для функции *_update: структура документа + user_id. Для новой записи id = -1
+func updateParams(userId, id int64, doc interface{}) (map[string]interface{}, error) {
+	jsonStr, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{}
+	if err = json.Unmarshal(jsonStr, &res); err != nil {
+		return nil, err
+	}
+	if id == 0 {
+		res["id"] = -1
+	}
+	// служебные поля не передаем, они заполняются в postgres
+	delete(res, "created_at")
+	delete(res, "updated_at")
+	res["user_id"] = userId
+	return res, nil
+}
+
+func call(funcName string, params interface{}, res interface{}) error {
+	jsonStr, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return pg.CallPgFunc(funcName, jsonStr, res, nil)
+}
+
+func optionValue(options map[string]interface{}, name string, res interface{}) {
+	v, ok := options[name]
+	if !ok {
+		return
+	}
+	if jsonStr, err := json.Marshal(v); err == nil {
+		json.Unmarshal(jsonStr, res)
+	}
+}
@@ -0,0 +1,34 @@
+-- действующие сессии текущего пользователя
+-- параметры:
+-- user_id  type: int - текущий пользователь (добавляется в webServer через BeforeHookAddUserId)
+
+DROP FUNCTION IF EXISTS current_user_session_list(params JSONB );
+CREATE OR REPLACE FUNCTION current_user_session_list(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  checkMsg  TEXT;
+  result    JSON;
+
+BEGIN
+
+  checkMsg = check_required_params_with_func_name('current_user_session_list', params, ARRAY ['user_id']);
+  IF checkMsg IS NOT NULL
+  THEN
+    RETURN checkMsg;
+  END IF;
+
+  SELECT array_to_json(array_agg(t)) INTO result FROM (
+    SELECT id, device, user_agent, ip, created_at, last_seen_at
+    FROM user_session
+    WHERE user_id = (params ->> 'user_id') :: INT AND revoked_at ISNULL AND refresh_expired_at > now()
+    ORDER BY last_seen_at DESC NULLS LAST
+  ) AS t;
+
+  RETURN json_build_object('ok', TRUE, 'result', coalesce(result, '[]'));
+
+END
+
+$function$;
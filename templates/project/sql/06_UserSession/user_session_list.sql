@@ -0,0 +1,59 @@
+-- получение списка сессий пользователей (для администратора)
+-- параметры:
+-- user_id         type: int - пользователь
+-- is_active       type: bool - только действующие (не отозванные и с неистекшим refresh токеном)
+-- order_by        type: string - created_at или last_seen_at и направление сортировки. Дефолт: last_seen_at desc
+-- page            type: int - номер страницы. Дефолт: 1
+-- per_page        type: int - количество записей на странице. Дефолт: 1000
+-- search_text     type: string - текстовый поиск по пользователю, устройству, ip и User-Agent
+
+DROP FUNCTION IF EXISTS user_session_list(params JSONB );
+CREATE OR REPLACE FUNCTION user_session_list(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+
+  result       JSON;
+  whereStr     TEXT := ' where true';
+  orderBy      TEXT := 'doc.last_seen_at desc';
+  perPage      INT := COALESCE((params ->> 'per_page') :: INT, 1000);
+  page         INT := COALESCE((params ->> 'page') :: INT, 1);
+
+BEGIN
+
+  IF (params ->> 'user_id') IS NOT NULL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.user_id = ', (params ->> 'user_id') :: INT);
+  END IF;
+  IF (params ->> 'is_active') :: BOOL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.revoked_at ISNULL AND doc.refresh_expired_at > now()');
+  END IF;
+  IF length(params ->> 'search_text') > 0
+  THEN
+    whereStr = concat(whereStr, ' AND concat_ws('' '', u.fullname, doc.device, doc.ip, doc.user_agent) ilike ',
+                      quote_literal(concat('%', (params ->> 'search_text'), '%')));
+  END IF;
+
+  -- сортировка только по разрешенным полям
+  IF (params ->> 'order_by') IN ('created_at', 'created_at desc', 'last_seen_at', 'last_seen_at desc')
+  THEN
+    orderBy = concat('doc.', params ->> 'order_by');
+  END IF;
+
+  -- хэши токенов не отдаем
+  EXECUTE (
+    ' SELECT array_to_json(array_agg(t)) FROM (SELECT doc.id, doc.user_id, doc.device, doc.user_agent, doc.ip, doc.created_at, doc.last_seen_at, ' ||
+    ' doc.access_expired_at, doc.refresh_expired_at, doc.revoked_at, doc.revoked_by, ' ||
+    ' (doc.revoked_at ISNULL AND doc.refresh_expired_at > now()) as is_active, u.fullname as user_fullname FROM user_session as doc ' ||
+    ' LEFT JOIN "user" u on u.id = doc.user_id ' || whereStr ||
+    ' ORDER BY ' || orderBy || ' NULLS LAST LIMIT ' || perPage || ' OFFSET ' || greatest(page - 1, 0) * perPage || ') AS t')
+  INTO result;
+
+  RETURN json_build_object('ok', TRUE, 'result', coalesce(result, '[]'));
+
+END
+
+$function$;
@@ -0,0 +1,34 @@
+-- отзыв сессии или всех сессий пользователя (для администратора)
+-- параметры:
+-- id               type: int - сессия
+-- session_user_id  type: int - отозвать все сессии этого пользователя
+-- user_id          type: int - кто отзывает (добавляется в webServer через BeforeHookAddUserId)
+
+DROP FUNCTION IF EXISTS user_session_revoke(params JSONB );
+CREATE OR REPLACE FUNCTION user_session_revoke(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  cnt  INT;
+
+BEGIN
+
+  IF (params ->> 'id') IS NULL AND (params ->> 'session_user_id') IS NULL
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'user_session_revoke: missed id or session_user_id');
+  END IF;
+
+  UPDATE user_session SET revoked_at = now(), revoked_by = (params ->> 'user_id') :: INT
+  WHERE revoked_at ISNULL
+    AND ((params ->> 'id') IS NULL OR id = (params ->> 'id') :: INT)
+    AND ((params ->> 'session_user_id') IS NULL OR user_id = (params ->> 'session_user_id') :: INT);
+
+  GET DIAGNOSTICS cnt = ROW_COUNT;
+
+  RETURN json_build_object('ok', TRUE, 'result', json_build_object('count', cnt));
+
+END
+
+$function$;
@@ -0,0 +1,67 @@
+-- получение списка записей журнала вызовов api
+-- параметры:
+-- user_id         type: int - пользователь
+-- method          type: string - название метода
+-- ok              type: bool - успешные / с ошибкой
+-- is_cache        type: bool - ответ из кэша
+-- order_by        type: string - created_at или latency_ms и направление сортировки. Дефолт: created_at desc
+-- page            type: int - номер страницы. Дефолт: 1
+-- per_page        type: int - количество записей на странице. Дефолт: 1000
+-- search_text     type: string - текстовый поиск по методу, ip и сообщению
+
+DROP FUNCTION IF EXISTS api_audit_list(params JSONB );
+CREATE OR REPLACE FUNCTION api_audit_list(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+
+  result       JSON;
+  whereStr     TEXT := ' where true';
+  orderBy      TEXT := 'doc.created_at desc';
+  perPage      INT := COALESCE((params ->> 'per_page') :: INT, 1000);
+  page         INT := COALESCE((params ->> 'page') :: INT, 1);
+
+BEGIN
+
+  -- в журнале нет поля deleted, поэтому условия собираются здесь, а не через where_str_build
+  IF (params ->> 'user_id') IS NOT NULL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.user_id = ', (params ->> 'user_id') :: INT);
+  END IF;
+  IF (params ->> 'method') IS NOT NULL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.method = ', quote_literal(params ->> 'method'));
+  END IF;
+  IF (params ->> 'ok') IS NOT NULL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.ok = ', (params ->> 'ok') :: BOOL);
+  END IF;
+  IF (params ->> 'is_cache') IS NOT NULL
+  THEN
+    whereStr = concat(whereStr, ' AND doc.is_cache = ', (params ->> 'is_cache') :: BOOL);
+  END IF;
+  IF length(params ->> 'search_text') > 0
+  THEN
+    whereStr = concat(whereStr, ' AND concat_ws('' '', doc.method, doc.ip, doc.message) ilike ',
+                      quote_literal(concat('%', (params ->> 'search_text'), '%')));
+  END IF;
+
+  -- сортировка только по разрешенным полям
+  IF (params ->> 'order_by') IN ('created_at', 'created_at desc', 'latency_ms', 'latency_ms desc')
+  THEN
+    orderBy = concat('doc.', params ->> 'order_by');
+  END IF;
+
+  EXECUTE (
+    ' SELECT array_to_json(array_agg(t)) FROM (SELECT doc.*, u.fullname as user_fullname FROM api_audit as doc ' ||
+    ' LEFT JOIN "user" u on u.id = doc.user_id ' || whereStr ||
+    ' ORDER BY ' || orderBy || ' LIMIT ' || perPage || ' OFFSET ' || greatest(page - 1, 0) * perPage || ') AS t')
+  INTO result;
+
+  RETURN json_build_object('ok', TRUE, 'result', coalesce(result, '[]'));
+
+END
+
+$function$;
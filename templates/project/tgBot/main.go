@@ -1,10 +1,12 @@
 package tgBot
 
 import (
+	"context"
 	"fmt"
 	"[[.Config.LocalProjectPath]]/types"
 	"[[.Config.LocalProjectPath]]/cacheUtil"
 	"[[.Config.LocalProjectPath]]/pg"
+	"[[.Config.LocalProjectPath]]/logger"
 	"github.com/tidwall/gjson"
 	tb "gopkg.in/tucnak/telebot.v2"
 	"strconv"
@@ -55,7 +57,7 @@ func Start(config types.Config) {
 	})
 
 	if err != nil {
-		fmt.Printf("tgBot.Start tb.NewBot error: %s\n", err)
+		logger.Errorf("tgBot.Start tb.NewBot error: %s", err)
 		return
 	}
 
@@ -75,7 +77,7 @@ func Start(config types.Config) {
 	bot.Handle("/hello", func(m *tb.Message) {
 		_, err := bot.Send(m.Sender, "Hello World!")
 		if err != nil {
-			fmt.Printf("tgBot send message error: %s\n", err)
+			logger.Errorf("tgBot send message error: %s", err)
 		}
 	})
 
@@ -94,18 +96,18 @@ func Start(config types.Config) {
 		}
 		user, _ := userFindByTelegramId(strconv.Itoa(m.Sender.ID))
 		if user != nil {
-			fmt.Printf("user: %s (%s) send '%s'\n", user.Fullname, m.Sender.Username, m.Text)
+			logger.Debugf("user: %s (%s) send '%s'", user.Fullname, m.Sender.Username, m.Text)
 		} else {
-			fmt.Printf("not auth user %s %v send '%s'\n", m.Sender.Username, m.Sender.ID, m.Text)
+			logger.Debugf("not auth user %s %v send '%s'", m.Sender.Username, m.Sender.ID, m.Text)
 		}
 	})
 
 	bot.Handle(&btnHelp, func(m *tb.Message) {
-		fmt.Printf("in btnHelp %s\n", m.Sender.Username)
+		logger.Debugf("in btnHelp %s", m.Sender.Username)
 	})
 
 	bot.Handle(&btnSettings, func(m *tb.Message) {
-		fmt.Printf("in btnSettings %s\n", m.Sender.Username)
+		logger.Debugf("in btnSettings %s", m.Sender.Username)
 	})
 
 	// On inline button pressed (callback)
@@ -118,7 +120,7 @@ func Start(config types.Config) {
 	bot.Handle(tb.OnPhoto, func(m *tb.Message) {
 		err := bot.Download(&m.Photo.File, "test_photo.jpg")
 		if err != nil {
-			fmt.Printf("err %s\n", err)
+			logger.Errorf("err %s", err)
 		} else {
 			bot.Send(m.Sender, "фото успешно сохранено")
 		}
@@ -127,15 +129,23 @@ func Start(config types.Config) {
 	bot.Start()
 }
 
+// Stop остановка получения сообщений ботом при остановке приложения
+func Stop(ctx context.Context) error {
+	if bot != nil {
+		bot.Stop()
+	}
+	return nil
+}
+
 func SendMsg(tgId, msg string)  {
 	if bot != nil && len(tgId) > 0 && len(msg) > 0 {
 		msg = strings.Replace(msg, "\\n", "\n", -1)
 		answer, err := bot.Send(&tgUser{tgId}, msg, tb.ModeHTML)
 		if err != nil {
-			fmt.Printf("bot.Send error: %s tgId:%s msg:'%s'\n", err, tgId, msg)
+			logger.Errorf("bot.Send error: %s tgId:%s msg:'%s'", err, tgId, msg)
 		}
 		if answer!=nil {
-			fmt.Printf("bot.Send: tgId:%s msg:'%s' answer: %s\n", tgId, msg, answer.Text)
+			logger.Debugf("bot.Send: tgId:%s msg:'%s' answer: %s", tgId, msg, answer.Text)
 		}
 	}
 }
@@ -13,11 +13,86 @@ type GraylogConfig struct {
 	Port int
 }
 
+// Logger лог приложения (секция logger в config.toml)
+type Logger struct {
+	Level            string // debug, info, warn, error
+	Format           string // json, text
+	File             string
+	IsStdoutDisabled bool
+}
+
 type WebServer struct {
+	Enable          bool
+	Port            int64
+	Url             string
+	ShutdownTimeout int64 // секунды
+	TrustedProxies  []string
+	Cors            WebServerCors
+	SecurityHeaders WebServerSecurityHeaders
+}
+// WebServerCors CORS для production (секция webServer.cors в config.toml)
+type WebServerCors struct {
+	AllowOrigins       []string
+	AllowMethods       []string
+	AllowHeaders       []string
+	IsAllowCredentials bool
+	MaxAge             int64 // секунды
+}
+// WebServerSecurityHeaders заголовки безопасности (секция webServer.securityHeaders в config.toml)
+type WebServerSecurityHeaders struct {
+	IsDisabled     bool
+	Csp            string
+	FrameOptions   string
+	ReferrerPolicy string
+	HstsMaxAge     int64 // секунды
+}
+// AuthRateLimit ограничение количества запросов к /auth (секция authRateLimit в config.toml)
+type AuthRateLimit struct {
+	IsDisabled      bool
+	Window          int64 // секунды
+	PerIp           int64
+	PerLogin        int64
+	PerPhone        int64
+	LockoutFailures int64
+	LockoutDuration int64 // секунды
+	IsPgStore       bool
+}
+
+// AuthSession сессии пользователей (секция authSession в config.toml)
+type AuthSession struct {
+	IsDisabled           bool
+	AccessTtl            int64 // секунды
+	RefreshTtl           int64 // секунды
+	IsLegacyTokenAllowed bool
+}
+
+// ApiAudit журнал вызовов pg методов (секция apiAudit в config.toml)
+type ApiAudit struct {
+	IsEnabled     bool
+	RedactFields  []string
+	RetentionDays int64
+	BatchSize     int64
+	FlushInterval int64 // миллисекунды
+	BufferSize    int64
+}
+
+// Sse доставка событий по SSE (секция sse в config.toml)
+type Sse struct {
+	BufferSize        int64
+	HeartbeatInterval int64 // секунды
+	ReplaySize        int64
+	SlowClientPolicy  string // drop, disconnect
+}
+
+[[if .IsMetrics -]]
+// Metrics метрики prometheus (секция metrics в config.toml)
+type Metrics struct {
 	Enable bool
-	Port   int64
-	Url    string
+	Path   string
+	Token  string
 }
+[[- end]]
+
 type EmailConfig struct {
 	Sender     string // email отправителя
 	Password   string
@@ -0,0 +1,53 @@
+// файл генерируется автоматически по описанию документов
+
+export interface User {
+  id: number
+  username: string
+  first_name: string
+  last_name: string
+  fullname: string
+  avatar: string
+  role: string[]
+  state: string
+  auth_provider: string
+  auth_provider_id: string
+  options: {[key: string]: any}
+  deleted: boolean
+  phone: string
+  email?: string
+}
+
+// параметры для методов *_list
+export interface ListParams {
+  deleted?: boolean
+  order_by?: string
+  page?: number
+  per_page?: number
+  search_text?: string
+}
+
+[[.PrintTsDocInterfaces]]
+
+// параметры и результат каждого pg метода
+export interface PgMethodMap {
+[[.PrintTsPgMethodMap]]
+}
+
+export type PgMethodName = keyof PgMethodMap
+
+export declare const PgMethod: {
+[[.PrintTsPgMethodConsts]]
+}
+
+export interface CallPgFuncOptions {
+  isShowError?: boolean
+  successMsg?: string | null
+}
+
+export declare function callPgFunc<M extends PgMethodName>(method: M, params?: PgMethodMap[M]['params'], options?: CallPgFuncOptions): Promise<PgMethodMap[M]['result']>
+
+declare const _default: {
+  PgMethod: typeof PgMethod
+  callPgFunc: typeof callPgFunc
+}
+export default _default
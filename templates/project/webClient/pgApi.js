@@ -0,0 +1,20 @@
+// файл генерируется автоматически по описанию документов. Типы документов и методов описаны в pgApi.d.ts
+import utils from './utils'
+
+// названия pg методов, доступных через /api/call_pg_func
+export const PgMethod = Object.freeze({
+[[.PrintJsPgMethodConsts]]
+})
+
+// вызов postgres функции через /api/call_pg_func. Возвращает Promise с результатом функции
+export const callPgFunc = (method, params = {}, {isShowError = true, successMsg = null} = {}) => new Promise((resolve, reject) => {
+  utils.postCallPgMethod({method, params, isShowError, successMsg}).subscribe(res => {
+    if (res.ok) {
+      resolve(res.result)
+    } else {
+      reject(new Error(res.message))
+    }
+  })
+})
+
+export default {PgMethod, callPgFunc}
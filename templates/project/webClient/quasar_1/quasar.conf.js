@@ -9,6 +9,7 @@ module.exports = function (ctx) {
       'config',
       'utils',
       'currentUser',
+      'ws',
       'myCommon',
       'userTasks',
       'axios',
@@ -25,6 +25,7 @@ module.exports = configure(function (ctx) {
       'config',
       'axios',
       'currentUser',
+      'ws',
       'utils',
       'myCommon',
       'i18n'
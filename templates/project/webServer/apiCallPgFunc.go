@@ -1,10 +1,12 @@
 package webServer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"[[.Config.LocalProjectPath]]/cacheUtil"
 	"[[.Config.LocalProjectPath]]/pg"
 	"[[.Config.LocalProjectPath]]/types"
 	"[[.Config.LocalProjectPath]]/utils"
@@ -20,10 +22,8 @@ type (
 		Roles      []string
 		Cache      PgMethodCache
 		BeforeHook func(*gin.Context, interface{}) error
-	}
-	pgFuncCacheType struct {
-		Data        []byte
-		ExpiredTime time.Time
+		Timeout    time.Duration // таймаут вызова функции. Если 0, то pg.DefaultCallTimeout
+		Tables     []string      // таблицы, при изменении которых сбрасывается кэш метода (событие из pg.processPgEvent)
 	}
 	PgMethodCache interface {
 		Duration() time.Duration                                        // время кэширования в секундах
@@ -32,14 +32,24 @@ type (
 )
 
 var (
-	pgFuncCache = map[string]pgFuncCacheType{}
+	pgFuncCache = cacheUtil.NewCache("pg_func", 5000)
 	pgFuncList  = []PgMethod{
-		PgMethod{"user_update", []string{"admin",[[ArrayStringJoin .Config.User.Roles.UserUpdate ]]}, nil, nil},
-		PgMethod{"user_list", []string{[[ArrayStringJoin .Config.User.Roles.UserList ]]}, nil, nil},
-		PgMethod{"user_get_by_id", []string{}, nil, BeforeHookAddUserId},
-		PgMethod{"user_get_by_id_for_ui", []string{}, nil, BeforeHookAddUserId},
-		PgMethod{"current_user_update", []string{}, nil, BeforeHookAddUserId},
-		PgMethod{"current_user_get_auth_providers", []string{}, nil, BeforeHookAddUserId},
+		PgMethod{Title: "user_update", Roles: []string{"admin",[[ArrayStringJoin .Config.User.Roles.UserUpdate ]]}, Tables: []string{"user"}},
+		PgMethod{Title: "user_list", Roles: []string{[[ArrayStringJoin .Config.User.Roles.UserList ]]}, Tables: []string{"user"}},
+		PgMethod{Title: "user_get_by_id", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "user_get_by_id_for_ui", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "current_user_update", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "current_user_get_auth_providers", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user_auth"}},
+		[[- if .IsAuthSession]]
+		// сессии пользователей. user_id в user_session_revoke - кто отзывает, пользователь сессий передается в session_user_id
+		PgMethod{Title: "user_session_list", Roles: []string{"admin"}},
+		PgMethod{Title: "user_session_revoke", Roles: []string{"admin"}, BeforeHook: BeforeHookAddUserId},
+		PgMethod{Title: "current_user_session_list", Roles: []string{}, BeforeHook: BeforeHookAddUserId},
+		PgMethod{Title: "current_user_session_revoke", Roles: []string{}, BeforeHook: BeforeHookAddUserId},
+		[[- end]]
+		[[- if .IsApiAudit]]
+		PgMethod{Title: "api_audit_list", Roles: []string{"admin"}},
+		[[- end]]
 		[[.PrintApiCallPgFuncMethods]]
 	}
 )
@@ -58,22 +68,52 @@ func apiCallPgFunc(c *gin.Context) {
 		return
 	}
 
+	queryRes, ok := callPgMethod(c, jsonParam)
+	if !ok {
+		return
+	}
+	pgMethodResponse(c, http.StatusOK, queryRes)
+}
+
+// callPgMethod вызов метода из pgFuncList с проверкой ролей, before hook'ами и кэшем. Вызов записывается в журнал api_audit.
+// Используется в /api/call_pg_func и в REST api. В случае ошибки ответ уже записан в c и возвращается false
+func callPgMethod(c *gin.Context, jsonParam JsonParamType) ([]byte, bool) {
+	queryRes, status, err := execPgMethod(c, jsonParam)
+	if err != nil {
+		utils.HttpError(c, status, err.Error())
+		return nil, false
+	}
+	return queryRes, true
+}
+
+// execPgMethod то же, что callPgMethod, но ошибка с http статусом возвращается, а не записывается в ответ. Используется в websocket
+func execPgMethod(c *gin.Context, jsonParam JsonParamType) ([]byte, int, error) {
 	// проверяем что метод из списка разрешенных для вызова через api
 	isCorrectMethod := false
 	isAllowedMethod := false
 	// создаем переменную для хранении информации о кэше
 	cacheResult := struct {
-		Data           []byte
-		NewExpiredTime time.Time
-		Key            string
+		Data     []byte
+		Duration time.Duration
+		Key      string
 	}{}
 
 	u, _ := c.Get(utils.GinContextUser)
 	user := u.(*types.User)
 
+	audit := newApiAuditCall(c, user, jsonParam)
+	defer audit.finish()
+	defer observePgMethod(audit)
+	fail := func(status int, msg string) ([]byte, int, error) {
+		audit.fail(msg)
+		return nil, status, errors.New(msg)
+	}
+
+	var method PgMethod
 	for _, v := range pgFuncList {
 		if v.Title == jsonParam.Method {
 			isCorrectMethod = true
+			method = v
 			// если роли для данного метода не указаны, то метод автоматически разрешен
 			if len(v.Roles) == 0 {
 				isAllowedMethod = true
@@ -87,60 +127,59 @@ func apiCallPgFunc(c *gin.Context) {
 					}
 				}
 			}
-			// проверяем есть ли beforeHook
-			if v.BeforeHook != nil {
-				err := v.BeforeHook(c, jsonParam)
-				if err != nil {
-					utils.HttpError(c, http.StatusMethodNotAllowed, err.Error())
-					return
-				}
-			}
-			if v.Cache != nil {
-				key, err := v.Cache.GetKey(user.IdString(), user.GetRoleAsString(), jsonParam.Params)
-				if err == nil {
-					// в случае ошибки не формируем ключ для кэширования. Детали об ошибки выводим внутри метода.
-					// заполняем информацию, необходимую для кэширования
-					cacheResult.Key = key
-					cacheResult.NewExpiredTime = time.Now().Add(v.Cache.Duration() * time.Second)
-					if res, ok := pgFuncCache[key]; ok {
-						// проверяем не истекло ли время кэша
-						if res.ExpiredTime.After(time.Now()) {
-							cacheResult.Data = res.Data
-						}
-					}
-				}
-			}
 			break
 		}
 	}
 	// если метода нет в списке то выходим
 	if !isCorrectMethod {
-		utils.HttpError(c, http.StatusMethodNotAllowed, "not allowed method: "+jsonParam.Method)
-		return
+		return fail(http.StatusMethodNotAllowed, "not allowed method: "+jsonParam.Method)
 	}
 
 	// если метода нет в списке то выходим
 	if !isAllowedMethod {
-		utils.HttpError(c, http.StatusMethodNotAllowed, "for this role not allowed method: "+jsonParam.Method)
-		return
+		return fail(http.StatusMethodNotAllowed, "for this role not allowed method: "+jsonParam.Method)
+	}
+
+	// hook'и и кэш - только после проверки ролей, чтобы без прав не срабатывали побочные эффекты hook'ов
+	if method.BeforeHook != nil {
+		err := method.BeforeHook(c, jsonParam)
+		if err != nil {
+			return fail(http.StatusMethodNotAllowed, err.Error())
+		}
+	}
+	if method.Cache != nil {
+		key, err := method.Cache.GetKey(user.IdString(), user.GetRoleAsString(), jsonParam.Params)
+		if err == nil {
+			// в случае ошибки не формируем ключ для кэширования. Детали об ошибки выводим внутри метода.
+			// заполняем информацию, необходимую для кэширования
+			cacheResult.Key = key
+			cacheResult.Duration = method.Cache.Duration() * time.Second
+			if res, ok := pgFuncCache.Get(key); ok {
+				cacheResult.Data = res.([]byte)
+			}
+		}
 	}
 
 	// запрос к postgres, если нет данных из кэша
 	queryRes := []byte("")
 	if len(cacheResult.Data) == 0 {
 		var err error
-		queryRes, err = callPgFuncToJson(jsonParam.Method, jsonParam.Params)
+		queryRes, err = callPgFuncToJson(c.Request.Context(), method, jsonParam.Params)
 		if err != nil {
-			utils.HttpError(c, http.StatusBadRequest, processPgErrorMsg(err))
-			return
+			var timeoutErr *pg.PgTimeoutError
+			if errors.As(err, &timeoutErr) {
+				return fail(http.StatusGatewayTimeout, "timeout of method: "+jsonParam.Method)
+			}
+			return fail(http.StatusBadRequest, processPgErrorMsg(err))
 		}
 		// в случае если указан ключ для кэширования, сохраняем полученные данные из базы в кэш
 		if len(cacheResult.Key) > 0 {
-			pgFuncCache[cacheResult.Key] = pgFuncCacheType{queryRes, cacheResult.NewExpiredTime}
+			pgFuncCache.Set(cacheResult.Key, queryRes, cacheResult.Duration, method.Tables...)
 		}
 	} else {
 		queryRes = cacheResult.Data
 	}
+	audit.done(gjson.GetBytes(queryRes, "ok").Bool(), gjson.GetBytes(queryRes, "message").String(), len(cacheResult.Data) > 0)
 
 	//// логгирование обращений к сайту
 	//{
@@ -150,8 +189,12 @@ func apiCallPgFunc(c *gin.Context) {
 	//
 	//}
 
-	// перекладываем ответ из jsonParam в jsonParam
-	c.JSON(http.StatusOK, gin.H{
+	return queryRes, http.StatusOK, nil
+}
+
+// pgMethodResponse перекладываем ответ postgres функции в ответ сервера
+func pgMethodResponse(c *gin.Context, status int, queryRes []byte) {
+	c.JSON(status, gin.H{
 		"ok":        gjson.Get(fmt.Sprintf("%s", queryRes), "ok").Bool(),
 		"result":    gjson.Get(fmt.Sprintf("%s", queryRes), "result").Value(),
 		"message":   gjson.Get(fmt.Sprintf("%s", queryRes), "message").Value(),
@@ -159,38 +202,40 @@ func apiCallPgFunc(c *gin.Context) {
 	})
 }
 
-func callPgFuncToJson(funcName string, jsonMap interface{}) (queryRes []byte, err error) {
-	var queryStr string
+// callPgFuncToJson вызов функции из pgFuncList. Параметры передаются в запрос как $1::jsonb, запрос прерывается
+// по таймауту метода или при отмене контекста запроса (клиент закрыл соединение)
+func callPgFuncToJson(ctx context.Context, method PgMethod, jsonMap interface{}) (queryRes []byte, err error) {
 	var jsonStr []byte
 
 	// по разному обрабатываем параметры запроса в зависмости от типа: может быть строка, а может быть map
 	switch v := jsonMap.(type) {
+	case nil:
+		jsonStr = []byte{}
 	case string:
-		v = strings.Replace(v, "'", "''", -1)
-		jsonStr = []byte(v)
-	case map[string]interface{}:
+		if len(strings.TrimSpace(v)) > 0 {
+			jsonStr = []byte(v)
+		}
+	default:
 		if jsonStr, err = json.Marshal(v); err != nil {
 			return
 		}
-		jsonStr = []byte(strings.Replace(string(jsonStr), "'", "''", -1))
-	default:
-		jsonStr = []byte{}
 	}
 
-	if len(jsonStr) > 0 {
-		queryStr = fmt.Sprintf("select * from %s('%s')", funcName, jsonStr)
-	} else {
-		queryStr = fmt.Sprintf("select * from %s()", funcName)
+	timeout := method.Timeout
+	if timeout == 0 {
+		timeout = pg.DefaultCallTimeout
 	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	//println("!!! callPgFuncToJson queryStr:", queryStr)
+	return pg.CallRegisteredPgFuncContext(ctx, method.Title, jsonStr)
+}
 
-	err = pg.Pg.QueryRow(queryStr).Scan(&queryRes)
-	if err != nil {
-		return
+// registerPgFuncs регистрация методов pgFuncList в pg: вызвать через api можно только их
+func registerPgFuncs() {
+	for _, v := range pgFuncList {
+		pg.RegisterFuncs(v.Title)
 	}
-
-	return
 }
 
 func BeforeHookAddUserId(c *gin.Context, p interface{}) error {
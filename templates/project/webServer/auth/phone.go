@@ -2,12 +2,14 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"[[.Config.LocalProjectPath]]/pg"
 	"[[.Config.LocalProjectPath]]/types"
 	"[[.Config.LocalProjectPath]]/utils"
 	"golang.org/x/crypto/bcrypt"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"os"
@@ -109,19 +111,20 @@ func PhoneAuth(c *gin.Context) {
 		jsonStr, err := json.Marshal(userRegData)
 		err = pg.CallPgFunc("user_get_by_phone_with_password", jsonStr, &user, nil)
 		if err != nil {
+			authFailure(rateLimitKindPhone, login)
 			utils.HttpError(c, http.StatusOK, fmt.Sprintf("pg call user_get_by_phone_with_password err %s", err))
 			return
 		}
 		// проверяем пароль
 		err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(reqParams.Params.Password))
 		if err != nil {
+			authFailure(rateLimitKindPhone, login)
 			utils.HttpError(c, http.StatusOK, "wrong password")
 			return
 		}
-		// перед отправкой в данных пользователя стираем пароль
-		user.Password = ""
-
-		utils.HttpSuccess(c, user)
+		authSuccess(rateLimitKindPhone, login)
+		// создаем сессию, пароль стирается перед отправкой
+		SessionStart(c, &user)
 	}
 }
 
@@ -155,6 +158,7 @@ func CheckSmsCode(c *gin.Context) {
 	jsonStr, err := json.Marshal(queryData.Params)
 	err = pg.CallPgFunc("user_temp_phone_auth_check_sms_code", jsonStr, &user, nil)
 	if err != nil {
+		authFailure(rateLimitKindPhone, queryData.Params.Phone)
 		if len(err.Error()) > 0 {
 			utils.HttpError(c, http.StatusOK, "pg call user_temp_phone_auth_check_sms_code err:"+fmt.Sprintf("%s", err))
 		} else {
@@ -163,7 +167,7 @@ func CheckSmsCode(c *gin.Context) {
 		return
 	}
 
-	utils.HttpSuccess(c, user)
+	SessionStart(c, &user)
 }
 
 // функция начала сброса пароля. Создаем пару phone-токен и отправляем пользователю sms с токеном
@@ -205,7 +209,6 @@ func PhoneAuthStartRecoverPassword(c *gin.Context) {
 
 	token := strconv.Itoa(100000 + rand.Intn(999999-100000))
 	phone := queryData.Params.Phone
-	fmt.Printf("token: %s\n", token)
 	// добавляем пару токен-email а коллекцию
 	passwordRecoverPhoneTokenMap[phone] = PasswordRecoverPhoneToken{phone, token, time.Now().Add(1 * time.Minute)}
 	//удаляем просроченные токены
@@ -269,6 +272,7 @@ func PhoneAuthRecoverPassword(c *gin.Context) {
 		// сравниваем код полученный от пользоваателя, с тем, который был отправлен ему по sms
 		if v.Token != queryData.Params.Token {
 			// токен не совпадает с тем, который был отправлен по sms
+			authFailure(rateLimitKindPhone, queryData.Params.Phone)
 			utils.HttpError(c, http.StatusOK, "invalid token")
 			return
 		}
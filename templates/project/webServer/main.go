@@ -1,6 +1,7 @@
 package webServer
 
 import (
+	"[[.Config.LocalProjectPath]]/lifecycle"
 	"[[.Config.LocalProjectPath]]/sse"
 	"[[.Config.LocalProjectPath]]/types"
 	"[[.Config.LocalProjectPath]]/utils"
@@ -14,20 +15,53 @@ import (
 	"[[.Config.LocalProjectPath]]/odata"
 	[[- end]]
 	"net/http"
+	"os"
 	[[- range .Go.Routes.Imports]]
 		"[[.]]"
 	[[- end]]
 	"fmt"
 )
 
+// StartWebServer запуск веб-сервера. Не блокирует, остановка - через lifecycle
 func StartWebServer(config types.Config) {
 	r := gin.New()
+	// ip клиента (в том числе для ограничения запросов по ip) берется из X-Forwarded-For только от доверенных прокси
+	var trustedProxies []string
+	if len(config.WebServer.TrustedProxies) > 0 {
+		trustedProxies = config.WebServer.TrustedProxies
+	}
+	utils.CheckErr(r.SetTrustedProxies(trustedProxies), "webServer.trustedProxies")
+	// request_id и запись о каждом запросе в лог
+	r.Use(requestId, requestLog)
 
 	// передаем конфиги для модуля авторизации
 	auth.SetWebServerConfig(config.WebServer)
+	auth.SetRateLimitConfig(config.AuthRateLimit)
+	auth.SetSessionConfig(config.AuthSession)
+	// журнал вызовов pg методов
+	startApiAudit(config.ApiAudit)
+	// через api вызываются только методы из pgFuncList
+	registerPgFuncs()
+	[[- if .IsRealtime]]
+	// подписка на топики документов по ролям (DocType.Realtime)
+	sse.SetTopicAuthorizer(realtimeTopicAuthorizer)
+	[[- end]]
 
-	// вырубаем CORS
-	r.Use(LiberalCORS)
+	// CORS по настройкам webServer.cors. В dev режиме webClient запускается на другом порту, поэтому разрешаем любые источники
+	if os.Getenv("IS_DEVELOPMENT") == "true" {
+		r.Use(LiberalCORS)
+	} else {
+		r.Use(Cors(config.WebServer))
+	}
+	r.Use(SecurityHeaders(config.WebServer))
+	[[- if .IsMetrics]]
+	// метрики prometheus. Middleware подключается до роутов, чтобы считать все запросы
+	if config.Metrics.Enable {
+		startMetrics()
+		r.Use(metricsMiddleware)
+		r.GET(config.Metrics.Path, metricsHandler(config.Metrics.Token))
+	}
+	[[- end]]
 	[[- range .Go.Routes.Static]]
 		[[.]]
 	[[- end]]
@@ -35,21 +69,30 @@ func StartWebServer(config types.Config) {
 	r.Static("/static", "./webClient/dist")
 	r.Static("/statics", "./webClient/dist/statics")
 	r.StaticFile("/", "./webClient/dist/index.html")
-
-	// АВТОРИЗАЦИЯ
-	authRoute := r.Group("/auth")
+	// проверки для healthcheck docker-compose и балансировщика
+	r.GET("/healthz", healthz)
+	r.GET("/readyz", readyz)
+	// описание api в формате OpenAPI 3
+	r.StaticFile("/openapi.json", "./webServer/openapi.json")
+
+	// АВТОРИЗАЦИЯ. Количество запросов ограничено по ip, email и телефону (auth.RateLimit*)
+	authRoute := r.Group("/auth", auth.RateLimitByIp)
 	{
 		// авторизация через email
-		authRoute.POST("/email", auth.EmailAuth)
+		authRoute.POST("/email", auth.RateLimitByLogin, auth.EmailAuth)
 		authRoute.POST("/check_user_email", auth.EmailAuthCheckUserEmail)
-		authRoute.POST("/email_auth_start_recover_password", auth.EmailAuthStartRecoverPassword)
+		authRoute.POST("/email_auth_start_recover_password", auth.RateLimitByLogin, auth.EmailAuthStartRecoverPassword)
 		authRoute.POST("/email_auth_recover_password", auth.EmailAuthRecoverPassword)
+		[[- if .IsAuthSession]]
+		// обновление токенов сессии
+		authRoute.POST("/refresh", auth.SessionRefresh)
+		[[- end]]
 		[[if .Config.Auth.ByPhone -]]
 		// авторизация по номеру телефона
-		authRoute.POST("/phone", auth.PhoneAuth)
-		authRoute.POST("/check_sms_code", auth.CheckSmsCode)
-		authRoute.POST("/phone_auth_start_recover_password", auth.PhoneAuthStartRecoverPassword)
-		authRoute.POST("/phone_auth_recover_password", auth.PhoneAuthRecoverPassword)
+		authRoute.POST("/phone", auth.RateLimitByPhone, auth.PhoneAuth)
+		authRoute.POST("/check_sms_code", auth.RateLimitByPhone, auth.CheckSmsCode)
+		authRoute.POST("/phone_auth_start_recover_password", auth.RateLimitByPhone, auth.PhoneAuthStartRecoverPassword)
+		authRoute.POST("/phone_auth_recover_password", auth.RateLimitByPhone, auth.PhoneAuthRecoverPassword)
 		[[- end]]
 	}
 
@@ -57,12 +100,17 @@ func StartWebServer(config types.Config) {
 	{
 		apiRoute.POST("/current_user", apiCurrentUser)
 		apiRoute.POST("/call_pg_func", apiCallPgFunc)
-		[[- if .Config.Graylog.Host -]]
-		// отправка логов в graylog
-		apiRoute.POST("/log", logToGraylog)
+		[[- if .IsAuthSession]]
+		// выход: отзыв текущей сессии или всех сессий пользователя
+		apiRoute.POST("/logout", auth.Logout)
+		apiRoute.POST("/logout_all", auth.LogoutAll)
 		[[- end]]
+		// запись в лог приложения с клиента
+		apiRoute.POST("/log", logFromClient)
 		// подключение по SSE
 		apiRoute.GET("/sse", sse.AddConn)
+		// websocket: события как в SSE и вызов pg методов
+		apiRoute.GET("/ws", wsHandler(config.WebServer))
 		// операции с файлами
 		apiRoute.POST("/upload_file", uploadFile)
 		apiRoute.GET("/file/:fileToken", downloadFile)
@@ -98,6 +146,13 @@ func StartWebServer(config types.Config) {
 		[[- end]]
 	}
 
+	[[- if .IsRestApi]]
+
+	// REST api документов. Авторизация отдельная от /api, так как в теле запроса передается документ, а не JsonParamType
+	restRoute := r.Group("/api/v1", restAuthRequired)
+	addRestRoutes(restRoute)
+	[[- end]]
+
 	[[- range .Go.Routes.NotAuth]]
 	[[.]]
 	[[- end]]
@@ -126,8 +181,16 @@ func StartWebServer(config types.Config) {
 		http.ServeFile(c.Writer, c.Request, "./webClient/dist/index.html")
 	})
 
-	err := r.Run(fmt.Sprintf(":%v", config.WebServer.Port))
-	utils.CheckErr(err, "webserver run")
+	srv := &http.Server{Addr: fmt.Sprintf(":%v", config.WebServer.Port), Handler: r}
+	go func() {
+		err := srv.ListenAndServe()
+		if err != http.ErrServerClosed {
+			utils.CheckErr(err, "webserver run")
+		}
+	}()
+	// при остановке: сначала закрываются SSE соединения, затем веб-сервер дожидается выполнения текущих запросов
+	lifecycle.Register("web server", srv.Shutdown)
+	lifecycle.Register("sse", sse.CloseAll)
 }
 
 func apiCurrentUser(c *gin.Context) {
@@ -0,0 +1,78 @@
+package webServer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"[[.Config.LocalProjectPath]]/logger"
+	"strings"
+	"time"
+)
+
+type (
+	PgMethodHook func(*gin.Context, interface{}) error
+
+	// pgMethodCachePolicy кэширование pg метода, описанное в DocSqlMethod.Cache
+	pgMethodCachePolicy struct {
+		method string
+		ttl    int
+		scope  []string // user, role, params
+	}
+)
+
+var (
+	// hook'и, которые можно указать в DocSqlMethod.Hooks
+	pgMethodHooks = map[string]PgMethodHook{
+		"addUserId": BeforeHookAddUserId,
+[[.PrintPgMethodHooks]]
+	}
+)
+
+// pgMethodHookChain объединение hook'ов в один: выполняются по порядку до первой ошибки
+func pgMethodHookChain(names ...string) func(*gin.Context, interface{}) error {
+	chain := []PgMethodHook{}
+	for _, name := range names {
+		h, ok := pgMethodHooks[name]
+		if !ok {
+			panic(fmt.Sprintf("pgMethodHookChain: unknown hook '%s'", name))
+		}
+		chain = append(chain, h)
+	}
+	return func(c *gin.Context, p interface{}) error {
+		for _, h := range chain {
+			if err := h(c, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newPgMethodCache(method string, ttl int, scope ...string) PgMethodCache {
+	return pgMethodCachePolicy{method, ttl, scope}
+}
+
+func (p pgMethodCachePolicy) Duration() time.Duration {
+	return time.Duration(p.ttl)
+}
+
+func (p pgMethodCachePolicy) GetKey(userId, role string, params interface{}) (string, error) {
+	key := []string{p.method}
+	for _, s := range p.scope {
+		switch s {
+		case "user":
+			key = append(key, "user:"+userId)
+		case "role":
+			key = append(key, "role:"+role)
+		case "params":
+			// параметры целиком, вместе с user_id: результат одного пользователя не должен попасть к другому
+			b, err := json.Marshal(params)
+			if err != nil {
+				logger.Errorf("pg method '%s' cache key error: %s", p.method, err)
+				return "", err
+			}
+			key = append(key, "params:"+string(b))
+		}
+	}
+	return strings.Join(key, "|"), nil
+}
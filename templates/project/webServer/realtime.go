@@ -0,0 +1,41 @@
+package webServer
+
+import (
+	"[[.Config.LocalProjectPath]]/types"
+	"[[.Config.LocalProjectPath]]/utils"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+var (
+	// роли, которым разрешена подписка на топики doc:<таблица> и doc:<таблица>:<id> (DocType.Realtime).
+	// Таблиц без ролей и с получателями (Realtime.Recipients) здесь нет - подписка на их топики запрещена
+	realtimeTopicRoles = map[string][]string{
+[[.PrintRealtimeTopicRoles]]
+	}
+)
+
+// realtimeTopicAuthorizer проверка подписки на топики документов по ролям из realtimeTopicRoles.
+// Остальные топики (кроме своего user:<id>, который разрешен всегда) запрещены
+func realtimeTopicAuthorizer(c *gin.Context, userId, topic string) bool {
+	if !strings.HasPrefix(topic, "doc:") {
+		return false
+	}
+	table := strings.SplitN(strings.TrimPrefix(topic, "doc:"), ":", 2)[0]
+	roles, ok := realtimeTopicRoles[table]
+	if !ok || len(roles) == 0 {
+		return false
+	}
+	u, ok := c.Get(utils.GinContextUser)
+	if !ok {
+		return false
+	}
+	for _, role := range roles {
+		for _, userRole := range u.(*types.User).Role {
+			if userRole == role {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package webServer
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"[[.Config.LocalProjectPath]]/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"strconv"
+)
+
+type (
+	// restResource - REST api документа: /api/v1/<Doc>. Запросы вызывают те же pg методы, что и /api/call_pg_func,
+	// с теми же проверками ролей, hook'ами и кэшем (callPgMethod)
+	restResource struct {
+		Doc     string            // название документа
+		List    string            // pg метод для GET /<Doc>. Пустая строка - роута нет
+		Get     string            // pg метод для GET /<Doc>/:id
+		Update  string            // pg метод для POST /<Doc>, PATCH /<Doc>/:id, DELETE /<Doc>/:id
+		Action  string            // pg метод для POST /<Doc>/:id/actions/<action>
+		Filters map[string]string // query параметры списка: название -> тип (int, float, bool, string)
+		Actions []string          // переходы state machine
+	}
+)
+
+var (
+	restResources = []restResource{
+[[.PrintRestResources]]
+	}
+	// query параметры, которые есть у списка любого документа
+	restListBaseFilters = map[string]string{
+		"deleted":     "bool",
+		"order_by":    "string",
+		"page":        "int",
+		"per_page":    "int",
+		"search_text": "string",
+	}
+)
+
+func addRestRoutes(g *gin.RouterGroup) {
+	for _, res := range restResources {
+		res := res
+		url := "/" + res.Doc
+		if len(res.List) > 0 {
+			g.GET(url, res.list)
+		}
+		if len(res.Get) > 0 {
+			g.GET(url+"/:id", res.get)
+		}
+		if len(res.Update) > 0 {
+			g.POST(url, res.create)
+			g.PATCH(url+"/:id", res.update)
+			g.DELETE(url+"/:id", res.delete)
+		}
+		if len(res.Action) > 0 {
+			g.POST(url+"/:id/actions/:action", res.action)
+		}
+	}
+}
+
+func (res restResource) list(c *gin.Context) {
+	params := map[string]interface{}{}
+	for name, values := range c.Request.URL.Query() {
+		fldType, ok := res.Filters[name]
+		if !ok {
+			if fldType, ok = restListBaseFilters[name]; !ok {
+				continue
+			}
+		}
+		v, err := restParseParam(fldType, values[0])
+		if err != nil {
+			utils.HttpError(c, http.StatusBadRequest, fmt.Sprintf("wrong value of param '%s': %s", name, err))
+			return
+		}
+		params[name] = v
+	}
+	restCall(c, http.StatusOK, res.List, params)
+}
+
+func (res restResource) get(c *gin.Context) {
+	id, ok := restId(c)
+	if !ok {
+		return
+	}
+	restCall(c, http.StatusOK, res.Get, map[string]interface{}{"id": id})
+}
+
+func (res restResource) create(c *gin.Context) {
+	params, ok := restBody(c)
+	if !ok {
+		return
+	}
+	// новая запись в *_update создается при id = -1
+	params["id"] = -1
+	restCall(c, http.StatusCreated, res.Update, params)
+}
+
+func (res restResource) update(c *gin.Context) {
+	id, ok := restId(c)
+	if !ok {
+		return
+	}
+	params, ok := restBody(c)
+	if !ok {
+		return
+	}
+	params["id"] = id
+	restCall(c, http.StatusOK, res.Update, params)
+}
+
+func (res restResource) delete(c *gin.Context) {
+	id, ok := restId(c)
+	if !ok {
+		return
+	}
+	restCall(c, http.StatusOK, res.Update, map[string]interface{}{"id": id, "deleted": true})
+}
+
+func (res restResource) action(c *gin.Context) {
+	id, ok := restId(c)
+	if !ok {
+		return
+	}
+	actionName := c.Param("action")
+	isFound := false
+	for _, a := range res.Actions {
+		if a == actionName {
+			isFound = true
+			break
+		}
+	}
+	if !isFound {
+		utils.HttpError(c, http.StatusNotFound, "unknown action: "+actionName)
+		return
+	}
+	params := map[string]interface{}{}
+	// поля, которые заполняются при переходе, передаются в теле запроса. Тело может быть пустым
+	if c.Request.ContentLength != 0 {
+		if params, ok = restBody(c); !ok {
+			return
+		}
+	}
+	params["id"] = id
+	params["action_name"] = actionName
+	restCall(c, http.StatusOK, res.Action, params)
+}
+
+// вызов pg метода и запись ответа. Для GET запросов проставляется ETag, чтобы клиент и прокси могли не загружать неизмененные данные
+func restCall(c *gin.Context, status int, method string, params map[string]interface{}) {
+	queryRes, ok := callPgMethod(c, JsonParamType{Method: method, Params: params})
+	if !ok {
+		return
+	}
+	if !gjson.GetBytes(queryRes, "ok").Bool() {
+		pgMethodResponse(c, http.StatusBadRequest, queryRes)
+		return
+	}
+	if c.Request.Method == http.MethodGet {
+		etag := fmt.Sprintf(`W/"%x"`, sha1.Sum(queryRes))
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", "private, no-cache")
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+	pgMethodResponse(c, status, queryRes)
+}
+
+func restId(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		utils.HttpError(c, http.StatusBadRequest, "wrong id: "+c.Param("id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func restBody(c *gin.Context) (map[string]interface{}, bool) {
+	params := map[string]interface{}{}
+	if err := c.ShouldBindJSON(&params); err != nil {
+		utils.HttpError(c, http.StatusBadRequest, "json body error: "+err.Error())
+		return nil, false
+	}
+	// служебные поля не передаем, они заполняются в postgres
+	delete(params, "created_at")
+	delete(params, "updated_at")
+	delete(params, "user_id")
+	return params, true
+}
+
+func restParseParam(fldType, value string) (interface{}, error) {
+	switch fldType {
+	case "int":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(value, 64)
+	case "bool":
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"[[.Config.LocalProjectPath]]/logger"
 	"os"
 	"os/exec"
 	"strings"
@@ -40,7 +41,7 @@ func removeBackupFile(path string) {
 	cmd.Stderr = os.Stderr
 	err := cmd.Run()
 	if err != nil {
-		fmt.Printf("removeBackupFile err %s\n", err)
+		logger.Errorf("removeBackupFile err %s", err)
 	}
 }
 
@@ -55,7 +56,7 @@ func removeOldBackupsOnServer() error {
 		if i >= [[.Config.Backup.ToYandexDisk.FilesCount]] {
 			err = deleteFile(v.Path)
 			if err != nil {
-				fmt.Printf("deleteFile err: %s\n", err)
+				logger.Errorf("deleteFile err: %s", err)
 			}
 		}
 	}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"fmt"
+	"[[.Config.LocalProjectPath]]/logger"
 	"time"
 )
 
@@ -20,18 +20,18 @@ func createBackup()  {
 	// создаем папку на яндексе для проекта
 	err := createFolder("[[.Config.Backup.ToYandexDisk.Path]]")
 	if err != nil {
-		fmt.Printf("error createFolder [[.Config.Backup.ToYandexDisk.Path]]")
+		logger.Errorf("error createFolder [[.Config.Backup.ToYandexDisk.Path]]")
 	}
 	// получаем адрес файла с бэкапом
 	fileName, err := getBackupFile()
 	if err != nil {
-		fmt.Printf("error getBackupFile %s err:%s", fileName, err)
+		logger.Errorf("error getBackupFile %s err:%s", fileName, err)
 		return
 	}
 	// копируем файл на сервер
 	err = uploadFile(fileName, "[[.Config.Backup.ToYandexDisk.Path]]/"+fileName)
 	if err != nil {
-		fmt.Printf("error uploadFile %s error:%s", fileName, err)
+		logger.Errorf("error uploadFile %s error:%s", fileName, err)
 		return
 	}
 
@@ -42,7 +42,7 @@ func createBackup()  {
 	// удаляем старые файлы на яндекс диске
 	err = removeOldBackupsOnServer()
 	if err != nil {
-		fmt.Printf("error removeBackupFile error:%s", err)
+		logger.Errorf("error removeBackupFile error:%s", err)
 	}
 
 }
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- правила рассылки изменений {{.Name}} (DocType.Realtime). Вызывается из notify_event
+-- возвращает {op, users, recipients_only, data} или NULL, если об операции не сообщаем
+-- параметры:
+-- op  - insert, update, delete
+-- r   - запись (при удалении - удаленная запись)
+-- old - прежняя версия записи при update, иначе NULL
+
+DROP FUNCTION IF EXISTS {{.PgName}}_realtime(op TEXT, r JSONB, old JSONB);
+CREATE OR REPLACE FUNCTION {{.PgName}}_realtime(op TEXT, r JSONB, old JSONB)
+  RETURNS JSONB
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  users BIGINT[] = ARRAY []::BIGINT[];
+
+BEGIN
+  {{.PrintSqlFuncRealtimeOps}}
+
+  {{.PrintSqlFuncRealtimeRecipients}}
+
+  RETURN jsonb_build_object(
+      'op', op,
+      'users', (SELECT coalesce(jsonb_agg(DISTINCT u), '[]') FROM unnest(users) u WHERE u NOTNULL),
+      'recipients_only', {{if .IsRealtimeRecipientsOnly}}TRUE{{else}}FALSE{{end}},
+      'data', {{.PrintSqlFuncRealtimeData}});
+END
+
+$function$;
@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tvitcom/nla_framework/types"
+	"github.com/tvitcom/nla_framework/utils"
+	"strconv"
+	"strings"
+)
+
+// WriteSqlMigrations сравнивает таблицы документов с сохраненным при прошлой генерации состоянием
+// и для изменившихся таблиц создает пару файлов миграции NNNN_<doc>.up.sql / NNNN_<doc>.down.sql.
+// После этого состояние перезаписывается текущим.
+// Для документа, который генерируется первый раз, миграция не создается - таблица создается pg_generate
+func WriteSqlMigrations(p types.ProjectType) {
+	path := p.DistPath + "/sql/migrations"
+	snapshotPath := path + "/snapshot"
+	err := utils.Fs.MkdirAll(snapshotPath)
+	utils.CheckErr(err, "WriteSqlMigrations MkdirAll")
+
+	num := lastSqlMigrationNum(path)
+	for _, d := range p.Docs {
+		snapshotFilename := fmt.Sprintf("%s/%s.json", snapshotPath, d.Name)
+		cur := d.SqlSnapshot()
+		if data, err := utils.Fs.ReadFile(snapshotFilename); err == nil {
+			prev := types.SqlModelSnapshot{}
+			err = json.Unmarshal(data, &prev)
+			utils.CheckErr(err, fmt.Sprintf("WriteSqlMigrations doc '%s' read snapshot", d.Name))
+			if m := d.SqlMigration(prev); !m.IsEmpty() {
+				num++
+				filename := fmt.Sprintf("%s/%04d_%s", path, num, d.PgName())
+				err = utils.Fs.WriteFile(filename+".up.sql", []byte(m.PrintUp()))
+				utils.CheckErr(err, "WriteSqlMigrations up")
+				err = utils.Fs.WriteFile(filename+".down.sql", []byte(m.PrintDown()))
+				utils.CheckErr(err, "WriteSqlMigrations down")
+			}
+		}
+		data, err := json.MarshalIndent(cur, "", "\t")
+		utils.CheckErr(err, "WriteSqlMigrations snapshot marshal")
+		err = utils.Fs.WriteFile(snapshotFilename, data)
+		utils.CheckErr(err, "WriteSqlMigrations snapshot write")
+	}
+}
+
+// номер последней миграции. Файлы миграций называются NNNN_<table>.up.sql
+func lastSqlMigrationNum(path string) int {
+	res := 0
+	names, err := utils.Fs.ReadDir(path)
+	if err != nil {
+		return res
+	}
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.Split(name, "_")[0]); err == nil && n > res {
+			res = n
+		}
+	}
+	return res
+}
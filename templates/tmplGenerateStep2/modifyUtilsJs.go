@@ -26,7 +26,7 @@ func PluginUtilsJs(p types.ProjectType)  {
 		},
 	}
 	path := fmt.Sprintf("%s/project/webClient/quasar_%v/app/plugins/utils.js", getPathDirTemplate(), p.GetQuasarVersion())
-	t, err := template.New("utils.js").Funcs(funcMap).Delims("[[", "]]").ParseFiles(path)
+	t, err := utils.ParseTemplateFiles(template.New("utils.js").Funcs(funcMap).Delims("[[", "]]"), path)
 	utils.CheckErr(err, "OverriteCopiedFiles ParseFiles")
 
 	err = executeToFile(t, "", distPath, "utils.js")
@@ -38,7 +38,7 @@ func BootI18nJs(p types.ProjectType)  {
 	distPath := fmt.Sprintf("%s/webClient/src/boot", p.DistPath)
 
 	path := fmt.Sprintf("%s/project/webClient/quasar_%v/boot/i18n.js", getPathDirTemplate(), p.GetQuasarVersion())
-	t, err := template.New("i18n.js").Delims("[[", "]]").ParseFiles(path)
+	t, err := utils.ParseTemplateFiles(template.New("i18n.js").Delims("[[", "]]"), path)
 	utils.CheckErr(err, "OverriteCopiedFiles ParseFiles")
 
 	err = executeToFile(t, "", distPath, "i18n.js")
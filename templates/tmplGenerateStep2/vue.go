@@ -5,9 +5,6 @@ import (
 	"fmt"
 	"github.com/tvitcom/nla_framework/types"
 	"github.com/tvitcom/nla_framework/utils"
-	"io/ioutil"
-	"log"
-	"os"
 	"strings"
 	"text/template"
 )
@@ -16,27 +13,27 @@ import (
 func TasksTmpl(p types.ProjectType)  {
 	distPath := fmt.Sprintf("%s/webClient/src/app/components/currentUser/tasks", p.DistPath)
 	// находим список файлов компонент в директории
-	files, err := ioutil.ReadDir(distPath + "/taskTemplates")
+	files, err := utils.Fs.ReadDir(distPath + "/taskTemplates")
 	utils.CheckErr(err, "TasksTmpl")
 
 	funcMap := template.FuncMap{
 		"PrintComps": func() string {
 			arr := []string{}
 			for _, f := range files {
-				arr = append(arr, strings.TrimSuffix(f.Name(), ".vue"))
+				arr = append(arr, strings.TrimSuffix(f, ".vue"))
 			}
 			return strings.Join(arr, ", ")
 		},
 		"PrintImports": func() (res string) {
 			//import defaultTmpl from './taskTemplates/default'
 			for _, f := range files {
-				res = res + fmt.Sprintf("\n\timport %[1]s from './taskTemplates/%[1]s'	", strings.TrimSuffix(f.Name(), ".vue"))
+				res = res + fmt.Sprintf("\n\timport %[1]s from './taskTemplates/%[1]s'	", strings.TrimSuffix(f, ".vue"))
 			}
 			return
 		},
 	}
 	path := strings.TrimPrefix(getPathDirTemplate(), "/templates") + "/webClient/quasar_1/webClient/src/app/components/currentUser/tasks/list.vue"
-	t, err := template.New("list.vue").Funcs(funcMap).Delims("[[", "]]").ParseFiles(path)
+	t, err := utils.ParseTemplateFiles(template.New("list.vue").Funcs(funcMap).Delims("[[", "]]"), path)
 	utils.CheckErr(err, "OverriteCopiedFiles ParseFiles")
 
 	err = executeToFile(t, "", distPath, "list.vue")
@@ -45,9 +42,9 @@ func TasksTmpl(p types.ProjectType)  {
 
 func executeToFile(t *template.Template, d interface{}, path, filename string) error {
 	if t == nil {
-		log.Fatalf("template is nil for path '%s/%s'\n", path, filename)
+		utils.Fatalf("template is nil for path '%s/%s'\n", path, filename)
 	}
-	err := os.MkdirAll(path, os.ModePerm)
+	err := utils.Fs.MkdirAll(path)
 	if err != nil {
 		return err
 	}
@@ -56,5 +53,5 @@ func executeToFile(t *template.Template, d interface{}, path, filename string) e
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path+"/"+filename, []byte(tpl.String()), 0644)
+	return utils.WriteGeneratedFile(path+"/"+filename, utils.TemplateSource(t), []byte(tpl.String()))
 }
\ No newline at end of file
@@ -0,0 +1,261 @@
+package cacheUtil
+
+import (
+	"container/list"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// количество шардов кэша. Каждый шард со своим мьютексом, чтобы параллельные запросы меньше ждали друг друга
+const cacheShardsCount = 16
+
+type (
+	// Cache - потокобезопасный кэш с ограничением количества записей (вытесняются давно не используемые),
+	// временем жизни записей и сбросом по тегам. Тег - название таблицы ("client") или таблица и id записи ("client:5")
+	Cache struct {
+		name          string
+		maxSize       int
+		shards        []*cacheShard
+		hits          uint64
+		misses        uint64
+		evictions     uint64
+		invalidations uint64
+	}
+
+	// CacheStats метрики кэша
+	CacheStats struct {
+		Name          string `json:"name"`
+		Size          int    `json:"size"`
+		MaxSize       int    `json:"max_size"`
+		Hits          uint64 `json:"hits"`
+		Misses        uint64 `json:"misses"`
+		Evictions     uint64 `json:"evictions"`     // вытеснено из-за ограничения размера
+		Invalidations uint64 `json:"invalidations"` // сброшено по тегу или вручную
+	}
+
+	cacheShard struct {
+		mu      sync.Mutex
+		maxSize int
+		items   map[string]*list.Element
+		lru     *list.List                     // в начале списка последние использованные записи
+		tags    map[string]map[string]struct{} // тег -> ключи записей
+	}
+
+	cacheEntry struct {
+		key         string
+		data        interface{}
+		expiredTime time.Time // нулевое значение - без ограничения времени
+		tags        []string
+	}
+)
+
+var (
+	cacheRegistry   = map[string]*Cache{}
+	cacheRegistryMu sync.RWMutex
+)
+
+// NewCache создание кэша на maxSize записей. Кэш регистрируется по названию, чтобы его можно было сбросить
+// по событию из postgres (InvalidateTable) и получить его метрики (AllCacheStats)
+func NewCache(name string, maxSize int) *Cache {
+	if maxSize <= 0 {
+		panic(fmt.Sprintf("cache '%s': maxSize must be positive", name))
+	}
+	shardsCount := cacheShardsCount
+	if maxSize < shardsCount {
+		shardsCount = 1
+	}
+	c := &Cache{name: name, maxSize: maxSize}
+	for i := 0; i < shardsCount; i++ {
+		c.shards = append(c.shards, &cacheShard{
+			maxSize: (maxSize + shardsCount - 1) / shardsCount,
+			items:   map[string]*list.Element{},
+			lru:     list.New(),
+			tags:    map[string]map[string]struct{}{},
+		})
+	}
+
+	cacheRegistryMu.Lock()
+	defer cacheRegistryMu.Unlock()
+	if _, ok := cacheRegistry[name]; ok {
+		panic(fmt.Sprintf("cache '%s' already exist", name))
+	}
+	cacheRegistry[name] = c
+	return c
+}
+
+// Get значение по ключу. Запись с истекшим временем удаляется
+func (c *Cache) Get(key string) (interface{}, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !e.expiredTime.IsZero() && !e.expiredTime.After(time.Now()) {
+		s.remove(el)
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	s.lru.MoveToFront(el)
+	atomic.AddUint64(&c.hits, 1)
+	return e.data, true
+}
+
+// Set сохранение значения на время ttl (0 - без ограничения). Запись сбрасывается при InvalidateTags по любому из tags
+func (c *Cache) Set(key string, data interface{}, ttl time.Duration, tags ...string) {
+	e := &cacheEntry{key: key, data: data, tags: tags}
+	if ttl > 0 {
+		e.expiredTime = time.Now().Add(ttl)
+	}
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+	s.items[key] = s.lru.PushFront(e)
+	for _, t := range tags {
+		if s.tags[t] == nil {
+			s.tags[t] = map[string]struct{}{}
+		}
+		s.tags[t][key] = struct{}{}
+	}
+	for s.lru.Len() > s.maxSize {
+		s.remove(s.lru.Back())
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// Delete удаление записи
+func (c *Cache) Delete(key string) bool {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.items[key]
+	if ok {
+		s.remove(el)
+		atomic.AddUint64(&c.invalidations, 1)
+	}
+	return ok
+}
+
+// InvalidateTags удаление всех записей, у которых есть хотя бы один из тегов. Возвращает количество удаленных записей
+func (c *Cache) InvalidateTags(tags ...string) int {
+	cnt := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for _, t := range tags {
+			for key := range s.tags[t] {
+				if el, ok := s.items[key]; ok {
+					s.remove(el)
+					cnt++
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+	atomic.AddUint64(&c.invalidations, uint64(cnt))
+	return cnt
+}
+
+// Purge удаление всех записей
+func (c *Cache) Purge() {
+	cnt := 0
+	for _, s := range c.shards {
+		s.mu.Lock()
+		cnt += s.lru.Len()
+		s.items = map[string]*list.Element{}
+		s.lru.Init()
+		s.tags = map[string]map[string]struct{}{}
+		s.mu.Unlock()
+	}
+	atomic.AddUint64(&c.invalidations, uint64(cnt))
+}
+
+// Stats текущие метрики кэша
+func (c *Cache) Stats() CacheStats {
+	res := CacheStats{
+		Name:          c.name,
+		MaxSize:       c.maxSize,
+		Hits:          atomic.LoadUint64(&c.hits),
+		Misses:        atomic.LoadUint64(&c.misses),
+		Evictions:     atomic.LoadUint64(&c.evictions),
+		Invalidations: atomic.LoadUint64(&c.invalidations),
+	}
+	for _, s := range c.shards {
+		s.mu.Lock()
+		res.Size += s.lru.Len()
+		s.mu.Unlock()
+	}
+	return res
+}
+
+func (c *Cache) shard(key string) *cacheShard {
+	if len(c.shards) == 1 {
+		return c.shards[0]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return c.shards[h.Sum32()%uint32(len(c.shards))]
+}
+
+// удаление записи из шарда. Вызывается под блокировкой шарда
+func (s *cacheShard) remove(el *list.Element) {
+	e := el.Value.(*cacheEntry)
+	s.lru.Remove(el)
+	delete(s.items, e.key)
+	for _, t := range e.tags {
+		if keys, ok := s.tags[t]; ok {
+			delete(keys, e.key)
+			if len(keys) == 0 {
+				delete(s.tags, t)
+			}
+		}
+	}
+}
+
+// TableTag тег записей кэша, которые зависят от конкретной записи таблицы
+func TableTag(table string, id int64) string {
+	return fmt.Sprintf("%s:%v", table, id)
+}
+
+// InvalidateTable сброс во всех кэшах записей, которые зависят от таблицы или от записи таблицы с указанным id.
+// Вызывается при получении события об изменении из postgres (pg.processPgEvent)
+func InvalidateTable(table string, id int64) int {
+	cnt := 0
+	for _, c := range registeredCaches() {
+		if id > 0 {
+			cnt += c.InvalidateTags(table, TableTag(table, id))
+		} else {
+			cnt += c.InvalidateTags(table)
+		}
+	}
+	return cnt
+}
+
+// AllCacheStats метрики всех созданных кэшей, отсортированные по названию
+func AllCacheStats() []CacheStats {
+	res := []CacheStats{}
+	for _, c := range registeredCaches() {
+		res = append(res, c.Stats())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func registeredCaches() []*Cache {
+	cacheRegistryMu.RLock()
+	defer cacheRegistryMu.RUnlock()
+	res := make([]*Cache, 0, len(cacheRegistry))
+	for _, c := range cacheRegistry {
+		res = append(res, c)
+	}
+	return res
+}
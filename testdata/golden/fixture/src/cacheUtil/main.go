@@ -0,0 +1,57 @@
+package cacheUtil
+
+import (
+	"time"
+	"github.com/bluele/gcache"
+	"fmt"
+)
+
+var (
+	// memCache кэш для произвольных данных приложения
+	memCache = NewCache("mem", 10000)
+	gc       = gcache.New(40).
+		LRU().
+		Build()
+)
+
+func MemCacheGet(key string) interface{} {
+	if res, ok := memCache.Get(key); ok {
+		return res
+	}
+	return nil
+}
+
+// MemCachePut сохранение данных на duration секунд. По tags запись сбрасывается через InvalidateTable (например "client" или TableTag("client", id))
+func MemCachePut(key string, duration int, data interface{}, tags ...string) {
+	memCache.Set(key, data, time.Duration(duration)*time.Second, tags...)
+}
+
+func MemCacheClear(key string) {
+	memCache.Delete(key)
+}
+
+func GoCacheSet(key, value interface{}, t time.Duration) {
+	gc.SetWithExpire(key, value, t)
+}
+
+func GoCacheGet(key interface{}) (interface{}, error) {
+	return gc.Get(key)
+}
+
+func GoCacheRemove(key interface{}) bool {
+	return gc.Remove(key)
+}
+
+// ключ для хранении в кэше данных о польззователе
+func GetCacheKeyUser(userId int64) string {
+	return fmt.Sprintf("user_id_%v", userId)
+}
+
+// ключ для хранении в кэше данных о пользователе по токену
+func GetCacheKeyUserToken(token string) string {
+	return fmt.Sprintf("token_%v", token)
+}
+
+func UserRemoveByToken(token string) bool {
+	return GoCacheRemove(GetCacheKeyUserToken(token))
+}
@@ -0,0 +1,49 @@
+package jobs
+
+import (
+	"context"
+	"fixture/src/lifecycle"
+	"fixture/src/logger"
+	"sync"
+)
+
+var (
+	wg sync.WaitGroup
+)
+
+func StartJobs()  {
+	
+	lifecycle.Register("jobs", Stop)
+}
+
+// Go запуск фоновой задачи. ctx отменяется при остановке приложения, задача должна завершиться по ctx.Done()
+func Go(f func(ctx context.Context)) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			// паника в задаче по-прежнему завершает приложение, но перед этим попадает в лог (в том числе в graylog)
+			if r := recover(); r != nil {
+				logger.Errorf("job panic: %v", r)
+				_ = logger.Close(context.Background())
+				panic(r)
+			}
+		}()
+		f(lifecycle.Context())
+	}()
+}
+
+// Stop ожидание завершения задач, запущенных через Go
+func Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
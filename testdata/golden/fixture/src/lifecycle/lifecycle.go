@@ -0,0 +1,90 @@
+package lifecycle
+
+import (
+	"context"
+	"fixture/src/logger"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Остановка приложения по SIGINT/SIGTERM. Подсистемы регистрируют функции остановки при запуске,
+// при завершении они вызываются в обратном порядке (как defer): веб-сервер останавливается раньше, чем postgres
+
+type (
+	// StopFunc остановка подсистемы. Должна завершиться до отмены ctx
+	StopFunc func(ctx context.Context) error
+
+	stopper struct {
+		name string
+		f    StopFunc
+	}
+)
+
+var (
+	mu       sync.Mutex
+	stoppers []stopper
+	stopping int32
+	// ctx отменяется в начале остановки приложения
+	ctx, cancel = context.WithCancel(context.Background())
+)
+
+// Register добавление функции остановки подсистемы
+func Register(name string, f StopFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	stoppers = append(stoppers, stopper{name, f})
+}
+
+// Context контекст приложения, отменяется при начале остановки. Используется в фоновых задачах
+func Context() context.Context {
+	return ctx
+}
+
+// IsStopping признак что приложение останавливается (для /readyz)
+func IsStopping() bool {
+	return atomic.LoadInt32(&stopping) == 1
+}
+
+// WaitForSignal ожидание SIGINT/SIGTERM и остановка всех подсистем. timeout - общее время на остановку
+func WaitForSignal(timeout time.Duration) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	s := <-sig
+	logger.Infof("received %s, shutting down", s)
+	// повторный сигнал - завершаем сразу
+	go func() {
+		<-sig
+		logger.Warnf("forced shutdown")
+		os.Exit(1)
+	}()
+	Shutdown(timeout)
+}
+
+// Shutdown остановка зарегистрированных подсистем в обратном порядке. Ошибка одной подсистемы не прерывает остановку остальных
+func Shutdown(timeout time.Duration) {
+	if !atomic.CompareAndSwapInt32(&stopping, 0, 1) {
+		return
+	}
+	cancel()
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), timeout)
+	defer shutdownCancel()
+	mu.Lock()
+	list := make([]stopper, len(stoppers))
+	copy(list, stoppers)
+	mu.Unlock()
+	for i := len(list) - 1; i >= 0; i-- {
+		start := time.Now()
+		if err := list[i].f(shutdownCtx); err != nil {
+			logger.Errorf("shutdown '%s' error: %s", list[i].name, err)
+			continue
+		}
+		logger.Infof("shutdown '%s' done in %s", list[i].name, time.Since(start))
+	}
+}
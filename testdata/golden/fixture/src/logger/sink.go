@@ -0,0 +1,173 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"fixture/src/types"
+	"gopkg.in/aphistic/golf.v0"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// поля записи, которые нельзя перезаписать через Fields
+var reservedFields = map[string]bool{"time": true, "level": true, "app": true, "msg": true}
+
+// writerSink вывод в stdout или файл. Одна запись - одна строка (JSON или текст)
+type writerSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	isJson bool
+}
+
+func newWriterSink(w io.Writer, closer io.Closer, isJson bool) *writerSink {
+	return &writerSink{w: w, closer: closer, isJson: isJson}
+}
+
+func newFileSink(path string, isJson bool) (*writerSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return newWriterSink(f, f, isJson), nil
+}
+
+func (s *writerSink) Write(r *Record) error {
+	var line []byte
+	if s.isJson {
+		line = formatJson(r)
+	} else {
+		line = formatText(r)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// formatJson {"time":..,"level":..,"app":..,"msg":..,<поля по алфавиту>}
+func formatJson(r *Record) []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"time":`)
+	writeJsonValue(buf, r.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	writeJsonValue(buf, r.Level.String())
+	if len(r.App) > 0 {
+		buf.WriteString(`,"app":`)
+		writeJsonValue(buf, r.App)
+	}
+	buf.WriteString(`,"msg":`)
+	writeJsonValue(buf, r.Msg)
+	for _, k := range sortedFieldNames(r.Fields) {
+		buf.WriteByte(',')
+		writeJsonValue(buf, fieldName(k))
+		buf.WriteByte(':')
+		writeJsonValue(buf, fieldValue(r.Fields[k]))
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// formatText 2006-01-02T15:04:05.000Z07:00 INFO msg key=value
+func formatText(r *Record) []byte {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "%s %-5s %s", r.Time.Format("2006-01-02T15:04:05.000Z07:00"), r.Level.String(), r.Msg)
+	for _, k := range sortedFieldNames(r.Fields) {
+		fmt.Fprintf(buf, " %s=%v", fieldName(k), fieldValue(r.Fields[k]))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func writeJsonValue(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprintf("%v", v))
+	}
+	buf.Write(b)
+}
+
+func sortedFieldNames(fields Fields) []string {
+	res := make([]string, 0, len(fields))
+	for k := range fields {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func fieldName(k string) string {
+	if reservedFields[k] {
+		return "field_" + k
+	}
+	return k
+}
+
+// ошибки сериализуются в json как {}, поэтому пишем текст
+func fieldValue(v interface{}) interface{} {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	return v
+}
+
+// gelfSink отправка в graylog по udp
+type gelfSink struct {
+	mu     sync.Mutex
+	client *golf.Client
+	l      *golf.Logger
+}
+
+func newGelfSink(app string, config types.GraylogConfig) (*gelfSink, error) {
+	client, err := golf.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	err = client.Dial(fmt.Sprintf("udp://%s:%v", config.Host, config.Port))
+	if err != nil {
+		return nil, err
+	}
+	l, err := client.NewLogger()
+	if err != nil {
+		return nil, err
+	}
+	l.SetAttr("app", app)
+	return &gelfSink{client: client, l: l}, nil
+}
+
+func (s *gelfSink) Write(r *Record) error {
+	attrs := map[string]interface{}{}
+	for k, v := range r.Fields {
+		attrs[fieldName(k)] = fieldValue(v)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Level {
+	case LevelDebug:
+		return s.l.Dbgm(attrs, "%s", r.Msg)
+	case LevelWarn:
+		return s.l.Warnm(attrs, "%s", r.Msg)
+	case LevelError:
+		return s.l.Errm(attrs, "%s", r.Msg)
+	}
+	return s.l.Infom(attrs, "%s", r.Msg)
+}
+
+func (s *gelfSink) Close() error {
+	return s.client.Close()
+}
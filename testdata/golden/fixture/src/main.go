@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/gob"
+	"flag"
+	"fixture/src/jobs"
+	"fixture/src/lifecycle"
+	"fixture/src/logger"
+	"fixture/src/pg"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"fixture/src/webServer"
+	"fixture/src/sse"
+	
+	
+	
+	"math/rand"
+	"os"
+	"time"
+)
+
+var (
+	config *types.Config
+	err    error
+)
+
+func main() {
+
+	// считываем флаг dev. Если режим разработки, то меняем глобальные переменные
+	isDev := flag.Bool("dev", false, "a bool")
+	pgPort := flag.String("pg_port", "", "an string")
+	pgPassword := flag.String("pg_pass", "", "an string")
+	dbName := flag.String("dbname", "", "an string")
+	
+	flag.Parse()
+
+	if *isDev {
+		_ = os.Setenv("PG_PORT", "5438")
+		if len(*pgPort) > 0 {
+			_ = os.Setenv("PG_PORT", *pgPort)
+		}
+		if len(*pgPassword) > 0 {
+			_ = os.Setenv("PG_PASSWORD", *pgPassword)
+		}
+		_ = os.Setenv("PG_HOST", "localhost")
+		if len(*dbName) > 0 {
+			_ = os.Setenv("PG_DBNAME", *dbName)
+		}
+		
+		_ = os.Setenv("IS_DEVELOPMENT", "true")
+	}
+
+	// read config.toml
+	config, err = types.ReadConfigFile("./config.toml")
+	utils.CheckErr(err, "Read config")
+
+	// лог приложения: stdout, файл и graylog по настройкам секций logger и graylog. Закрывается последним при остановке
+	err = logger.Init("fixture", config.Logger, config.Graylog)
+	utils.CheckErr(err, "Init logger")
+	lifecycle.Register("logger", logger.Close)
+
+	// postgres
+	err = pg.StartPostgres(config.Postgres)
+	utils.CheckErr(err, "StartPostgres")
+
+	// инициализируем генератор случайных чисел
+	rand.Seed(time.Now().UnixNano())
+	//
+	gob.Register(map[string]interface{}{})
+	//
+	jobs.StartJobs()
+
+	// передаем часть конфига в utils
+	utils.SetWebServerConfig(config.WebServer)
+	utils.SetEmailConfig(config.Email)
+	
+	
+
+	//go pg.GenerateFakeUsers(100)
+	
+
+	// инициализируем брокера для обработки подключений по SSE
+	sse.Init(config.Sse)
+
+	webServer.StartWebServer(*config)
+
+	// ожидаем SIGINT/SIGTERM и останавливаем подсистемы в обратном порядке запуска
+	lifecycle.WaitForSignal(time.Duration(config.WebServer.ShutdownTimeout) * time.Second)
+}
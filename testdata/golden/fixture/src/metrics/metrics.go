@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Метрики в текстовом формате prometheus (https://prometheus.io/docs/instrumenting/exposition_formats/).
+// Пока метрики не включены (SetEnabled), значения не накапливаются
+
+type (
+	// CounterVec счетчик с метками
+	CounterVec struct {
+		name   string
+		help   string
+		labels []string
+		mu     sync.Mutex
+		values map[string]*counterValue
+	}
+
+	counterValue struct {
+		labelValues []string
+		value       float64
+	}
+
+	// HistogramVec гистограмма с метками, например время выполнения запроса
+	HistogramVec struct {
+		name    string
+		help    string
+		labels  []string
+		buckets []float64
+		mu      sync.Mutex
+		values  map[string]*histogramValue
+	}
+
+	histogramValue struct {
+		labelValues []string
+		counts      []uint64 // количество значений <= buckets[i]
+		count       uint64
+		sum         float64
+	}
+
+	// Sample значение метрики, которая вычисляется при запросе /metrics (AddFunc)
+	Sample struct {
+		LabelValues []string
+		Value       float64
+	}
+
+	funcCollector struct {
+		name   string
+		help   string
+		kind   string
+		labels []string
+		f      func() []Sample
+	}
+
+	collector interface {
+		write(w io.Writer)
+		metricName() string
+	}
+)
+
+const (
+	KindCounter = "counter"
+	KindGauge   = "gauge"
+)
+
+var (
+	// DefaultBuckets границы гистограмм в секундах
+	DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+	enabled    int32
+	registryMu sync.Mutex
+	registry   = map[string]collector{}
+)
+
+// SetEnabled включение сбора метрик
+func SetEnabled(v bool) {
+	var i int32
+	if v {
+		i = 1
+	}
+	atomic.StoreInt32(&enabled, i)
+}
+
+func IsEnabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[c.metricName()]; ok {
+		panic(fmt.Sprintf("metrics: duplicate metric '%s'", c.metricName()))
+	}
+	registry[c.metricName()] = c
+}
+
+// NewCounterVec создание и регистрация счетчика. Название метрики должно быть уникальным
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: map[string]*counterValue{}}
+	register(c)
+	return c
+}
+
+// NewHistogramVec создание и регистрация гистограммы. Если buckets не указаны, то DefaultBuckets
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, values: map[string]*histogramValue{}}
+	register(h)
+	return h
+}
+
+// AddFunc регистрация метрики, значение которой вычисляется при каждом запросе /metrics
+// (размер пула соединений, количество клиентов и т.д.). kind - KindCounter или KindGauge
+func AddFunc(name, help, kind string, labels []string, f func() []Sample) {
+	register(&funcCollector{name: name, help: help, kind: kind, labels: labels, f: f})
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	if !IsEnabled() {
+		return
+	}
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labelValues: labelValues}
+		c.values[key] = cv
+	}
+	cv.value += v
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	if !IsEnabled() {
+		return
+	}
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hv
+	}
+	for i, b := range h.buckets {
+		if v <= b {
+			hv.counts[i]++
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+// WritePrometheus запись всех метрик, отсортированных по названию
+func WritePrometheus(w io.Writer) {
+	registryMu.Lock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]collector, 0, len(names))
+	for _, name := range names {
+		list = append(list, registry[name])
+	}
+	registryMu.Unlock()
+	for _, c := range list {
+		c.write(w)
+	}
+}
+
+func (c *CounterVec) metricName() string   { return c.name }
+func (h *HistogramVec) metricName() string { return h.name }
+func (f *funcCollector) metricName() string { return f.name }
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeHeader(w, c.name, c.help, KindCounter)
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cv := c.values[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, cv.labelValues), formatValue(cv.value))
+	}
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		hv := h.values[key]
+		labels := append(append([]string{}, h.labels...), "le")
+		for i, b := range h.buckets {
+			values := append(append([]string{}, hv.labelValues...), formatValue(b))
+			fmt.Fprintf(w, "%s_bucket%s %v\n", h.name, formatLabels(labels, values), hv.counts[i])
+		}
+		values := append(append([]string{}, hv.labelValues...), "+Inf")
+		fmt.Fprintf(w, "%s_bucket%s %v\n", h.name, formatLabels(labels, values), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, hv.labelValues), formatValue(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %v\n", h.name, formatLabels(h.labels, hv.labelValues), hv.count)
+	}
+}
+
+func (f *funcCollector) write(w io.Writer) {
+	writeHeader(w, f.name, f.help, f.kind)
+	for _, s := range f.f() {
+		fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(f.labels, s.LabelValues), formatValue(s.Value))
+	}
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, strings.NewReplacer("\\", `\\`, "\n", `\n`).Replace(help), name, kind)
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	arr := make([]string, 0, len(names))
+	for i, name := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		arr = append(arr, fmt.Sprintf(`%s="%s"`, name, strings.NewReplacer("\\", `\\`, "\n", `\n`, `"`, `\"`).Replace(v)))
+	}
+	return "{" + strings.Join(arr, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
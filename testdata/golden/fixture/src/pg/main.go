@@ -0,0 +1,44 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"fixture/src/lifecycle"
+	"fixture/src/types"
+	"github.com/tvitcom/pg_generate"
+)
+
+var Pg *sql.DB
+
+func StartPostgres(config types.Postgres) error {
+	var err error
+	// создаем подключение к базе
+	dbinfo := fmt.Sprintf("postgres://%s:%s@%s:%v/%s?sslmode=disable", config.User, config.Password, config.Host, config.Port, config.DbName)
+	Pg, err = sql.Open("postgres", dbinfo)
+	if err != nil {
+		return err
+	}
+	// миграции применяются до pg_generate, чтобы переименования колонок прошли раньше, чем модель будет применена к базе.
+	// Если базы еще нет, то мигрировать нечего - ее создаст pg_generate
+	if Pg.Ping() == nil {
+		err = applyMigrations(Pg)
+		if err != nil {
+			return err
+		}
+	}
+	// создаем базу
+	pgGenerate.Start(false)
+	err = Pg.Ping()
+	if err != nil {
+		return err
+	}
+	// при остановке приложения соединения закрываются после остановки всех подсистем, которые используют базу
+	lifecycle.Register("postgres", func(ctx context.Context) error {
+		return Pg.Close()
+	})
+	// подписываемся на канал обновлений
+	go pgListen(config)
+	lifecycle.Register("pg listener", StopListener)
+	return nil
+}
@@ -0,0 +1,112 @@
+package pg
+
+import (
+	"github.com/tidwall/gjson"
+	"fixture/src/logger"
+	"fixture/src/metrics"
+	"time"
+)
+
+// Большие события notify_event (больше 8000 байт, см. triger_notify_event.sql) сохраняются в таблицу event_outbox,
+// а в NOTIFY приходит только {table, id, op, outbox_id}. Слушатель читает событие из таблицы и после обработки
+// отмечает его processed_at. Необработанные события (слушатель был остановлен или переподключался) обрабатываются
+// при подключении слушателя, поэтому такие события доставляются не менее одного раза
+
+const (
+	outboxBatchSize = 100
+	// сколько хранятся обработанные события
+	outboxRetention     = 7 * 24 * time.Hour
+	outboxCleanupPeriod = time.Hour
+)
+
+type outboxEvent struct {
+	id      int64
+	payload string
+}
+
+// outboxEventId id события в event_outbox. 0 - событие пришло в NOTIFY целиком
+func outboxEventId(event string) int64 {
+	return gjson.Get(event, "outbox_id").Int()
+}
+
+// outboxFetch событие целиком из event_outbox
+func outboxFetch(id int64) (string, error) {
+	var payload string
+	err := Pg.QueryRow("select payload from event_outbox where id = $1", id).Scan(&payload)
+	return payload, err
+}
+
+// outboxAck отметка, что событие обработано
+func outboxAck(id int64) {
+	_, err := Pg.Exec("update event_outbox set processed_at = now() where id = $1 and processed_at isnull", id)
+	if err != nil {
+		logger.Errorf("pg event outbox %d ack error: %s", id, err)
+	}
+}
+
+// outboxPending необработанные события по порядку, начиная с afterId
+func outboxPending(afterId int64) ([]outboxEvent, error) {
+	rows, err := Pg.Query("select id, payload from event_outbox where processed_at isnull and id > $1 order by id limit $2", afterId, outboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []outboxEvent{}
+	for rows.Next() {
+		var ev outboxEvent
+		if err := rows.Scan(&ev.id, &ev.payload); err != nil {
+			return nil, err
+		}
+		res = append(res, ev)
+	}
+	return res, rows.Err()
+}
+
+// processOutboxPending обработка событий, которые остались необработанными, например, после перезапуска приложения
+func processOutboxPending() {
+	var lastId int64
+	for {
+		arr, err := outboxPending(lastId)
+		if err != nil {
+			logger.Errorf("pg event outbox read error: %s", err)
+			return
+		}
+		for _, ev := range arr {
+			handlePgEvent(ev.payload)
+			outboxAck(ev.id)
+			lastId = ev.id
+		}
+		if len(arr) < outboxBatchSize {
+			return
+		}
+	}
+}
+
+// processOutboxEvent обработка события, которое пришло в NOTIFY ссылкой на event_outbox
+func processOutboxEvent(id int64) {
+	payload, err := outboxFetch(id)
+	if err != nil {
+		logger.Errorf("pg event outbox %d read error: %s", id, err)
+		return
+	}
+	metrics.PgOutboxEvents.Inc()
+	handlePgEvent(payload)
+	outboxAck(id)
+}
+
+// outboxCleanup удаление старых обработанных событий. Работает до остановки слушателя
+func outboxCleanup() {
+	ticker := time.NewTicker(outboxCleanupPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-listenerStop:
+			return
+		case <-ticker.C:
+			_, err := Pg.Exec("delete from event_outbox where processed_at < $1", time.Now().Add(-outboxRetention))
+			if err != nil {
+				logger.Errorf("pg event outbox cleanup error: %s", err)
+			}
+		}
+	}
+}
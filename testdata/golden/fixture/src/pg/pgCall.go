@@ -0,0 +1,152 @@
+package pg
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DefaultCallTimeout таймаут вызова postgres функции, если для метода не указан свой
+var DefaultCallTimeout = 30 * time.Second
+
+// название функции: имя или schema.имя из латиницы, цифр и '_'
+var pgFuncNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+var (
+	ErrInvalidFuncName   = errors.New("invalid pg function name")
+	ErrFuncNotRegistered = errors.New("pg function is not registered")
+	registeredFuncs      = map[string]bool{}
+	registeredFuncsMu    sync.RWMutex
+)
+
+type (
+	// PgFuncError - функция выполнилась, но вернула {ok: false, message: ...}
+	PgFuncError struct {
+		FuncName string
+		Message  string
+	}
+
+	// PgCallError - ошибка драйвера или соединения с базой
+	PgCallError struct {
+		FuncName string
+		Err      error
+	}
+
+	// PgTimeoutError - вызов прерван по таймауту или отменен (например клиент закрыл соединение)
+	PgTimeoutError struct {
+		FuncName string
+		Err      error // context.DeadlineExceeded или context.Canceled
+	}
+)
+
+func (e *PgFuncError) Error() string {
+	return e.Message
+}
+
+func (e *PgCallError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PgCallError) Unwrap() error {
+	return e.Err
+}
+
+func (e *PgTimeoutError) Error() string {
+	return fmt.Sprintf("pg function '%s': %s", e.FuncName, e.Err)
+}
+
+func (e *PgTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// ValidateFuncName проверка, что название можно подставить в запрос как имя функции.
+// Проверяется только синтаксис: разрешен ли вызов функции, проверяет CheckRegisteredFuncName
+func ValidateFuncName(funcName string) error {
+	if !pgFuncNameRe.MatchString(funcName) {
+		return fmt.Errorf("%w: '%s'", ErrInvalidFuncName, funcName)
+	}
+	return nil
+}
+
+// RegisterFuncs регистрация функций, которые можно вызывать по названию из запроса клиента (pgFuncList в webServer)
+func RegisterFuncs(funcNames ...string) {
+	registeredFuncsMu.Lock()
+	defer registeredFuncsMu.Unlock()
+	for _, name := range funcNames {
+		registeredFuncs[name] = true
+	}
+}
+
+// CheckRegisteredFuncName проверка названия и того, что функция зарегистрирована через RegisterFuncs
+func CheckRegisteredFuncName(funcName string) error {
+	if err := ValidateFuncName(funcName); err != nil {
+		return err
+	}
+	registeredFuncsMu.RLock()
+	defer registeredFuncsMu.RUnlock()
+	if !registeredFuncs[funcName] {
+		return fmt.Errorf("%w: '%s'", ErrFuncNotRegistered, funcName)
+	}
+	return nil
+}
+
+// CallRegisteredPgFuncContext вызов функции, название которой пришло из запроса клиента. Вызываются только функции,
+// зарегистрированные через RegisterFuncs
+func CallRegisteredPgFuncContext(ctx context.Context, funcName string, jsonStr []byte) ([]byte, error) {
+	if err := CheckRegisteredFuncName(funcName); err != nil {
+		return nil, err
+	}
+	return CallPgFuncRawContext(ctx, funcName, jsonStr)
+}
+
+// CallPgFuncRawContext вызов postgres функции с параметрами в виде json. Параметры передаются в запрос как $1::jsonb.
+// Возвращает ответ функции как есть, без разбора ok/result. Регистрация функции не проверяется, поэтому название
+// должно задаваться в коде. Название из запроса клиента передается через CallRegisteredPgFuncContext
+func CallPgFuncRawContext(ctx context.Context, funcName string, jsonStr []byte) (queryRes []byte, err error) {
+	if err = ValidateFuncName(funcName); err != nil {
+		return
+	}
+	if _, ok := ctx.Deadline(); !ok && DefaultCallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultCallTimeout)
+		defer cancel()
+	}
+	var row *sql.Row
+	if len(jsonStr) > 0 {
+		row = Pg.QueryRowContext(ctx, fmt.Sprintf("select * from %s($1::jsonb)", funcName), string(jsonStr))
+	} else {
+		row = Pg.QueryRowContext(ctx, fmt.Sprintf("select * from %s()", funcName))
+	}
+	if err = row.Scan(&queryRes); err != nil {
+		return nil, classifyCallErr(ctx, funcName, err)
+	}
+	return
+}
+
+// CallPgFuncContext вызов postgres функции с разбором ответа. Если функция вернула ok: false, то возвращается *PgFuncError
+func CallPgFuncContext(ctx context.Context, funcName string, jsonStr []byte, res interface{}, metaInfo interface{}) error {
+	queryRes, err := CallPgFuncRawContext(ctx, funcName, jsonStr)
+	if err != nil {
+		return err
+	}
+	err = ParseResponseFromPostgresFunc(queryRes, res, metaInfo)
+	if fErr, ok := err.(*PgFuncError); ok {
+		fErr.FuncName = funcName
+	}
+	return err
+}
+
+// ошибки, возникшие из-за контекста, отделяем от ошибок драйвера
+func classifyCallErr(ctx context.Context, funcName string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return &PgTimeoutError{FuncName: funcName, Err: ctxErr}
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return &PgTimeoutError{FuncName: funcName, Err: err}
+	}
+	return &PgCallError{FuncName: funcName, Err: err}
+}
@@ -0,0 +1,60 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"fixture/src/logger"
+	"encoding/json"
+)
+
+func CallPgSelectToJson(queryStr string, res interface{}) (err error) {
+	var queryRes []byte
+
+	err = Pg.QueryRow(queryStr).Scan(&queryRes)
+	if err != nil {
+		logger.Errorf("queryRes err %s", err)
+		return
+	}
+
+	err = json.Unmarshal(queryRes, &res)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func CallPgFuncWithStruct(funcName string, jsonStruct, res interface{}) error  {
+	jsonStr, err := json.Marshal(jsonStruct)
+	if err != nil {
+		return err
+	}
+	return CallPgFunc(funcName, jsonStr, res, nil)
+}
+
+// CallPgFunc вызов postgres функции без контекста запроса, с таймаутом DefaultCallTimeout
+func CallPgFunc(funcName string, jsonStr []byte, res interface{}, metaInfo interface{}) (err error) {
+	return CallPgFuncContext(context.Background(), funcName, jsonStr, res, metaInfo)
+}
+
+func ParseResponseFromPostgresFunc(queryRes []byte, tempRes interface{}, metaInfo interface{}) (err error) {
+	ok := gjson.Get(fmt.Sprintf("%s", queryRes), "ok").Bool()
+	if !ok {
+		errMsg := gjson.Get(fmt.Sprintf("%s", queryRes), "message").Str
+		err = &PgFuncError{Message: errMsg}
+		return
+	}
+
+	err = json.Unmarshal([]byte(gjson.Get(fmt.Sprintf("%s", queryRes), "result").Raw), &tempRes)
+	if err != nil {
+		return err
+	}
+	if metaInfo != nil {
+		err = json.Unmarshal([]byte(gjson.Get(fmt.Sprintf("%s", queryRes), "meta_info").Raw), &metaInfo)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package pgClient
+
+// файл генерируется автоматически по описанию документов
+
+// Client - клиент
+type Client struct {
+	Id int64 `json:"id"`
+	Title string `json:"title"` // название
+	Inn string `json:"inn"` // ИНН
+	ManagerID int `json:"manager_id"` // менеджер
+	ManagerTitle string `json:"manager_title,omitempty"`
+	Amount float64 `json:"amount"` // сумма
+	Options map[string]interface{} `json:"options,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	Deleted bool `json:"deleted"`
+}
+
+// ClientGet запись по id
+func ClientGet(userId, id int64) (*Client, error) {
+	res := &Client{}
+	err := call("client_get_by_id", map[string]interface{}{"id": id, "user_id": userId}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ClientList список записей по фильтру
+func ClientList(userId int64, filter ListFilter) ([]Client, error) {
+	res := []Client{}
+	err := call("client_list", filter.params(userId), &res)
+	return res, err
+}
+
+// ClientUpdate создание (если Id = 0) или обновление записи. Возвращает сохраненную запись
+func ClientUpdate(userId int64, doc Client) (*Client, error) {
+	params, err := updateParams(userId, doc.Id, doc)
+	if err != nil {
+		return nil, err
+	}
+	res := &Client{}
+	err = call("client_update", params, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ClientDelete пометка записи как удаленной
+func ClientDelete(userId, id int64) error {
+	return call("client_update", map[string]interface{}{"id": id, "deleted": true, "user_id": userId}, nil)
+}
+
@@ -0,0 +1 @@
+[[define "tableExt"]][[end]]
\ No newline at end of file
@@ -0,0 +1,5 @@
+[[define "createSchemaTmpl"]]
+
+CREATE SCHEMA IF NOT EXISTS [[.]];
+
+[[ end ]]
@@ -0,0 +1,83 @@
+-- создание клиент
+
+DROP FUNCTION IF EXISTS client_update(params JSONB);
+CREATE OR REPLACE FUNCTION client_update(params JSONB)
+    RETURNS JSON
+    LANGUAGE plpgsql
+AS
+$function$
+
+DECLARE
+    clientRow     client%ROWTYPE;
+    checkMsg    TEXT;
+    result      JSONB;
+    updateValue TEXT;
+    queryStr    TEXT;
+    
+BEGIN
+
+    
+    -- проверика наличия id
+    checkMsg = check_required_params(params, ARRAY ['id']);
+    IF checkMsg IS NOT NULL
+    THEN
+        RETURN checkMsg;
+    END IF;
+	
+
+    
+    
+    
+    
+    
+
+    if (params ->> 'id')::int = -1 then
+        -- проверика наличия обязательных параметров
+        checkMsg = check_required_params(params, ARRAY ['title']);
+        IF checkMsg IS NOT NULL
+        THEN
+            RETURN checkMsg;
+        END IF;
+        
+
+        EXECUTE ('INSERT INTO client (title, inn, manager_id, amount, options) VALUES ($1, $2, $3, $4, $5)  RETURNING *;')
+		INTO clientRow
+		USING
+			(params ->> 'title')::text,
+			(params ->> 'inn')::text,
+			(params ->> 'manager_id')::int,
+			(params ->> 'amount')::double precision,
+			coalesce(params -> 'options', '{}')::jsonb;
+
+        
+
+    else
+        updateValue = '' || update_str_from_json(params, ARRAY [
+			['title', 'title', 'text'],
+			['inn', 'inn', 'text'],
+			['manager_id', 'manager_id', 'number'],
+			['amount', 'amount', 'number'],
+            ['options', 'options', 'jsonb'],
+            ['deleted', 'deleted', 'bool']
+            ]);
+
+        queryStr = concat('UPDATE client SET ', updateValue, ' WHERE id=', params ->> 'id', ' RETURNING *;');
+
+        EXECUTE (queryStr)
+            INTO clientRow;
+
+        -- случай когда записи с таким id не найдено
+        IF row_to_json(clientRow) ->> 'id' ISNULL
+        THEN
+            RETURN json_build_object('ok', FALSE, 'message', 'wrong id');
+        END IF;
+
+    end if;
+
+    
+
+    RETURN client_get_by_id(jsonb_build_object('id', clientRow.id));
+
+END
+
+$function$;
\ No newline at end of file
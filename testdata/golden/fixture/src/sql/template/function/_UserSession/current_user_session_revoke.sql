@@ -0,0 +1,36 @@
+-- выход: отзыв своей сессии или всех своих сессий
+-- параметры:
+-- user_id   type: int - текущий пользователь (добавляется в webServer через BeforeHookAddUserId)
+-- id        type: int - сессия. Если не указана, то отзываются все сессии пользователя
+-- except_id type: int - сессия, которую не нужно отзывать (например, текущая)
+
+DROP FUNCTION IF EXISTS current_user_session_revoke(params JSONB );
+CREATE OR REPLACE FUNCTION current_user_session_revoke(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  checkMsg  TEXT;
+  cnt       INT;
+
+BEGIN
+
+  checkMsg = check_required_params_with_func_name('current_user_session_revoke', params, ARRAY ['user_id']);
+  IF checkMsg IS NOT NULL
+  THEN
+    RETURN checkMsg;
+  END IF;
+
+  UPDATE user_session SET revoked_at = now(), revoked_by = (params ->> 'user_id') :: INT
+  WHERE user_id = (params ->> 'user_id') :: INT AND revoked_at ISNULL
+    AND ((params ->> 'id') IS NULL OR id = (params ->> 'id') :: INT)
+    AND ((params ->> 'except_id') IS NULL OR id <> (params ->> 'except_id') :: INT);
+
+  GET DIAGNOSTICS cnt = ROW_COUNT;
+
+  RETURN json_build_object('ok', TRUE, 'result', json_build_object('count', cnt));
+
+END
+
+$function$;
@@ -0,0 +1,43 @@
+-- создание сессии при входе пользователя. Токены генерируются в webServer/auth/session.go, в базе хранятся только хэши
+-- параметры:
+-- user_id              type: int
+-- access_token_hash    type: string
+-- refresh_token_hash   type: string
+-- access_expired_at    type: timestamp
+-- refresh_expired_at   type: timestamp
+-- device               type: string
+-- user_agent           type: string
+-- ip                   type: string
+
+DROP FUNCTION IF EXISTS user_session_create(params JSONB );
+CREATE OR REPLACE FUNCTION user_session_create(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  checkMsg    TEXT;
+  sessionRow  user_session%ROWTYPE;
+
+BEGIN
+
+  checkMsg = check_required_params_with_func_name('user_session_create', params, ARRAY ['user_id', 'access_token_hash', 'refresh_token_hash', 'access_expired_at', 'refresh_expired_at']);
+  IF checkMsg IS NOT NULL
+  THEN
+    RETURN checkMsg;
+  END IF;
+
+  -- сессии с истекшим refresh токеном больше не нужны
+  DELETE FROM user_session WHERE user_id = (params ->> 'user_id') :: INT AND refresh_expired_at < now() - INTERVAL '30 days';
+
+  INSERT INTO user_session (user_id, access_token_hash, refresh_token_hash, access_expired_at, refresh_expired_at, device, user_agent, ip, last_seen_at, created_at)
+  VALUES ((params ->> 'user_id') :: INT, params ->> 'access_token_hash', params ->> 'refresh_token_hash',
+          (params ->> 'access_expired_at') :: TIMESTAMPTZ, (params ->> 'refresh_expired_at') :: TIMESTAMPTZ,
+          left(params ->> 'device', 200), left(params ->> 'user_agent', 500), left(params ->> 'ip', 50), now(), now())
+  RETURNING * INTO sessionRow;
+
+  RETURN json_build_object('ok', TRUE, 'result', json_build_object('id', sessionRow.id));
+
+END
+
+$function$;
@@ -0,0 +1,55 @@
+-- поиск пользователя по access токену сессии
+-- параметры:
+-- access_token_hash  type: string
+
+DROP FUNCTION IF EXISTS user_session_get_by_access_token(params JSONB );
+CREATE OR REPLACE FUNCTION user_session_get_by_access_token(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  checkMsg    TEXT;
+  sessionRow  user_session%ROWTYPE;
+  userRow     "user"%ROWTYPE;
+  result      JSONB;
+
+BEGIN
+
+  checkMsg = check_required_params_with_func_name('user_session_get_by_access_token', params, ARRAY ['access_token_hash']);
+  IF checkMsg IS NOT NULL
+  THEN
+    RETURN checkMsg;
+  END IF;
+
+  SELECT * INTO sessionRow FROM user_session WHERE access_token_hash = params ->> 'access_token_hash';
+
+  IF sessionRow.id ISNULL OR sessionRow.revoked_at NOTNULL
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'invalid token');
+  END IF;
+  IF sessionRow.access_expired_at < now()
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'access token expired');
+  END IF;
+
+  SELECT * INTO userRow FROM "user" WHERE id = sessionRow.user_id;
+  IF userRow.id ISNULL OR userRow.deleted
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'invalid token');
+  END IF;
+
+  -- время последней активности обновляем не чаще раза в 5 минут: каждое обновление сбрасывает кэш сессии через notify_event
+  IF sessionRow.last_seen_at ISNULL OR sessionRow.last_seen_at < now() - INTERVAL '5 minutes'
+  THEN
+    UPDATE user_session SET last_seen_at = now() WHERE id = sessionRow.id;
+  END IF;
+
+  result = row_to_json(userRow) :: JSONB - 'password';
+  result = result || jsonb_build_object('session_id', sessionRow.id, 'access_expired_at', sessionRow.access_expired_at);
+
+  RETURN json_build_object('ok', TRUE, 'result', result);
+
+END
+
+$function$;
@@ -0,0 +1,78 @@
+-- обновление токенов сессии по refresh токену. Refresh токен одноразовый: при каждом обновлении выдается новый.
+-- Повторное использование предыдущего refresh токена означает, что он попал к кому-то еще - сессия отзывается
+-- параметры:
+-- refresh_token_hash      type: string - хэш текущего refresh токена
+-- new_access_token_hash   type: string
+-- new_refresh_token_hash  type: string
+-- access_expired_at       type: timestamp
+-- refresh_expired_at      type: timestamp
+-- user_agent              type: string
+-- ip                      type: string
+
+DROP FUNCTION IF EXISTS user_session_refresh(params JSONB );
+CREATE OR REPLACE FUNCTION user_session_refresh(params JSONB)
+  RETURNS JSON
+LANGUAGE plpgsql
+AS $function$
+
+DECLARE
+  checkMsg    TEXT;
+  sessionRow  user_session%ROWTYPE;
+  userRow     "user"%ROWTYPE;
+  result      JSONB;
+
+BEGIN
+
+  checkMsg = check_required_params_with_func_name('user_session_refresh', params, ARRAY ['refresh_token_hash', 'new_access_token_hash', 'new_refresh_token_hash', 'access_expired_at', 'refresh_expired_at']);
+  IF checkMsg IS NOT NULL
+  THEN
+    RETURN checkMsg;
+  END IF;
+
+  SELECT * INTO sessionRow FROM user_session WHERE refresh_token_hash = params ->> 'refresh_token_hash' FOR UPDATE;
+
+  IF sessionRow.id ISNULL
+  THEN
+    UPDATE user_session SET revoked_at = now()
+    WHERE prev_refresh_token_hash = params ->> 'refresh_token_hash' AND revoked_at ISNULL
+    RETURNING * INTO sessionRow;
+    IF sessionRow.id NOTNULL
+    THEN
+      RETURN json_build_object('ok', FALSE, 'message', 'refresh token reused, session revoked');
+    END IF;
+    RETURN json_build_object('ok', FALSE, 'message', 'invalid refresh token');
+  END IF;
+  IF sessionRow.revoked_at NOTNULL
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'session revoked');
+  END IF;
+  IF sessionRow.refresh_expired_at < now()
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'refresh token expired');
+  END IF;
+
+  SELECT * INTO userRow FROM "user" WHERE id = sessionRow.user_id;
+  IF userRow.id ISNULL OR userRow.deleted
+  THEN
+    RETURN json_build_object('ok', FALSE, 'message', 'invalid refresh token');
+  END IF;
+
+  UPDATE user_session
+  SET access_token_hash       = params ->> 'new_access_token_hash',
+      refresh_token_hash      = params ->> 'new_refresh_token_hash',
+      prev_refresh_token_hash = sessionRow.refresh_token_hash,
+      access_expired_at       = (params ->> 'access_expired_at') :: TIMESTAMPTZ,
+      refresh_expired_at      = (params ->> 'refresh_expired_at') :: TIMESTAMPTZ,
+      user_agent              = coalesce(left(params ->> 'user_agent', 500), user_agent),
+      ip                      = coalesce(left(params ->> 'ip', 50), ip),
+      last_seen_at            = now()
+  WHERE id = sessionRow.id;
+
+  result = row_to_json(userRow) :: JSONB - 'password';
+  result = result || jsonb_build_object('session_id', sessionRow.id);
+
+  RETURN json_build_object('ok', TRUE, 'result', result);
+
+END
+
+$function$;
@@ -0,0 +1,100 @@
+-- функция создания сообщения об изменении
+
+CREATE OR REPLACE FUNCTION notify_event()
+  RETURNS TRIGGER AS $$
+
+DECLARE
+  hString HSTORE;
+  result  JSONB;
+  r       RECORD;
+  authToken text;
+  userFullname text;
+  userOptions jsonb;
+  taskExecutorFullname text;
+  taskManagerFullname text;
+  taskTypeOptions jsonb;
+  realtime jsonb;
+  oldJson jsonb;
+  payload text;
+  outboxId int;
+BEGIN
+
+  IF (TG_OP = 'DELETE')
+  THEN
+    r = OLD;
+    -- в случае удаления отправляем всю запись
+    hString = (hstore(OLD) || 'delete=>true' :: HSTORE) - ARRAY ['id', 'updated_at', 'created_at'];
+  ELSIF (TG_OP = 'INSERT')
+    THEN
+      r = NEW;
+      hString = hstore(NEW) - ARRAY ['id', 'updated_at', 'created_at', 'password', 'access_token_hash', 'refresh_token_hash', 'prev_refresh_token_hash'];
+  ELSIF (TG_OP = 'UPDATE')
+    THEN
+      r = NEW;
+      -- считаем дельту между старой и новой версией
+      -- из полученной дельты убираем поле updated_at
+      -- хэши токенов сессий (user_session) в событие не попадают
+      hString = hstore(NEW) - hstore(OLD) - ARRAY ['updated_at', 'password', 'access_token_hash', 'refresh_token_hash', 'prev_refresh_token_hash'];
+
+  END IF;
+
+  result = jsonb_build_object('table', TG_TABLE_NAME, 'id', r.id, 'flds',
+                              hstore_to_json_loose(hString));
+
+  -- в случае изменения user добавляем поля auth_token
+  IF TG_TABLE_NAME = 'user'
+  THEN
+      select auth_token into authToken from user_auth where user_id = r.id;
+      result = result || jsonb_build_object('auth_token', authToken);
+  END IF;
+
+  -- в случае изменения message добавляем поля id и TG_OP
+  IF TG_TABLE_NAME = 'message'
+  THEN
+      select fullname, options into userFullname, userOptions from "user" where id = r.user_id;
+      result = jsonb_set(result, '{flds}', result->'flds' || jsonb_build_object('id', r.id, 'tg_op', TG_OP, 'sse_type', 'message', 'user_fullname', userFullname, 'user_options', userOptions));
+  END IF;
+
+  -- в случае изменения task добавляем fullname по исполнителю и менеджеру
+  IF TG_TABLE_NAME = 'task'
+  THEN
+      select fullname into taskExecutorFullname from "user" where id = r.executor_id;
+      select fullname into taskManagerFullname from "user" where id = r.manager_id;
+      select options into taskTypeOptions from task_type where id = r.task_type_id;
+      result = jsonb_set(result, '{flds}', result->'flds' || row_to_json(r)::jsonb || jsonb_build_object('id', r.id, 'tg_op', TG_OP, 'sse_type', 'task', 'executor_fullname', taskExecutorFullname, 'manager_fullname', taskManagerFullname, 'task_type_options', taskTypeOptions));
+  END IF;
+
+  -- правила рассылки изменений документа (DocType.Realtime): функция <таблица>_realtime определяет получателей и данные события
+  IF to_regproc(TG_TABLE_NAME || '_realtime') NOTNULL
+  THEN
+      IF TG_OP = 'UPDATE'
+      THEN
+          oldJson = to_jsonb(OLD);
+      END IF;
+      EXECUTE format('SELECT %I($1, $2, $3)', TG_TABLE_NAME || '_realtime') INTO realtime USING lower(TG_OP), to_jsonb(r), oldJson;
+      IF realtime NOTNULL
+      THEN
+          result = result || jsonb_build_object('realtime', realtime);
+      END IF;
+  END IF;
+
+  IF char_length(hString :: TEXT) > 0 -- отправляем notification только если есть изменения
+  THEN
+    payload = result :: TEXT;
+    -- размер сообщения NOTIFY в postgres меньше 8000 байт, иначе ошибка и откат транзакции пользователя.
+    -- Большое событие (длинные text/jsonb поля) сохраняем в event_outbox, а в NOTIFY отправляем только ссылку на него.
+    -- Слушатель читает событие из event_outbox и отмечает обработанным (pg.processOutboxEvent)
+    IF octet_length(payload) >= 8000
+    THEN
+      INSERT INTO event_outbox (table_name, table_id, op, payload) VALUES (TG_TABLE_NAME, r.id, lower(TG_OP), result)
+      RETURNING id INTO outboxId;
+      payload = jsonb_build_object('table', TG_TABLE_NAME, 'id', r.id, 'op', lower(TG_OP), 'outbox_id', outboxId) :: TEXT;
+    END IF;
+    PERFORM pg_notify('events', payload);
+  END IF;
+
+  -- Result is ignored since this is an AFTER trigger
+  RETURN NULL;
+END;
+
+$$ LANGUAGE plpgsql;
\ No newline at end of file
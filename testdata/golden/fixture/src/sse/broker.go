@@ -0,0 +1,190 @@
+package sse
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// broker распределяет события по подключенным клиентам. Каждый клиент подписан на набор топиков
+// (свой пользователь, документы, чаты). Отправка не блокируется: у клиента своя очередь ограниченного размера,
+// при ее переполнении событие пропускается или соединение закрывается (SlowClientPolicy).
+// Последние события хранятся в кольцевом буфере для повтора после переподключения (Last-Event-ID)
+type broker struct {
+	mu sync.Mutex
+	// номера событий уникальны только в пределах запуска приложения, поэтому в id события добавляется epoch.
+	// После перезапуска или переподключения к другому экземпляру повтор невозможен и клиент получает reset
+	epoch    string
+	lastId   int64
+	clients  map[*client]struct{}
+	byTopic  map[string]map[*client]struct{}
+	replay   []*Event // кольцевой буфер последних событий
+	replayAt int      // позиция для следующей записи в replay
+	// настройки
+	bufferSize       int
+	isDisconnectSlow bool
+}
+
+// client одно SSE соединение
+type client struct {
+	userId string
+	topics []string
+	events chan *Event
+	// закрывается, если клиент не успевает получать события и политика disconnect
+	kick     chan struct{}
+	kickOnce sync.Once
+}
+
+func newBroker(bufferSize, replaySize int, isDisconnectSlow bool) *broker {
+	return &broker{
+		epoch:            strconv.FormatInt(time.Now().UnixNano(), 36),
+		clients:          map[*client]struct{}{},
+		byTopic:          map[string]map[*client]struct{}{},
+		replay:           make([]*Event, replaySize),
+		bufferSize:       bufferSize,
+		isDisconnectSlow: isDisconnectSlow,
+	}
+}
+
+// register подключение клиента. Возвращает события после lastEventId, которые нужно отправить до новых,
+// и признак что часть событий уже вытеснена из буфера (или получена от другого запуска) и клиенту нужно перечитать данные
+func (b *broker) register(cl *client, lastEventId string) (missed []*Event, isGap bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clients[cl] = struct{}{}
+	for _, t := range cl.topics {
+		m, ok := b.byTopic[t]
+		if !ok {
+			m = map[*client]struct{}{}
+			b.byTopic[t] = m
+		}
+		m[cl] = struct{}{}
+	}
+	atomic.AddInt64(&connectedClients, 1)
+	if len(lastEventId) == 0 {
+		return nil, false
+	}
+	epoch, lastId := parseEventId(lastEventId)
+	if epoch != b.epoch {
+		return nil, true
+	}
+	if lastId >= b.lastId {
+		return nil, false
+	}
+	// события в буфере по порядку, начиная с самого старого
+	oldestId := b.lastId + 1
+	n := len(b.replay)
+	for i := 0; i < n; i++ {
+		ev := b.replay[(b.replayAt+i)%n]
+		if ev == nil {
+			continue
+		}
+		if ev.Id < oldestId {
+			oldestId = ev.Id
+		}
+		if ev.Id > lastId && cl.isSubscribed(ev.Topic) {
+			missed = append(missed, ev)
+		}
+	}
+	return missed, lastId+1 < oldestId
+}
+
+func (b *broker) unregister(cl *client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.clients[cl]; !ok {
+		return
+	}
+	delete(b.clients, cl)
+	for _, t := range cl.topics {
+		if m, ok := b.byTopic[t]; ok {
+			delete(m, cl)
+			if len(m) == 0 {
+				delete(b.byTopic, t)
+			}
+		}
+	}
+	atomic.AddInt64(&connectedClients, -1)
+}
+
+// addTopic подписка подключенного клиента на топик
+func (b *broker) addTopic(cl *client, topic string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.clients[cl]; !ok || cl.isSubscribed(topic) {
+		return
+	}
+	cl.topics = append(cl.topics, topic)
+	m, ok := b.byTopic[topic]
+	if !ok {
+		m = map[*client]struct{}{}
+		b.byTopic[topic] = m
+	}
+	m[cl] = struct{}{}
+}
+
+// removeTopic отписка клиента от топика
+func (b *broker) removeTopic(cl *client, topic string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, t := range cl.topics {
+		if t == topic {
+			cl.topics = append(cl.topics[:i:i], cl.topics[i+1:]...)
+			break
+		}
+	}
+	if m, ok := b.byTopic[topic]; ok {
+		delete(m, cl)
+		if len(m) == 0 {
+			delete(b.byTopic, topic)
+		}
+	}
+}
+
+// publish присвоение номера, запись в буфер повтора и постановка в очереди подписчиков топика
+func (b *broker) publish(ev *Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastId++
+	ev.Id = b.lastId
+	ev.epoch = b.epoch
+	if len(b.replay) > 0 {
+		b.replay[b.replayAt] = ev
+		b.replayAt = (b.replayAt + 1) % len(b.replay)
+	}
+	for cl := range b.byTopic[ev.Topic] {
+		select {
+		case cl.events <- ev:
+		default:
+			atomic.AddInt64(&droppedEvents, 1)
+			if b.isDisconnectSlow {
+				cl.kickOnce.Do(func() { close(cl.kick) })
+			}
+		}
+	}
+}
+
+func (cl *client) isSubscribed(topic string) bool {
+	for _, t := range cl.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// EventId значение поля id события: <epoch>-<номер>. По нему клиент запрашивает пропущенные события после переподключения
+func (ev *Event) EventId() string {
+	return ev.epoch + "-" + strconv.FormatInt(ev.Id, 10)
+}
+
+func parseEventId(s string) (epoch string, id int64) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return "", 0
+	}
+	id, _ = strconv.ParseInt(s[i+1:], 10, 64)
+	return s[:i], id
+}
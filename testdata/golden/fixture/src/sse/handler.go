@@ -0,0 +1,165 @@
+package sse
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"fixture/src/logger"
+	"fixture/src/utils"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxTopics      = 50
+	maxTopicLength = 200
+	// через сколько миллисекунд браузер переподключается после разрыва
+	reconnectRetry = 3000
+)
+
+// TopicAuthorizer проверка права подписки текущего пользователя на топик
+type TopicAuthorizer func(c *gin.Context, userId, topic string) bool
+
+var topicAuthorizer TopicAuthorizer = defaultTopicAuthorizer
+
+// SetTopicAuthorizer замена проверки права подписки на топики. Топик своего пользователя добавляется без проверки.
+// Без своей проверки подписка возможна только на топик своего пользователя - проект явно разрешает остальные топики (doc:, chat: и т.д.)
+func SetTopicAuthorizer(f TopicAuthorizer) {
+	topicAuthorizer = f
+}
+
+// по умолчанию подписка на любые топики, кроме своего user:<id>, запрещена
+func defaultTopicAuthorizer(c *gin.Context, userId, topic string) bool {
+	return false
+}
+
+// AddConn подключение по SSE. Клиент подписывается на свой топик user:<id> и на топики из параметра topics (через запятую).
+// После переподключения браузер передает заголовок Last-Event-ID (или параметр lastEventId) и получает пропущенные события.
+// Если пропущенных событий уже нет в памяти, первым приходит событие reset - клиенту нужно перечитать данные
+func AddConn(c *gin.Context) {
+	userId, ok := utils.ExtractUserIdString(c)
+	if !ok {
+		return
+	}
+	w := c.Writer
+	f, ok := w.(http.Flusher)
+	if !ok {
+		utils.HttpError(c, http.StatusBadRequest, "streaming unsupported")
+		return
+	}
+
+	topics, status, err := RequestTopics(c, userId)
+	if err != nil {
+		utils.HttpError(c, status, err.Error())
+		return
+	}
+
+	b := defaultBroker
+	cl := &client{
+		userId: userId,
+		topics: topics,
+		events: make(chan *Event, b.bufferSize),
+		kick:   make(chan struct{}),
+	}
+	missed, isGap := b.register(cl, RequestLastEventId(c))
+	defer b.unregister(cl)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// nginx не должен буферизовать поток
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %v\n\n", reconnectRetry)
+	if isGap {
+		writeEvent(w, &Event{Name: "reset", Data: "{}"})
+	}
+	for _, ev := range missed {
+		writeEvent(w, ev)
+	}
+	f.Flush()
+
+	heartbeat := time.NewTicker(HeartbeatInterval())
+	defer heartbeat.Stop()
+	ctx := c.Request.Context()
+	for {
+		var err error
+		select {
+		case ev := <-cl.events:
+			err = writeEvent(w, ev)
+		case <-heartbeat.C:
+			// комментарий не вызывает событие на клиенте, но не дает прокси закрыть соединение
+			_, err = io.WriteString(w, ": ping\n\n")
+		case <-cl.kick:
+			logger.Warnf("sse client of user %s is too slow, disconnected", userId)
+			return
+		case <-ctx.Done():
+			// клиент отключился
+			return
+		case <-shutdown:
+			// приложение останавливается - закрываем соединение, клиент переподключится к новому экземпляру
+			return
+		}
+		if err != nil {
+			return
+		}
+		f.Flush()
+	}
+}
+
+// RequestTopics топики из параметра topics (через запятую) с проверкой права подписки. Первым идет топик пользователя
+func RequestTopics(c *gin.Context, userId string) ([]string, int, error) {
+	topics := []string{UserTopic(userId)}
+	for _, t := range strings.Split(c.Query("topics"), ",") {
+		t = strings.TrimSpace(t)
+		if len(t) == 0 || t == topics[0] {
+			continue
+		}
+		if len(topics) > maxTopics {
+			return nil, http.StatusBadRequest, errors.New("too many topics")
+		}
+		if !IsTopicAllowed(c, userId, t) {
+			return nil, http.StatusForbidden, fmt.Errorf("topic %s not allowed", t)
+		}
+		topics = append(topics, t)
+	}
+	return topics, http.StatusOK, nil
+}
+
+// RequestLastEventId id последнего полученного клиентом события: заголовок Last-Event-ID (его передает браузер при
+// переподключении EventSource) или параметр lastEventId
+func RequestLastEventId(c *gin.Context) string {
+	if id := c.GetHeader("Last-Event-ID"); len(id) > 0 {
+		return id
+	}
+	return c.Query("lastEventId")
+}
+
+// IsTopicAllowed проверка права подписки на топик
+func IsTopicAllowed(c *gin.Context, userId, topic string) bool {
+	if len(topic) == 0 || len(topic) > maxTopicLength {
+		return false
+	}
+	return topic == UserTopic(userId) || topicAuthorizer(c, userId, topic)
+}
+
+// writeEvent запись события в формате text/event-stream
+func writeEvent(w io.Writer, ev *Event) error {
+	buf := &bytes.Buffer{}
+	if ev.Id > 0 {
+		fmt.Fprintf(buf, "id: %s\n", ev.EventId())
+	}
+	if len(ev.Name) > 0 {
+		fmt.Fprintf(buf, "event: %s\n", ev.Name)
+	}
+	for _, line := range strings.Split(ev.Data, "\n") {
+		fmt.Fprintf(buf, "data: %s\n", line)
+	}
+	buf.WriteByte('\n')
+	_, err := w.Write(buf.Bytes())
+	return err
+}
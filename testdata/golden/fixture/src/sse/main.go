@@ -0,0 +1,134 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fixture/src/logger"
+	"fixture/src/types"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event событие для клиентов, подписанных на топик
+type Event struct {
+	Id    int64  // номер события, присваивается при отправке
+	Name  string // поле event:. Пустая строка - событие message (onmessage в EventSource)
+	Topic string
+	Data  string
+	epoch string
+}
+
+const (
+	// политики при переполнении очереди клиента
+	SlowClientDrop       = "drop"
+	SlowClientDisconnect = "disconnect"
+)
+
+var (
+	config        = sseWithDefaults(types.Sse{})
+	defaultBroker = newBroker(int(config.BufferSize), int(config.ReplaySize), false)
+
+	// количество подключенных клиентов (для метрик)
+	connectedClients int64
+	// количество событий, не поставленных в очередь клиента из-за ее переполнения (для метрик)
+	droppedEvents int64
+
+	// закрывается при остановке приложения, все SSE соединения завершаются
+	shutdown     = make(chan struct{})
+	shutdownOnce sync.Once
+)
+
+// Init настройки из секции sse в config.toml
+func Init(c types.Sse) {
+	config = sseWithDefaults(c)
+	defaultBroker = newBroker(int(config.BufferSize), int(config.ReplaySize), config.SlowClientPolicy == SlowClientDisconnect)
+}
+
+func sseWithDefaults(c types.Sse) types.Sse {
+	if c.BufferSize <= 0 {
+		c.BufferSize = 64
+	}
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = 25
+	}
+	if c.ReplaySize <= 0 {
+		c.ReplaySize = 256
+	}
+	if c.SlowClientPolicy != SlowClientDisconnect {
+		c.SlowClientPolicy = SlowClientDrop
+	}
+	return c
+}
+
+// HeartbeatInterval период проверки соединения
+func HeartbeatInterval() time.Duration {
+	return time.Duration(config.HeartbeatInterval) * time.Second
+}
+
+// UserTopic топик событий пользователя. На него клиент подписывается автоматически
+func UserTopic(userId string) string {
+	return "user:" + userId
+}
+
+// TableTopic топик изменений таблицы
+func TableTopic(table string) string {
+	return "doc:" + table
+}
+
+// DocTopic топик изменений документа
+func DocTopic(table string, id int64) string {
+	return "doc:" + table + ":" + strconv.FormatInt(id, 10)
+}
+
+// ChatTopic топик сообщений чата
+func ChatTopic(id int64) string {
+	return "chat:" + strconv.FormatInt(id, 10)
+}
+
+// Publish отправка события name с данными d (сериализуются в JSON) подписчикам топика. Не блокируется
+func Publish(topic, name string, d interface{}) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		logger.Errorf("sse publish to %s error while marshaling JSON: %s", topic, err)
+		return
+	}
+	PublishString(topic, name, string(data))
+}
+
+// PublishString отправка события с готовыми данными
+func PublishString(topic, name, data string) {
+	defaultBroker.publish(&Event{Name: name, Topic: topic, Data: data})
+}
+
+// SendJson отправка данных пользователю как событие message
+func SendJson(userId string, d interface{}) {
+	Publish(UserTopic(userId), "", d)
+}
+
+// ConnectedClients количество открытых SSE и websocket соединений
+func ConnectedClients() int64 {
+	return atomic.LoadInt64(&connectedClients)
+}
+
+// DroppedEvents количество событий, которые не попали в очередь клиента из-за ее переполнения
+func DroppedEvents() int64 {
+	return atomic.LoadInt64(&droppedEvents)
+}
+
+// CloseAll закрытие всех SSE соединений при остановке приложения. Ждет, пока обработчики завершатся, чтобы
+// веб-сервер мог остановиться без ожидания бесконечных запросов
+func CloseAll(ctx context.Context) error {
+	shutdownOnce.Do(func() {
+		close(shutdown)
+	})
+	for atomic.LoadInt64(&connectedClients) > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sse
+
+// Subscriber подписка на события брокера для других транспортов (websocket). Учитывается в ConnectedClients,
+// поэтому CloseAll при остановке ждет и их закрытия
+type Subscriber struct {
+	b  *broker
+	cl *client
+}
+
+// NewSubscriber подписка пользователя на топики (первым должен быть UserTopic). Возвращает пропущенные после
+// lastEventId события и признак, что часть событий уже потеряна и клиенту нужно перечитать данные
+func NewSubscriber(userId string, topics []string, lastEventId string) (*Subscriber, []*Event, bool) {
+	b := defaultBroker
+	cl := &client{
+		userId: userId,
+		topics: append([]string{}, topics...),
+		events: make(chan *Event, b.bufferSize),
+		kick:   make(chan struct{}),
+	}
+	missed, isGap := b.register(cl, lastEventId)
+	return &Subscriber{b: b, cl: cl}, missed, isGap
+}
+
+// Events очередь событий подписчика
+func (s *Subscriber) Events() <-chan *Event {
+	return s.cl.events
+}
+
+// Kicked закрывается, если подписчик не успевает забирать события и SlowClientPolicy = disconnect
+func (s *Subscriber) Kicked() <-chan struct{} {
+	return s.cl.kick
+}
+
+// Subscribe добавление топика. Право подписки проверяется заранее через IsTopicAllowed
+func (s *Subscriber) Subscribe(topic string) {
+	s.b.addTopic(s.cl, topic)
+}
+
+// Unsubscribe удаление топика
+func (s *Subscriber) Unsubscribe(topic string) {
+	s.b.removeTopic(s.cl, topic)
+}
+
+// Close отписка от всех топиков
+func (s *Subscriber) Close() {
+	s.b.unregister(s.cl)
+}
+
+// Shutdown закрывается при остановке приложения
+func Shutdown() <-chan struct{} {
+	return shutdown
+}
@@ -0,0 +1,296 @@
+package types
+
+import (
+	"github.com/pelletier/go-toml"
+	"os"
+	"strconv"
+	"fmt"
+)
+
+type Config struct {
+	Postgres Postgres
+
+	WebServer WebServer
+
+	Graylog GraylogConfig
+
+	Logger Logger
+
+	AuthRateLimit AuthRateLimit
+
+	AuthSession AuthSession
+
+	ApiAudit ApiAudit
+
+	Sse Sse
+	
+
+	Email EmailConfig
+	
+	
+	
+}
+
+func ReadConfigFile(path string) (c *Config, err error) {
+
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		pwd, _ := os.Getwd()
+		fmt.Printf("current directory (pwd): %s\n", pwd)
+		return nil, err
+	}
+
+	c = &Config{}
+
+	if tree.Has("postgres") {
+		c.Postgres.User = tree.Get("postgres.user").(string)
+		c.Postgres.Password = tree.Get("postgres.password").(string)
+		if len(os.Getenv("PG_PASSWORD")) > 0 {
+			// перезаписываем пароль, если есть глобальная переменная
+			c.Postgres.Password = os.Getenv("PG_PASSWORD")
+		}
+		c.Postgres.DbName = tree.Get("postgres.dbName").(string)
+		if len(os.Getenv("PG_DBNAME")) > 0 {
+			// перезаписываем пароль, если есть глобальная переменная
+			c.Postgres.DbName = os.Getenv("PG_DBNAME")
+		}
+		c.Postgres.Host = tree.Get("postgres.host").(string)
+		if len(os.Getenv("PG_HOST")) > 0 {
+			// перезаписываем имя хоста, если есть глобальная переменная (для docker-compose)
+			c.Postgres.Host = os.Getenv("PG_HOST")
+		}
+		c.Postgres.Port = tree.Get("postgres.port").(int64)
+		if len(os.Getenv("PG_PORT")) > 0 {
+			// перезаписываем порт, если есть глобальная переменная (для docker-compose)
+			var port int64
+			port, err = strconv.ParseInt(os.Getenv("PG_PORT"), 10, 64)
+			if err != nil {
+				return
+			}
+			c.Postgres.Port = port
+		}
+	}
+
+	if tree.Has("webServer") {
+		if tree.Has("webServer.enable") {
+			c.WebServer.Enable = true
+		}
+		if tree.Has("webServer.port") {
+			c.WebServer.Port = tree.Get("webServer.port").(int64)
+		} else {
+			c.WebServer.Port = 8085
+		}
+		if tree.Has("webServer.url") {
+			c.WebServer.Url = tree.Get("webServer.url").(string)
+			if os.Getenv("IS_DEVELOPMENT") == "true" {
+				c.WebServer.Url = "http://localhost:8080"
+			}
+		} else {
+			c.WebServer.Url = "localhost"
+		}
+		c.WebServer.ShutdownTimeout = 30
+		if tree.Has("webServer.shutdownTimeout") && tree.Get("webServer.shutdownTimeout").(int64) > 0 {
+			c.WebServer.ShutdownTimeout = tree.Get("webServer.shutdownTimeout").(int64)
+		}
+		c.WebServer.TrustedProxies = configStringArray(tree, "webServer.trustedProxies")
+		if tree.Has("webServer.cors") {
+			c.WebServer.Cors.AllowOrigins = configStringArray(tree, "webServer.cors.allowOrigins")
+			c.WebServer.Cors.AllowMethods = configStringArray(tree, "webServer.cors.allowMethods")
+			c.WebServer.Cors.AllowHeaders = configStringArray(tree, "webServer.cors.allowHeaders")
+			if tree.Has("webServer.cors.isAllowCredentials") {
+				c.WebServer.Cors.IsAllowCredentials = tree.Get("webServer.cors.isAllowCredentials").(bool)
+			}
+			if tree.Has("webServer.cors.maxAge") {
+				c.WebServer.Cors.MaxAge = tree.Get("webServer.cors.maxAge").(int64)
+			}
+		}
+		if tree.Has("webServer.securityHeaders") {
+			if tree.Has("webServer.securityHeaders.isDisabled") {
+				c.WebServer.SecurityHeaders.IsDisabled = tree.Get("webServer.securityHeaders.isDisabled").(bool)
+			}
+			if tree.Has("webServer.securityHeaders.csp") {
+				c.WebServer.SecurityHeaders.Csp = tree.Get("webServer.securityHeaders.csp").(string)
+			}
+			if tree.Has("webServer.securityHeaders.frameOptions") {
+				c.WebServer.SecurityHeaders.FrameOptions = tree.Get("webServer.securityHeaders.frameOptions").(string)
+			}
+			if tree.Has("webServer.securityHeaders.referrerPolicy") {
+				c.WebServer.SecurityHeaders.ReferrerPolicy = tree.Get("webServer.securityHeaders.referrerPolicy").(string)
+			}
+			if tree.Has("webServer.securityHeaders.hstsMaxAge") {
+				c.WebServer.SecurityHeaders.HstsMaxAge = tree.Get("webServer.securityHeaders.hstsMaxAge").(int64)
+			}
+		}
+	}
+
+	if tree.Has("graylog") {
+		if tree.Has("graylog.host") {
+			c.Graylog.Host = tree.Get("graylog.host").(string)
+		}
+		if tree.Has("graylog.port") {
+			c.Graylog.Port = int(tree.Get("graylog.port").(int64))
+		}
+	}
+
+	if tree.Has("logger") {
+		if tree.Has("logger.level") {
+			c.Logger.Level = tree.Get("logger.level").(string)
+		}
+		if tree.Has("logger.format") {
+			c.Logger.Format = tree.Get("logger.format").(string)
+		}
+		if tree.Has("logger.file") {
+			c.Logger.File = tree.Get("logger.file").(string)
+		}
+		if tree.Has("logger.isStdoutDisabled") {
+			c.Logger.IsStdoutDisabled = tree.Get("logger.isStdoutDisabled").(bool)
+		}
+	}
+	if len(os.Getenv("LOG_LEVEL")) > 0 {
+		// перезаписываем, если есть глобальная переменная
+		c.Logger.Level = os.Getenv("LOG_LEVEL")
+	}
+	if len(c.Logger.Format) == 0 {
+		// в dev режиме удобнее читать текст
+		c.Logger.Format = "json"
+		if os.Getenv("IS_DEVELOPMENT") == "true" {
+			c.Logger.Format = "text"
+		}
+	}
+
+	if tree.Has("authRateLimit") {
+		if tree.Has("authRateLimit.isDisabled") {
+			c.AuthRateLimit.IsDisabled = tree.Get("authRateLimit.isDisabled").(bool)
+		}
+		if tree.Has("authRateLimit.window") {
+			c.AuthRateLimit.Window = tree.Get("authRateLimit.window").(int64)
+		}
+		if tree.Has("authRateLimit.perIp") {
+			c.AuthRateLimit.PerIp = tree.Get("authRateLimit.perIp").(int64)
+		}
+		if tree.Has("authRateLimit.perLogin") {
+			c.AuthRateLimit.PerLogin = tree.Get("authRateLimit.perLogin").(int64)
+		}
+		if tree.Has("authRateLimit.perPhone") {
+			c.AuthRateLimit.PerPhone = tree.Get("authRateLimit.perPhone").(int64)
+		}
+		if tree.Has("authRateLimit.lockoutFailures") {
+			c.AuthRateLimit.LockoutFailures = tree.Get("authRateLimit.lockoutFailures").(int64)
+		}
+		if tree.Has("authRateLimit.lockoutDuration") {
+			c.AuthRateLimit.LockoutDuration = tree.Get("authRateLimit.lockoutDuration").(int64)
+		}
+		if tree.Has("authRateLimit.isPgStore") {
+			c.AuthRateLimit.IsPgStore = tree.Get("authRateLimit.isPgStore").(bool)
+		}
+	}
+
+	if tree.Has("authSession") {
+		if tree.Has("authSession.isDisabled") {
+			c.AuthSession.IsDisabled = tree.Get("authSession.isDisabled").(bool)
+		}
+		if tree.Has("authSession.accessTtl") {
+			c.AuthSession.AccessTtl = tree.Get("authSession.accessTtl").(int64)
+		}
+		if tree.Has("authSession.refreshTtl") {
+			c.AuthSession.RefreshTtl = tree.Get("authSession.refreshTtl").(int64)
+		}
+		if tree.Has("authSession.isLegacyTokenAllowed") {
+			c.AuthSession.IsLegacyTokenAllowed = tree.Get("authSession.isLegacyTokenAllowed").(bool)
+		}
+	}
+
+	if tree.Has("apiAudit") {
+		if tree.Has("apiAudit.isEnabled") {
+			c.ApiAudit.IsEnabled = tree.Get("apiAudit.isEnabled").(bool)
+		}
+		c.ApiAudit.RedactFields = configStringArray(tree, "apiAudit.redactFields")
+		if tree.Has("apiAudit.retentionDays") {
+			c.ApiAudit.RetentionDays = tree.Get("apiAudit.retentionDays").(int64)
+		}
+		if tree.Has("apiAudit.batchSize") {
+			c.ApiAudit.BatchSize = tree.Get("apiAudit.batchSize").(int64)
+		}
+		if tree.Has("apiAudit.flushInterval") {
+			c.ApiAudit.FlushInterval = tree.Get("apiAudit.flushInterval").(int64)
+		}
+		if tree.Has("apiAudit.bufferSize") {
+			c.ApiAudit.BufferSize = tree.Get("apiAudit.bufferSize").(int64)
+		}
+	}
+
+	if tree.Has("sse") {
+		if tree.Has("sse.bufferSize") {
+			c.Sse.BufferSize = tree.Get("sse.bufferSize").(int64)
+		}
+		if tree.Has("sse.heartbeatInterval") {
+			c.Sse.HeartbeatInterval = tree.Get("sse.heartbeatInterval").(int64)
+		}
+		if tree.Has("sse.replaySize") {
+			c.Sse.ReplaySize = tree.Get("sse.replaySize").(int64)
+		}
+		if tree.Has("sse.slowClientPolicy") {
+			c.Sse.SlowClientPolicy = tree.Get("sse.slowClientPolicy").(string)
+		}
+	}
+
+	
+
+	if tree.Has("email") {
+		c.Email.Sender = tree.Get("email.sender").(string)
+		if len(os.Getenv("EMAIL_SENDER")) > 0 {
+			c.Email.Sender = os.Getenv("EMAIL_SENDER")
+		}
+		c.Email.Password = tree.Get("email.password").(string)
+		if len(os.Getenv("EMAIL_PASSWORD")) > 0 {
+			c.Email.Password = os.Getenv("EMAIL_PASSWORD")
+		}
+		c.Email.Host = tree.Get("email.host").(string)
+		if len(os.Getenv("EMAIL_HOST")) > 0 {
+			c.Email.Host = os.Getenv("EMAIL_HOST")
+		}
+		if tree.Has("email.port") {
+			c.Email.Port = tree.Get("email.port").(int64)
+		} else {
+			c.Email.Port = 25
+		}
+		if len(os.Getenv("EMAIL_PORT")) > 0 {
+			c.Email.Port, err = strconv.ParseInt(os.Getenv("EMAIL_PORT"), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if tree.Has("email.senderName") {
+			c.Email.SenderName = tree.Get("email.senderName").(string)
+		}
+		if tree.Has("email.senderLogo") {
+			c.Email.SenderLogo = tree.Get("email.senderLogo").(string)
+		}
+		if tree.Has("email.isSendWithEmptySender") {
+			c.Email.IsSendWithEmptySender = tree.Get("email.isSendWithEmptySender").(bool)
+		}
+	}
+	
+
+	
+
+	
+
+	return
+}
+
+// массив строк из config.toml. Пустой или отсутствующий массив - nil
+func configStringArray(tree *toml.Tree, key string) []string {
+	if !tree.Has(key) {
+		return nil
+	}
+	res := []string{}
+	if arr, ok := tree.Get(key).([]interface{}); ok {
+		for _, v := range arr {
+			if s, ok := v.(string); ok {
+				res = append(res, s)
+			}
+		}
+	}
+	return res
+}
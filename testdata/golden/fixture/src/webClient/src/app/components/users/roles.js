@@ -0,0 +1,6 @@
+import {i18n} from 'boot/i18n'
+export default [
+    {label: i18n.global.t('user.role_admin'), value: 'admin'},
+		{label: i18n.global.t('user.role_student'), value: 'student'},
+
+]
\ No newline at end of file
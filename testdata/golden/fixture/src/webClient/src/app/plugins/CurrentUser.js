@@ -0,0 +1,110 @@
+import {BehaviorSubject} from 'rxjs'
+import config from './config'
+import utils from './utils'
+import {Loading} from 'quasar'
+
+let user$ = new BehaviorSubject(null)
+let isInLogingProcess$ = new BehaviorSubject(false) // флаг для стадии процесса логина
+// auth_token - короткий access токен сессии, обновляется по refresh токену до окончания его действия
+let refreshTimer = null
+let refreshAt = 0 // время (ms), после которого нужно обновить auth_token
+
+const refreshKey = () => `${config.appName}_refresh`
+
+const CurrentUser = class {
+  getUser$ = () => user$
+
+  login = ({user, auth_token} = {}) => {
+    // в случае если уже передали авторизованного пользователя (например, при первоначальной авторизации через соцсети)
+    if (user) {
+      setTokens(user)
+      user$.next(user)
+    } else {
+      // вариант логина по токену, который ищем в localStorage
+      // если auth_token не передан в параметрах, то ищем его в localStorage
+      if (!auth_token) auth_token = localStorage.getItem(config.appName)
+      // записываем токен в localstorage, чтобы в header запроса был подставлен новый токен
+      localStorage.setItem(config.appName, auth_token)
+      loginProcess(true)
+
+      utils.postApiRequest({url: '/api/current_user', params: {auth_token}, isShowError: false}).subscribe(res => {
+        if (res.ok) {
+          setTokens(res.result)
+          user$.next(res.result)
+          loginProcess(false)
+        } else {
+          // access токен истек - пробуем обновить по refresh токену
+          refreshTokens(ok => {
+            if (!ok) this.logout()
+            loginProcess(false)
+          })
+        }
+      })
+    }
+  }
+
+  // новые токены сессии по refresh токену
+  refresh = (cb) => refreshTokens(cb)
+
+  logout = () => {
+    clearTimeout(refreshTimer)
+    // отзываем сессию на сервере, ответ не ждем
+    if (user$.value) utils.postApiRequest({url: '/api/logout', params: {}, isShowError: false}).subscribe(() => {})
+    user$.next(null)
+    localStorage.removeItem(config.appName)
+    localStorage.removeItem(refreshKey())
+  }
+
+  getIsInLogingProcess = () => isInLogingProcess$
+}
+
+// сохранение токенов и запуск обновления за минуту до окончания действия auth_token
+const setTokens = (user) => {
+  localStorage.setItem(config.appName, user.auth_token)
+  // refresh_token приходит только при входе и обновлении, в данных пользователя его не храним
+  if (user.refresh_token) localStorage.setItem(refreshKey(), user.refresh_token)
+  delete user.refresh_token
+  clearTimeout(refreshTimer)
+  if (user.access_expires_in > 0) {
+    const delay = Math.max(user.access_expires_in - 60, 5) * 1000
+    refreshAt = Date.now() + delay
+    refreshTimer = setTimeout(() => refreshTokens(), delay)
+  }
+}
+
+const refreshTokens = (cb) => {
+  const refresh_token = localStorage.getItem(refreshKey())
+  if (!refresh_token) {
+    if (cb) cb(false)
+    return
+  }
+  utils.postApiRequest({url: '/auth/refresh', params: {refresh_token}, isShowError: false}).subscribe(res => {
+    if (res.ok) {
+      setTokens(res.result)
+      user$.next(res.result)
+    } else if (user$.value) {
+      // сессия отозвана или refresh токен истек
+      user$.next(null)
+      localStorage.removeItem(config.appName)
+      localStorage.removeItem(refreshKey())
+    }
+    if (cb) cb(res.ok)
+  })
+}
+
+// таймер не срабатывает, пока вкладка в фоне или компьютер спит - проверяем при возвращении на вкладку
+document.addEventListener('visibilitychange', () => {
+  if (!document.hidden && user$.value && refreshAt > 0 && Date.now() > refreshAt) refreshTokens()
+})
+
+const loginProcess = (isTrue) => {
+  if (isTrue) {
+    Loading.show({message: 'авторизация'})
+    isInLogingProcess$.next(true)
+  } else {
+    Loading.hide()
+    isInLogingProcess$.next(false)
+  }
+}
+
+export default CurrentUser
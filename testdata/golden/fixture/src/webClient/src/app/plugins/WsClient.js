@@ -0,0 +1,152 @@
+import {Observable, Subject, BehaviorSubject} from 'rxjs'
+import {filter} from 'rxjs/operators'
+import config from './config'
+
+// Клиент /api/ws: события из топиков и вызов pg методов. Переподключается после разрыва с увеличивающейся паузой,
+// при переподключении передает id последнего полученного события и получает пропущенные.
+// Пример:
+//   this.$ws.call({method: 'task_list', params: {}}).then(res => ...)
+//   const sub = this.$ws.subscribeTopic('doc:task:5').subscribe(ev => ...)
+//   this.$ws.getUserDocEvents$('task', 5).subscribe(ev => ...) - события документа с получателями (Realtime.Recipients)
+//   sub.unsubscribe() - при уходе со страницы
+//   this.$ws.getReset$().subscribe(() => ...) - часть событий потеряна, нужно перечитать данные
+
+const callTimeout = 60000
+const maxReconnectDelay = 30000
+// соединение закрыто сервером, так как токен больше не действует - переподключаемся с новым токеном
+const closeTokenInvalid = 4001
+
+let socket = null
+let isStopped = true
+let reconnectDelay = 1000
+let reconnectTimer = null
+let lastEventId = ''
+let nextId = 1
+const topics = new Map() // топик -> количество подписчиков
+const pending = new Map() // id запроса -> {resolve, timer}
+const queue = [] // запросы, отправленные до подключения
+const isConnected$ = new BehaviorSubject(false)
+const events$ = new Subject()
+const reset$ = new Subject()
+
+const WsClient = class {
+  getIsConnected$ = () => isConnected$
+
+  // все события: {event_id, event, topic, data}
+  getEvents$ = () => events$
+
+  getReset$ = () => reset$
+
+  // события doc из топика своего пользователя по таблице (и id записи, если указан). На топик пользователя
+  // сервер подписывает сам, поэтому отдельная подписка не нужна
+  getUserDocEvents$ = (table, id) => events$.pipe(filter(ev => ev.event === 'doc' && ev.topic.startsWith('user:') &&
+    ev.data && ev.data.table === table && (id === undefined || String(ev.data.id) === String(id))))
+
+  // подключение. Вызывается при входе пользователя и при каждом обновлении его данных, поэтому повторно не подключаемся
+  connect = () => {
+    isStopped = false
+    if (!socket && !reconnectTimer) open()
+  }
+
+  // отключение без переподключения. Вызывается при выходе пользователя
+  close = () => {
+    isStopped = true
+    clearTimeout(reconnectTimer)
+    reconnectTimer = null
+    topics.clear()
+    lastEventId = ''
+    if (socket) socket.close()
+  }
+
+  // вызов pg метода. Результат как у utils.postCallPgMethod: {ok, result, message}
+  call = ({method, params}) => request({type: 'call', method, params})
+
+  // подписка на события топика. Топик сохраняется и передается при переподключении.
+  // От топика на сервере отписываемся, когда отписались все подписчики (например, закрыты список и карточка документа)
+  subscribeTopic = (topic) => new Observable(subscriber => {
+    const count = topics.get(topic) || 0
+    topics.set(topic, count + 1)
+    if (count === 0 && isConnected$.value) request({type: 'subscribe', topic}).then(res => !res.ok && console.warn('ws subscribe', topic, res.message))
+    const sub = events$.pipe(filter(ev => ev.topic === topic)).subscribe(subscriber)
+    return () => {
+      sub.unsubscribe()
+      const n = (topics.get(topic) || 0) - 1
+      if (n > 0) {
+        topics.set(topic, n)
+      } else {
+        unsubscribe(topic)
+      }
+    }
+  })
+
+  // отписка от топика для всех подписчиков
+  unsubscribeTopic = (topic) => unsubscribe(topic)
+}
+
+const unsubscribe = (topic) => {
+  if (topics.delete(topic) && isConnected$.value) request({type: 'unsubscribe', topic})
+}
+
+const open = () => {
+  reconnectTimer = null
+  const authToken = localStorage.getItem(config.appName)
+  if (isStopped || !authToken) return
+  const query = [`authToken=${encodeURIComponent(authToken)}`]
+  if (topics.size > 0) query.push(`topics=${encodeURIComponent([...topics.keys()].join(','))}`)
+  if (lastEventId) query.push(`lastEventId=${encodeURIComponent(lastEventId)}`)
+  socket = new WebSocket(`${config.wsUrl()}/api/ws?${query.join('&')}`)
+
+  socket.onopen = () => {
+    reconnectDelay = 1000
+    isConnected$.next(true)
+    while (queue.length > 0) socket.send(queue.shift())
+  }
+
+  socket.onmessage = (e) => {
+    const msg = JSON.parse(e.data)
+    switch (msg.type) {
+      case 'event':
+        lastEventId = msg.event_id
+        events$.next(msg)
+        break
+      case 'reset':
+        reset$.next()
+        break
+      case 'result':
+        if (pending.has(msg.id)) {
+          const {resolve, timer} = pending.get(msg.id)
+          clearTimeout(timer)
+          pending.delete(msg.id)
+          resolve({ok: !!msg.ok, result: msg.result, message: msg.message, meta_info: msg.meta_info})
+        }
+        break
+    }
+  }
+
+  socket.onclose = (e) => {
+    socket = null
+    isConnected$.next(false)
+    if (isStopped) return
+    // токен обновляется в CurrentUser по refresh токену, поэтому после 4001 переподключаемся сразу
+    const delay = e.code === closeTokenInvalid ? 1000 : reconnectDelay
+    reconnectDelay = Math.min(reconnectDelay * 2, maxReconnectDelay)
+    reconnectTimer = setTimeout(open, delay + Math.random() * 1000)
+  }
+}
+
+const request = (msg) => new Promise(resolve => {
+  const id = nextId++
+  const timer = setTimeout(() => {
+    pending.delete(id)
+    resolve({ok: false, message: 'timeout'})
+  }, callTimeout)
+  pending.set(id, {resolve, timer})
+  const data = JSON.stringify({...msg, id})
+  if (socket && socket.readyState === WebSocket.OPEN) {
+    socket.send(data)
+  } else {
+    queue.push(data)
+  }
+})
+
+export default WsClient
@@ -0,0 +1,18 @@
+export default {
+  appName: 'fixture',
+  uiAppName: '',
+  apiUrl: () => process.env.NODE_ENV === 'development' ? 'http://localhost:3090' : 'https://fixture.ru',
+  wsUrl: () => process.env.NODE_ENV === 'development' ? 'ws://localhost:3090' : 'wss://fixture.ru',
+  isEmailAuth: {
+    firstName: true,
+    lastName: true,
+  },
+  logoSrc: '',
+  dadataToken: '1cf3a086e3dbe1306ed142d2b5fbc1b324b8eb60',
+  // yandexMetrikaId: 54433825,
+  breadcrumbIcons: {
+    
+  },
+  
+  tablesForTask: {},
+}
@@ -0,0 +1,98 @@
+// файл генерируется автоматически по описанию документов
+
+export interface User {
+  id: number
+  username: string
+  first_name: string
+  last_name: string
+  fullname: string
+  avatar: string
+  role: string[]
+  state: string
+  auth_provider: string
+  auth_provider_id: string
+  options: {[key: string]: any}
+  deleted: boolean
+  phone: string
+  email?: string
+}
+
+// параметры для методов *_list
+export interface ListParams {
+  deleted?: boolean
+  order_by?: string
+  page?: number
+  per_page?: number
+  search_text?: string
+}
+
+/** клиент */
+export interface Client {
+  id: number
+  /** название */
+  title: string
+  /** ИНН */
+  inn: string | null
+  /** менеджер */
+  manager_id: number | null
+  manager_title?: string
+  /** сумма */
+  amount: number | null
+  options: ClientOptions
+  created_at?: string
+  updated_at?: string
+  deleted: boolean
+}
+
+export interface ClientOptions {
+  [key: string]: any
+}
+
+
+// параметры и результат каждого pg метода
+export interface PgMethodMap {
+  user_update: {params: Record<string, any>, result: User}
+  user_list: {params: Record<string, any>, result: User[]}
+  user_get_by_id: {params: Record<string, any>, result: User}
+  user_get_by_id_for_ui: {params: Record<string, any>, result: User}
+  current_user_update: {params: Record<string, any>, result: User}
+  current_user_get_auth_providers: {params: Record<string, any>, result: any}
+  user_session_list: {params: Record<string, any>, result: any}
+  user_session_revoke: {params: Record<string, any>, result: any}
+  current_user_session_list: {params: Record<string, any>, result: any}
+  current_user_session_revoke: {params: Record<string, any>, result: any}
+  client_get_by_id: {params: {id: number}, result: Client}
+  client_list: {params: ListParams & Partial<Client>, result: Client[]}
+  client_update: {params: Partial<Client> & {id: number}, result: Client}
+}
+
+export type PgMethodName = keyof PgMethodMap
+
+export declare const PgMethod: {
+  readonly USER_UPDATE: 'user_update'
+  readonly USER_LIST: 'user_list'
+  readonly USER_GET_BY_ID: 'user_get_by_id'
+  readonly USER_GET_BY_ID_FOR_UI: 'user_get_by_id_for_ui'
+  readonly CURRENT_USER_UPDATE: 'current_user_update'
+  readonly CURRENT_USER_GET_AUTH_PROVIDERS: 'current_user_get_auth_providers'
+  readonly USER_SESSION_LIST: 'user_session_list'
+  readonly USER_SESSION_REVOKE: 'user_session_revoke'
+  readonly CURRENT_USER_SESSION_LIST: 'current_user_session_list'
+  readonly CURRENT_USER_SESSION_REVOKE: 'current_user_session_revoke'
+  readonly CLIENT_GET_BY_ID: 'client_get_by_id'
+  readonly CLIENT_LIST: 'client_list'
+  readonly CLIENT_UPDATE: 'client_update'
+}
+
+export interface CallPgFuncOptions {
+  isShowError?: boolean
+  successMsg?: string | null
+}
+
+export declare function callPgFunc<M extends PgMethodName>(method: M, params?: PgMethodMap[M]['params'], options?: CallPgFuncOptions): Promise<PgMethodMap[M]['result']>
+
+declare const _default: {
+  PgMethod: typeof PgMethod
+  callPgFunc: typeof callPgFunc
+}
+export default _default
@@ -0,0 +1,32 @@
+// файл генерируется автоматически по описанию документов. Типы документов и методов описаны в pgApi.d.ts
+import utils from './utils'
+
+// названия pg методов, доступных через /api/call_pg_func
+export const PgMethod = Object.freeze({
+  USER_UPDATE: 'user_update',
+  USER_LIST: 'user_list',
+  USER_GET_BY_ID: 'user_get_by_id',
+  USER_GET_BY_ID_FOR_UI: 'user_get_by_id_for_ui',
+  CURRENT_USER_UPDATE: 'current_user_update',
+  CURRENT_USER_GET_AUTH_PROVIDERS: 'current_user_get_auth_providers',
+  USER_SESSION_LIST: 'user_session_list',
+  USER_SESSION_REVOKE: 'user_session_revoke',
+  CURRENT_USER_SESSION_LIST: 'current_user_session_list',
+  CURRENT_USER_SESSION_REVOKE: 'current_user_session_revoke',
+  CLIENT_GET_BY_ID: 'client_get_by_id',
+  CLIENT_LIST: 'client_list',
+  CLIENT_UPDATE: 'client_update',
+})
+
+// вызов postgres функции через /api/call_pg_func. Возвращает Promise с результатом функции
+export const callPgFunc = (method, params = {}, {isShowError = true, successMsg = null} = {}) => new Promise((resolve, reject) => {
+  utils.postCallPgMethod({method, params, isShowError, successMsg}).subscribe(res => {
+    if (res.ok) {
+      resolve(res.result)
+    } else {
+      reject(new Error(res.message))
+    }
+  })
+})
+
+export default {PgMethod, callPgFunc}
@@ -0,0 +1,6 @@
+import axios from 'axios'
+
+export default async ({app}) => {
+  app.config.globalProperties.$axios = axios
+}
+
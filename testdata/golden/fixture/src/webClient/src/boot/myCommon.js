@@ -0,0 +1,6 @@
+import commonComponents from '../app/components/common'
+
+export default ({app}) => {
+  // подключаем свои common компоненты
+  commonComponents(app)
+}
@@ -0,0 +1,6 @@
+import utils from '../app/plugins/utils'
+
+export default async ({app}) => {
+  app.config.globalProperties.$utils = utils
+}
+
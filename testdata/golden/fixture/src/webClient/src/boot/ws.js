@@ -0,0 +1,8 @@
+import WsClient from '../app/plugins/WsClient'
+
+// подключение по websocket, пока пользователь авторизован
+export default async ({app}) => {
+  const ws = new WsClient()
+  app.config.globalProperties.$ws = ws
+  app.config.globalProperties.$currentUser.getUser$().subscribe(user => user ? ws.connect() : ws.close())
+}
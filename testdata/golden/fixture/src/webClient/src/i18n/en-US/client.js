@@ -0,0 +1,10 @@
+
+export default {
+ 		amount: 'amount',
+ 		inn: 'inn',
+ 		manager_id: 'manager ',
+ 		name: 'client',
+ 		name_plural: 'client',
+ 		name_plural_deleted: 'deleted client',
+ 		title: 'title',
+}
@@ -0,0 +1,69 @@
+import client from './client'
+
+export default {
+	auth: {
+ 		authorization: 'authorization',
+ 		check_user_email_message: 'The link is no longer active. Possible reasons: <br/> <br/> - Your email has already been successfully confirmed - try to enter the system with your username and password <br/> - The email address has expired. Need to register again',
+ 		forgot_password: 'forgot password',
+ 		invalid_email: '"email" field is filled incorrectly',
+ 		invalid_first_name: 'you must fill in the "first name" field',
+ 		invalid_last_name: 'you must fill in the "last name" field',
+ 		invalid_password_must_be_more_7: 'password must be more than 7 characters',
+ 		invalid_password_wrong_repeat: 're-entered password does not match the first option',
+ 		invalid_user_already_exist: 'user with this email is already registered',
+ 		login: 'login',
+ 		password: 'password',
+ 		password_recovery: 'password recovery',
+ 		password_recovery_go_to_login: 'go to authorization',
+ 		password_recovery_message: 'Check the email specified in the registration. A letter should come with a link for password recovery.',
+ 		password_recovery_success_message: 'password changed successfully',
+ 		password_repeat: 'repeat password',
+ 		register: 'register',
+ 		register_message: 'Check the email specified in the registration. A letter should come to confirm the mailing address.',
+ 		registration: 'registration',
+ 		user_not_found: 'user not found',
+ 		wrong_password: 'wrong password',
+	},
+	menu: {
+ 		client: 'client',
+ 		user: 'users',
+	},
+	message: {
+ 		cancel: 'cancel',
+ 		delete: 'delete',
+ 		doc_deleted: 'document deleted',
+ 		edit: 'edit',
+ 		file: 'file',
+ 		files: 'files',
+ 		ok: 'ok',
+ 		photo: 'photo',
+ 		save: 'save',
+ 		send: 'send',
+	},
+	profile: {
+ 		avatar: 'photo',
+ 		breadcrumb_label: 'Profile edit',
+ 		exit: 'exit',
+ 		first_name: 'first name',
+ 		last_name: 'last name',
+ 		phone: 'phone',
+	},
+	user: {
+ 		email: 'email',
+ 		first_name: 'first name',
+ 		grade: 'grade',
+ 		last_name: 'last name',
+ 		name_plural: 'users',
+ 		name_plural_deleted: 'deleted users',
+ 		phone: 'phone',
+ 		photo: 'photo',
+ 		role_admin: 'admin',
+ 		role_student: 'user',
+ 		roles: 'roles',
+ 		state: 'state',
+ 		state_fired: 'fired',
+ 		state_waiting_auth: 'waiting auth',
+ 		state_working: 'working',
+	},
+	client,
+}
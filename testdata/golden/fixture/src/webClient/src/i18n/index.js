@@ -0,0 +1,7 @@
+import en from './en-US'
+import ru from './ru'
+
+export default {
+		'en-US': en,
+		'ru-RU': ru,
+}
@@ -0,0 +1,10 @@
+
+export default {
+ 		amount: 'сумма',
+ 		inn: 'ИНН',
+ 		manager_id: 'менеджер',
+ 		name: 'клиент',
+ 		name_plural: 'Клиент',
+ 		name_plural_deleted: 'удаленные Клиент',
+ 		title: 'название',
+}
@@ -0,0 +1,69 @@
+import client from './client'
+
+export default {
+	auth: {
+ 		authorization: 'авторизация',
+ 		check_user_email_message: 'Ссылка уже неактивна. Возможные причины: <br/><br/>- Ваш email уже успешно подтвержден - попробуйте войти в систему с логином и паролем <br/>- Истекло время подтверждения почтового адреса. Нужно регистрироваться снова',
+ 		forgot_password: 'забыли пароль',
+ 		invalid_email: 'поле "email" заполнено неверно',
+ 		invalid_first_name: 'необходимо заполнить поле "имя"',
+ 		invalid_last_name: 'необходимо заполнить поле "фамилия"',
+ 		invalid_password_must_be_more_7: 'пароль должен быть больше 7 знаков',
+ 		invalid_password_wrong_repeat: 'повторно введенный пароль не совпадает с первым вариантом',
+ 		invalid_user_already_exist: 'пользователь с таким email уже зарегестрирован',
+ 		login: 'войти',
+ 		password: 'пароль',
+ 		password_recovery: 'восстановление пароля',
+ 		password_recovery_go_to_login: 'перейти к авторизации',
+ 		password_recovery_message: 'Проверьте указанный в регистрации email. Должно прийти письмо с ссылкой для восстановления пароля.',
+ 		password_recovery_success_message: 'пароль успешно изменен',
+ 		password_repeat: 'повторите пароль',
+ 		register: 'зарегистрироваться',
+ 		register_message: 'Проверьте указанный в регистрации email. Должно прийти письмо для подтверждения почтового адреса.',
+ 		registration: 'регистрация',
+ 		user_not_found: 'пользователь с таким email не найден',
+ 		wrong_password: 'неверный пароль',
+	},
+	menu: {
+ 		client: 'Клиент',
+ 		user: 'пользователи',
+	},
+	message: {
+ 		cancel: 'отмена',
+ 		delete: 'удалить',
+ 		doc_deleted: 'документ удален',
+ 		edit: 'редактировать',
+ 		file: 'файл',
+ 		files: 'файлы',
+ 		ok: 'ok',
+ 		photo: 'фото',
+ 		save: 'сохранить',
+ 		send: 'отправить',
+	},
+	profile: {
+ 		avatar: 'фото',
+ 		breadcrumb_label: 'Редактирование профиля',
+ 		exit: 'выйти',
+ 		first_name: 'имя',
+ 		last_name: 'фамилия',
+ 		phone: 'телефон',
+	},
+	user: {
+ 		email: 'email',
+ 		first_name: 'имя',
+ 		grade: 'должность',
+ 		last_name: 'фамилия',
+ 		name_plural: 'пользователи',
+ 		name_plural_deleted: 'удаленные пользователи',
+ 		phone: 'телефон',
+ 		photo: 'фото',
+ 		role_admin: 'админ',
+ 		role_student: 'сотрудник',
+ 		roles: 'роли',
+ 		state: 'статус',
+ 		state_fired: 'уволен',
+ 		state_waiting_auth: 'ожидает авторизации',
+ 		state_working: 'работает',
+	},
+	client,
+}
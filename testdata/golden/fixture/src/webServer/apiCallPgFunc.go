@@ -0,0 +1,261 @@
+package webServer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"fixture/src/cacheUtil"
+	"fixture/src/pg"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type (
+	PgMethod struct {
+		Title      string
+		Roles      []string
+		Cache      PgMethodCache
+		BeforeHook func(*gin.Context, interface{}) error
+		Timeout    time.Duration // таймаут вызова функции. Если 0, то pg.DefaultCallTimeout
+		Tables     []string      // таблицы, при изменении которых сбрасывается кэш метода (событие из pg.processPgEvent)
+	}
+	PgMethodCache interface {
+		Duration() time.Duration                                        // время кэширования в секундах
+		GetKey(userId, role string, params interface{}) (string, error) // формирование ключа
+	}
+)
+
+var (
+	pgFuncCache = cacheUtil.NewCache("pg_func", 5000)
+	pgFuncList  = []PgMethod{
+		PgMethod{Title: "user_update", Roles: []string{"admin",}, Tables: []string{"user"}},
+		PgMethod{Title: "user_list", Roles: []string{}, Tables: []string{"user"}},
+		PgMethod{Title: "user_get_by_id", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "user_get_by_id_for_ui", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "current_user_update", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user"}},
+		PgMethod{Title: "current_user_get_auth_providers", Roles: []string{}, BeforeHook: BeforeHookAddUserId, Tables: []string{"user_auth"}},
+		// сессии пользователей. user_id в user_session_revoke - кто отзывает, пользователь сессий передается в session_user_id
+		PgMethod{Title: "user_session_list", Roles: []string{"admin"}},
+		PgMethod{Title: "user_session_revoke", Roles: []string{"admin"}, BeforeHook: BeforeHookAddUserId},
+		PgMethod{Title: "current_user_session_list", Roles: []string{}, BeforeHook: BeforeHookAddUserId},
+		PgMethod{Title: "current_user_session_revoke", Roles: []string{}, BeforeHook: BeforeHookAddUserId},
+		
+		PgMethod{Title: "client_get_by_id", Roles: []string{"admin"}, BeforeHook: BeforeHookAddUserId, Tables: []string{"client"}},
+		PgMethod{Title: "client_list", Roles: []string{"admin"}, BeforeHook: BeforeHookAddUserId, Cache: newPgMethodCache("client_list", 60, "user", "params"), Tables: []string{"client"}},
+		PgMethod{Title: "client_update", Roles: []string{"admin"}, BeforeHook: BeforeHookAddUserId, Tables: []string{"client"}},
+	}
+)
+
+func apiCallPgFunc(c *gin.Context) {
+
+	var jsonParam JsonParamType
+	if v, ok := c.Get(utils.ContextJsonParam); !ok {
+		utils.HttpError(c, http.StatusMethodNotAllowed, "missed params")
+		return
+	} else {
+		jsonParam = v.(JsonParamType)
+	}
+	if len(jsonParam.Method) == 0 {
+		utils.HttpError(c, http.StatusMethodNotAllowed, "missed method name")
+		return
+	}
+
+	queryRes, ok := callPgMethod(c, jsonParam)
+	if !ok {
+		return
+	}
+	pgMethodResponse(c, http.StatusOK, queryRes)
+}
+
+// callPgMethod вызов метода из pgFuncList с проверкой ролей, before hook'ами и кэшем. Вызов записывается в журнал api_audit.
+// Используется в /api/call_pg_func и в REST api. В случае ошибки ответ уже записан в c и возвращается false
+func callPgMethod(c *gin.Context, jsonParam JsonParamType) ([]byte, bool) {
+	queryRes, status, err := execPgMethod(c, jsonParam)
+	if err != nil {
+		utils.HttpError(c, status, err.Error())
+		return nil, false
+	}
+	return queryRes, true
+}
+
+// execPgMethod то же, что callPgMethod, но ошибка с http статусом возвращается, а не записывается в ответ. Используется в websocket
+func execPgMethod(c *gin.Context, jsonParam JsonParamType) ([]byte, int, error) {
+	// проверяем что метод из списка разрешенных для вызова через api
+	isCorrectMethod := false
+	isAllowedMethod := false
+	// создаем переменную для хранении информации о кэше
+	cacheResult := struct {
+		Data     []byte
+		Duration time.Duration
+		Key      string
+	}{}
+
+	u, _ := c.Get(utils.GinContextUser)
+	user := u.(*types.User)
+
+	audit := newApiAuditCall(c, user, jsonParam)
+	defer audit.finish()
+	defer observePgMethod(audit)
+	fail := func(status int, msg string) ([]byte, int, error) {
+		audit.fail(msg)
+		return nil, status, errors.New(msg)
+	}
+
+	var method PgMethod
+	for _, v := range pgFuncList {
+		if v.Title == jsonParam.Method {
+			isCorrectMethod = true
+			method = v
+			// если роли для данного метода не указаны, то метод автоматически разрешен
+			if len(v.Roles) == 0 {
+				isAllowedMethod = true
+			}
+			// проверяем роль
+			for _, role := range v.Roles {
+				for _, userRole := range user.Role {
+					if userRole == role {
+						isAllowedMethod = true
+						break
+					}
+				}
+			}
+			break
+		}
+	}
+	// если метода нет в списке то выходим
+	if !isCorrectMethod {
+		return fail(http.StatusMethodNotAllowed, "not allowed method: "+jsonParam.Method)
+	}
+
+	// если метода нет в списке то выходим
+	if !isAllowedMethod {
+		return fail(http.StatusMethodNotAllowed, "for this role not allowed method: "+jsonParam.Method)
+	}
+
+	// hook'и и кэш - только после проверки ролей, чтобы без прав не срабатывали побочные эффекты hook'ов
+	if method.BeforeHook != nil {
+		err := method.BeforeHook(c, jsonParam)
+		if err != nil {
+			return fail(http.StatusMethodNotAllowed, err.Error())
+		}
+	}
+	if method.Cache != nil {
+		key, err := method.Cache.GetKey(user.IdString(), user.GetRoleAsString(), jsonParam.Params)
+		if err == nil {
+			// в случае ошибки не формируем ключ для кэширования. Детали об ошибки выводим внутри метода.
+			// заполняем информацию, необходимую для кэширования
+			cacheResult.Key = key
+			cacheResult.Duration = method.Cache.Duration() * time.Second
+			if res, ok := pgFuncCache.Get(key); ok {
+				cacheResult.Data = res.([]byte)
+			}
+		}
+	}
+
+	// запрос к postgres, если нет данных из кэша
+	queryRes := []byte("")
+	if len(cacheResult.Data) == 0 {
+		var err error
+		queryRes, err = callPgFuncToJson(c.Request.Context(), method, jsonParam.Params)
+		if err != nil {
+			var timeoutErr *pg.PgTimeoutError
+			if errors.As(err, &timeoutErr) {
+				return fail(http.StatusGatewayTimeout, "timeout of method: "+jsonParam.Method)
+			}
+			return fail(http.StatusBadRequest, processPgErrorMsg(err))
+		}
+		// в случае если указан ключ для кэширования, сохраняем полученные данные из базы в кэш
+		if len(cacheResult.Key) > 0 {
+			pgFuncCache.Set(cacheResult.Key, queryRes, cacheResult.Duration, method.Tables...)
+		}
+	} else {
+		queryRes = cacheResult.Data
+	}
+	audit.done(gjson.GetBytes(queryRes, "ok").Bool(), gjson.GetBytes(queryRes, "message").String(), len(cacheResult.Data) > 0)
+
+	//// логгирование обращений к сайту
+	//{
+	//	u, _ := c.Get(common.GinContextUser)
+	//	user := u.(*model.User)
+	//	common.GraylogConfig.L().Infom(map[string]interface{}{"web_api_call": jsonParam.Method, "userId": user.Id, "username": user.Fullname}, fmt.Sprintf("%s. Is from cache: %v", jsonParam.Method, len(cacheResult.Data)))
+	//
+	//}
+
+	return queryRes, http.StatusOK, nil
+}
+
+// pgMethodResponse перекладываем ответ postgres функции в ответ сервера
+func pgMethodResponse(c *gin.Context, status int, queryRes []byte) {
+	c.JSON(status, gin.H{
+		"ok":        gjson.Get(fmt.Sprintf("%s", queryRes), "ok").Bool(),
+		"result":    gjson.Get(fmt.Sprintf("%s", queryRes), "result").Value(),
+		"message":   gjson.Get(fmt.Sprintf("%s", queryRes), "message").Value(),
+		"meta_info": gjson.Get(fmt.Sprintf("%s", queryRes), "meta_info").Value(),
+	})
+}
+
+// callPgFuncToJson вызов функции из pgFuncList. Параметры передаются в запрос как $1::jsonb, запрос прерывается
+// по таймауту метода или при отмене контекста запроса (клиент закрыл соединение)
+func callPgFuncToJson(ctx context.Context, method PgMethod, jsonMap interface{}) (queryRes []byte, err error) {
+	var jsonStr []byte
+
+	// по разному обрабатываем параметры запроса в зависмости от типа: может быть строка, а может быть map
+	switch v := jsonMap.(type) {
+	case nil:
+		jsonStr = []byte{}
+	case string:
+		if len(strings.TrimSpace(v)) > 0 {
+			jsonStr = []byte(v)
+		}
+	default:
+		if jsonStr, err = json.Marshal(v); err != nil {
+			return
+		}
+	}
+
+	timeout := method.Timeout
+	if timeout == 0 {
+		timeout = pg.DefaultCallTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return pg.CallRegisteredPgFuncContext(ctx, method.Title, jsonStr)
+}
+
+// registerPgFuncs регистрация методов pgFuncList в pg: вызвать через api можно только их
+func registerPgFuncs() {
+	for _, v := range pgFuncList {
+		pg.RegisterFuncs(v.Title)
+	}
+}
+
+func BeforeHookAddUserId(c *gin.Context, p interface{}) error {
+	jsonParam, ok := p.(JsonParamType)
+	if !ok {
+		return errors.New(fmt.Sprintf("BeforeHookAddUserId wrong type assertion %s not JsonParamType", p))
+	}
+	if jsonParam.Params == nil {
+		return errors.New(fmt.Sprintf("missed params in method %s", jsonParam.Method))
+	}
+	if u, ok := c.Get(utils.GinContextUser); ok {
+		user := u.(*types.User)
+		m, ok := jsonParam.Params.(map[string]interface{})
+		if !ok {
+			return errors.New(fmt.Sprintf("BeforeHookAddUserId wrong type assertion %s not map[string]interface{}", jsonParam.Params))
+		}
+		m["user_id"] = user.Id
+	}
+	return nil
+}
+
+func processPgErrorMsg (err error) string  {
+
+	return err.Error()
+}
\ No newline at end of file
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"fixture/src/cacheUtil"
+	"fixture/src/pg"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"net/http"
+	"time"
+)
+
+// Сессии пользователей (таблица user_session). При входе выдается короткий access токен (передается как auth_token)
+// и refresh токен, который меняется при каждом обновлении (/auth/refresh). В базе хранятся только sha256 хэши токенов.
+// Сессия кэшируется по хэшу access токена, кэш сбрасывается событием из postgres (notify_event на user_session и user),
+// поэтому выход, отзыв сессии администратором и изменение пользователя действуют сразу на всех экземплярах приложения
+
+const (
+	// максимальное время хранения сессии в кэше
+	sessionCacheTtl = time.Minute
+)
+
+var (
+	sessionConfig = sessionWithDefaults(types.AuthSession{})
+	sessionCache  = cacheUtil.NewCache("session", 10000)
+
+	// ErrInvalidToken токен не найден, сессия отозвана или пользователь удален
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+type (
+	sessionCacheItem struct {
+		user            *types.User
+		accessExpiredAt time.Time
+	}
+
+	// новая пара токенов сессии
+	sessionTokens struct {
+		access           string
+		refresh          string
+		accessExpiredAt  time.Time
+		refreshExpiredAt time.Time
+	}
+)
+
+// SetSessionConfig настройки сессий из секции authSession в config.toml
+func SetSessionConfig(config types.AuthSession) {
+	sessionConfig = sessionWithDefaults(config)
+}
+
+func sessionWithDefaults(config types.AuthSession) types.AuthSession {
+	if config.AccessTtl <= 0 {
+		config.AccessTtl = 900
+	}
+	if config.RefreshTtl <= 0 {
+		config.RefreshTtl = 30 * 24 * 3600
+	}
+	return config
+}
+
+// IsLegacyTokenAllowed признак что принимаются бессрочные токены из user_auth.auth_token
+func IsLegacyTokenAllowed() bool {
+	return sessionConfig.IsLegacyTokenAllowed
+}
+
+// IsSessionDisabled признак что сессии отключены (authSession.isDisabled): вход по бессрочному токену из user_auth.auth_token
+func IsSessionDisabled() bool {
+	return sessionConfig.IsDisabled
+}
+
+func newSessionTokens() (t sessionTokens, err error) {
+	if t.access, err = newSessionToken(); err != nil {
+		return
+	}
+	if t.refresh, err = newSessionToken(); err != nil {
+		return
+	}
+	now := time.Now()
+	t.accessExpiredAt = now.Add(time.Duration(sessionConfig.AccessTtl) * time.Second)
+	t.refreshExpiredAt = now.Add(time.Duration(sessionConfig.RefreshTtl) * time.Second)
+	return
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func sessionTokenHash(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// SessionStart создание сессии после успешного входа и отправка клиенту пользователя с токенами.
+// Название устройства передается в заголовке X-Device. Если сессии отключены, то отправляется пользователь с бессрочным токеном
+func SessionStart(c *gin.Context, user *types.User) {
+	if sessionConfig.IsDisabled {
+		// перед отправкой в данных пользователя стираем пароль
+		user.Password = ""
+		utils.HttpSuccess(c, user)
+		return
+	}
+	t, err := newSessionTokens()
+	if err != nil {
+		utils.HttpError(c, http.StatusInternalServerError, fmt.Sprintf("session token error: %s", err))
+		return
+	}
+	res := struct {
+		Id int64 `json:"id"`
+	}{}
+	err = pg.CallPgFuncWithStruct("user_session_create", map[string]interface{}{
+		"user_id":            user.Id,
+		"access_token_hash":  sessionTokenHash(t.access),
+		"refresh_token_hash": sessionTokenHash(t.refresh),
+		"access_expired_at":  t.accessExpiredAt,
+		"refresh_expired_at": t.refreshExpiredAt,
+		"device":             c.GetHeader("X-Device"),
+		"user_agent":         c.Request.UserAgent(),
+		"ip":                 c.ClientIP(),
+	}, &res)
+	if err != nil {
+		utils.HttpError(c, http.StatusOK, "pg call user_session_create err:"+err.Error())
+		return
+	}
+	// перед отправкой в данных пользователя стираем пароль
+	user.Password = ""
+	user.SessionId = res.Id
+	sessionSuccess(c, user, t)
+}
+
+// SessionRefresh новая пара токенов по refresh токену. Параметры: {"params": {"refresh_token": "..."}}
+func SessionRefresh(c *gin.Context) {
+	reqParams := struct {
+		Params struct {
+			RefreshToken string `json:"refresh_token"`
+		} `json:"params"`
+	}{}
+	if err := c.BindJSON(&reqParams); err != nil {
+		utils.HttpError(c, http.StatusOK, "post json params error:"+fmt.Sprintf("%s", err))
+		return
+	}
+	if len(reqParams.Params.RefreshToken) == 0 {
+		utils.HttpError(c, http.StatusOK, "missed refresh_token")
+		return
+	}
+	t, err := newSessionTokens()
+	if err != nil {
+		utils.HttpError(c, http.StatusInternalServerError, fmt.Sprintf("session token error: %s", err))
+		return
+	}
+	user := types.User{}
+	err = pg.CallPgFuncWithStruct("user_session_refresh", map[string]interface{}{
+		"refresh_token_hash":     sessionTokenHash(reqParams.Params.RefreshToken),
+		"new_access_token_hash":  sessionTokenHash(t.access),
+		"new_refresh_token_hash": sessionTokenHash(t.refresh),
+		"access_expired_at":      t.accessExpiredAt,
+		"refresh_expired_at":     t.refreshExpiredAt,
+		"user_agent":             c.Request.UserAgent(),
+		"ip":                     c.ClientIP(),
+	}, &user)
+	if err != nil {
+		utils.HttpError(c, http.StatusOK, err.Error())
+		return
+	}
+	// предыдущий access токен больше не действует. Событие из postgres сбросит кэш и на остальных экземплярах
+	sessionCache.InvalidateTags(cacheUtil.TableTag("user_session", user.SessionId))
+	sessionSuccess(c, &user, t)
+}
+
+func sessionSuccess(c *gin.Context, user *types.User, t sessionTokens) {
+	user.AuthToken = t.access
+	user.RefreshToken = t.refresh
+	user.AccessExpiresIn = sessionConfig.AccessTtl
+	utils.HttpSuccess(c, user)
+}
+
+// SessionFindByAccessToken пользователь по access токену. Возвращает ErrInvalidToken, если сессии нет или она отозвана,
+// и ошибку 'access token expired', если нужно обновить токен через /auth/refresh
+func SessionFindByAccessToken(token string) (*types.User, error) {
+	hash := sessionTokenHash(token)
+	if v, ok := sessionCache.Get(hash); ok {
+		item := v.(sessionCacheItem)
+		if time.Now().Before(item.accessExpiredAt) {
+			return sessionUser(item), nil
+		}
+		sessionCache.Delete(hash)
+	}
+
+	res := struct {
+		types.User
+		AccessExpiredAt time.Time `json:"access_expired_at"`
+	}{}
+	err := pg.CallPgFuncWithStruct("user_session_get_by_access_token", map[string]interface{}{"access_token_hash": hash}, &res)
+	if err != nil {
+		if fErr, ok := err.(*pg.PgFuncError); ok && fErr.Message == ErrInvalidToken.Error() {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+	item := sessionCacheItem{user: &res.User, accessExpiredAt: res.AccessExpiredAt}
+	item.user.AuthToken = token
+	ttl := sessionCacheTtl
+	if d := time.Until(res.AccessExpiredAt); d < ttl {
+		ttl = d
+	}
+	if ttl > 0 {
+		sessionCache.Set(hash, item, ttl, cacheUtil.TableTag("user_session", res.SessionId), cacheUtil.TableTag("user", res.Id))
+	}
+	return sessionUser(item), nil
+}
+
+// копия пользователя из кэша со временем до окончания действия токена
+func sessionUser(item sessionCacheItem) *types.User {
+	user := *item.user
+	user.AccessExpiresIn = int64(time.Until(item.accessExpiredAt).Seconds())
+	return &user
+}
+
+// Logout выход: отзыв текущей сессии
+func Logout(c *gin.Context) {
+	sessionRevoke(c, false)
+}
+
+// LogoutAll выход на всех устройствах: отзыв всех сессий текущего пользователя
+func LogoutAll(c *gin.Context) {
+	sessionRevoke(c, true)
+}
+
+func sessionRevoke(c *gin.Context, isAll bool) {
+	userId, ok := utils.ExtractUserIdInt64(c)
+	if !ok {
+		return
+	}
+	sessionId := c.GetInt64(utils.GinContextSessionId)
+	params := map[string]interface{}{"user_id": userId}
+	if !isAll {
+		if sessionId == 0 {
+			// вход по бессрочному токену из user_auth - отзывать нечего
+			utils.HttpSuccess(c, nil)
+			return
+		}
+		params["id"] = sessionId
+	}
+	err := pg.CallPgFuncWithStruct("current_user_session_revoke", params, nil)
+	if err != nil {
+		utils.HttpError(c, http.StatusOK, "pg call current_user_session_revoke err:"+err.Error())
+		return
+	}
+	// не дожидаемся события из postgres, чтобы следующий запрос с этим токеном уже не прошел
+	if isAll {
+		sessionCache.InvalidateTags(cacheUtil.TableTag("user", userId))
+	} else {
+		sessionCache.InvalidateTags(cacheUtil.TableTag("user_session", sessionId))
+	}
+	utils.HttpSuccess(c, nil)
+}
@@ -0,0 +1,63 @@
+package webServer
+
+import (
+	"github.com/gin-gonic/gin"
+	"fmt"
+	"fixture/src/logger"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"net/http"
+)
+
+// logFromClient запись в лог приложения с клиента. type: error, warn или info
+func logFromClient(c *gin.Context) {
+
+	var user *types.User
+
+	if u, ok := c.Get(utils.GinContextUser); ok {
+		user = u.(*types.User)
+	}
+
+	logMsg := struct {
+		Type string                 `json:"type"`
+		Code string                 `json:"code"`
+		Msg  map[string]interface{} `json:"msg"`
+	}{}
+
+	// извлекаем параметр page из json
+	// вариант когда пользователь авторизован и параметры уже извлечены и находятся в ContextJsonParamFldParam
+	if user != nil {
+		if ok := utils.ExtractPostReqParams(c, &logMsg); !ok {
+			return
+		}
+	} else {
+		// вариант когда пользователь неавторизован и передан только параметры
+		if err := c.BindJSON(&logMsg); err != nil {
+			utils.HttpError(c, http.StatusOK, "post json params error:"+fmt.Sprintf("%s", err))
+			return
+		}
+	}
+
+	msgAttrs := logger.Fields{"source": "client", "type": logMsg.Code}
+	if user != nil {
+		msgAttrs["fullname"] = user.Fullname
+	}
+	for k, v := range logMsg.Msg {
+		// request_id и user_id берутся из запроса, клиент их не подменяет
+		if k == "request_id" || k == "user_id" {
+			continue
+		}
+		msgAttrs[k] = v
+	}
+
+	l := utils.RequestLog(c).With(msgAttrs)
+	switch logMsg.Type {
+	case "error":
+		l.Errorf("%s", logMsg.Msg)
+	case "warn":
+		l.Warnf("%s", logMsg.Msg)
+	default:
+		l.Infof("%s", logMsg.Msg)
+	}
+	utils.HttpSuccess(c, "ok")
+}
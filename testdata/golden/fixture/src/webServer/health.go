@@ -0,0 +1,47 @@
+package webServer
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"fixture/src/lifecycle"
+	"fixture/src/pg"
+	"net/http"
+	"time"
+)
+
+// healthz проверка что приложение работает и есть соединение с postgres. Используется в healthcheck docker-compose
+func healthz(c *gin.Context) {
+	checks := gin.H{"postgres": healthCheckPostgres(c.Request.Context())}
+	healthResponse(c, checks)
+}
+
+// readyz готовность принимать запросы: есть соединение с postgres, слушаются события postgres и приложение не останавливается
+func readyz(c *gin.Context) {
+	checks := gin.H{"postgres": healthCheckPostgres(c.Request.Context()), "listener": "ok", "lifecycle": "ok"}
+	if !pg.IsListenerConnected() {
+		checks["listener"] = "not connected"
+	}
+	if lifecycle.IsStopping() {
+		checks["lifecycle"] = "stopping"
+	}
+	healthResponse(c, checks)
+}
+
+func healthCheckPostgres(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := pg.Pg.PingContext(ctx); err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+func healthResponse(c *gin.Context, checks gin.H) {
+	status := http.StatusOK
+	for _, v := range checks {
+		if v != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(status, gin.H{"ok": status == http.StatusOK, "checks": checks})
+}
@@ -0,0 +1,134 @@
+package webServer
+
+import (
+	"fixture/src/lifecycle"
+	"fixture/src/sse"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"fixture/src/webServer/auth"
+	"github.com/gin-gonic/gin"
+
+
+	
+	"net/http"
+	"os"
+	"fmt"
+)
+
+// StartWebServer запуск веб-сервера. Не блокирует, остановка - через lifecycle
+func StartWebServer(config types.Config) {
+	r := gin.New()
+	// ip клиента (в том числе для ограничения запросов по ip) берется из X-Forwarded-For только от доверенных прокси
+	var trustedProxies []string
+	if len(config.WebServer.TrustedProxies) > 0 {
+		trustedProxies = config.WebServer.TrustedProxies
+	}
+	utils.CheckErr(r.SetTrustedProxies(trustedProxies), "webServer.trustedProxies")
+	// request_id и запись о каждом запросе в лог
+	r.Use(requestId, requestLog)
+
+	// передаем конфиги для модуля авторизации
+	auth.SetWebServerConfig(config.WebServer)
+	auth.SetRateLimitConfig(config.AuthRateLimit)
+	auth.SetSessionConfig(config.AuthSession)
+	// журнал вызовов pg методов
+	startApiAudit(config.ApiAudit)
+	// через api вызываются только методы из pgFuncList
+	registerPgFuncs()
+	// подписка на топики документов по ролям (DocType.Realtime)
+	sse.SetTopicAuthorizer(realtimeTopicAuthorizer)
+
+	// CORS по настройкам webServer.cors. В dev режиме webClient запускается на другом порту, поэтому разрешаем любые источники
+	if os.Getenv("IS_DEVELOPMENT") == "true" {
+		r.Use(LiberalCORS)
+	} else {
+		r.Use(Cors(config.WebServer))
+	}
+	r.Use(SecurityHeaders(config.WebServer))
+	r.Static("/stat-img", "../image")
+	r.Static("/static", "./webClient/dist")
+	r.Static("/statics", "./webClient/dist/statics")
+	r.StaticFile("/", "./webClient/dist/index.html")
+	// проверки для healthcheck docker-compose и балансировщика
+	r.GET("/healthz", healthz)
+	r.GET("/readyz", readyz)
+	// описание api в формате OpenAPI 3
+	r.StaticFile("/openapi.json", "./webServer/openapi.json")
+
+	// АВТОРИЗАЦИЯ. Количество запросов ограничено по ip, email и телефону (auth.RateLimit*)
+	authRoute := r.Group("/auth", auth.RateLimitByIp)
+	{
+		// авторизация через email
+		authRoute.POST("/email", auth.RateLimitByLogin, auth.EmailAuth)
+		authRoute.POST("/check_user_email", auth.EmailAuthCheckUserEmail)
+		authRoute.POST("/email_auth_start_recover_password", auth.RateLimitByLogin, auth.EmailAuthStartRecoverPassword)
+		authRoute.POST("/email_auth_recover_password", auth.EmailAuthRecoverPassword)
+		// обновление токенов сессии
+		authRoute.POST("/refresh", auth.SessionRefresh)
+		
+	}
+
+	apiRoute := r.Group("/api", authRequired)
+	{
+		apiRoute.POST("/current_user", apiCurrentUser)
+		apiRoute.POST("/call_pg_func", apiCallPgFunc)
+		// выход: отзыв текущей сессии или всех сессий пользователя
+		apiRoute.POST("/logout", auth.Logout)
+		apiRoute.POST("/logout_all", auth.LogoutAll)
+		// запись в лог приложения с клиента
+		apiRoute.POST("/log", logFromClient)
+		// подключение по SSE
+		apiRoute.GET("/sse", sse.AddConn)
+		// websocket: события как в SSE и вызов pg методов
+		apiRoute.GET("/ws", wsHandler(config.WebServer))
+		// операции с файлами
+		apiRoute.POST("/upload_file", uploadFile)
+		apiRoute.GET("/file/:fileToken", downloadFile)
+		apiRoute.POST("/remove_file/:fileToken", deleteFile)
+		// загрузка фото
+		apiRoute.POST("/upload_image", uploadImage)
+		apiRoute.POST("/upload_profile_image", uploadProfileImage)
+		
+
+		
+
+		
+	}
+
+	// REST api документов. Авторизация отдельная от /api, так как в теле запроса передается документ, а не JsonParamType
+	restRoute := r.Group("/api/v1", restAuthRequired)
+	addRestRoutes(restRoute)
+
+	
+
+	
+
+
+	// на ненайденный url отправляем статический файл для запуска vuejs приложения
+	r.NoRoute(func(c *gin.Context) {
+		http.ServeFile(c.Writer, c.Request, "./webClient/dist/index.html")
+	})
+
+	srv := &http.Server{Addr: fmt.Sprintf(":%v", config.WebServer.Port), Handler: r}
+	go func() {
+		err := srv.ListenAndServe()
+		if err != http.ErrServerClosed {
+			utils.CheckErr(err, "webserver run")
+		}
+	}()
+	// при остановке: сначала закрываются SSE соединения, затем веб-сервер дожидается выполнения текущих запросов
+	lifecycle.Register("web server", srv.Shutdown)
+	lifecycle.Register("sse", sse.CloseAll)
+}
+
+func apiCurrentUser(c *gin.Context) {
+	if u, ok := c.Get(utils.GinContextUser); ok {
+		if user, ok := u.(*types.User); ok {
+			utils.HttpSuccess(c, user)
+		} else {
+			utils.HttpError(c, http.StatusNonAuthoritativeInfo, "user problem cast to model.User")
+		}
+	} else {
+		utils.HttpError(c, http.StatusNonAuthoritativeInfo, "user not found in gin.context")
+	}
+}
\ No newline at end of file
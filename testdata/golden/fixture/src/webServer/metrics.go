@@ -0,0 +1,118 @@
+package webServer
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"github.com/gin-gonic/gin"
+	"fixture/src/cacheUtil"
+	"fixture/src/metrics"
+	"fixture/src/pg"
+	"fixture/src/sse"
+	"fixture/src/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	httpRequestsTotal   = metrics.NewCounterVec("http_requests_total", "HTTP requests by route and status", "method", "route", "status")
+	httpRequestDuration = metrics.NewHistogramVec("http_request_duration_seconds", "HTTP request latency by route", nil, "method", "route")
+	pgMethodDuration    = metrics.NewHistogramVec("pg_method_duration_seconds", "Latency of pg methods called via api. source: db or cache", nil, "method", "source")
+	pgMethodErrorsTotal = metrics.NewCounterVec("pg_method_errors_total", "Failed pg method calls via api", "method")
+)
+
+// startMetrics включение сбора метрик и регистрация метрик, которые вычисляются при запросе /metrics
+func startMetrics() {
+	metrics.SetEnabled(true)
+
+	cacheStats := func(f func(s cacheUtil.CacheStats) float64) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			res := []metrics.Sample{}
+			for _, s := range cacheUtil.AllCacheStats() {
+				res = append(res, metrics.Sample{LabelValues: []string{s.Name}, Value: f(s)})
+			}
+			return res
+		}
+	}
+	cacheLabels := []string{"cache"}
+	metrics.AddFunc("cache_hits_total", "Cache hits", metrics.KindCounter, cacheLabels, cacheStats(func(s cacheUtil.CacheStats) float64 { return float64(s.Hits) }))
+	metrics.AddFunc("cache_misses_total", "Cache misses", metrics.KindCounter, cacheLabels, cacheStats(func(s cacheUtil.CacheStats) float64 { return float64(s.Misses) }))
+	metrics.AddFunc("cache_evictions_total", "Cache evictions", metrics.KindCounter, cacheLabels, cacheStats(func(s cacheUtil.CacheStats) float64 { return float64(s.Evictions) }))
+	metrics.AddFunc("cache_size", "Cache entries", metrics.KindGauge, cacheLabels, cacheStats(func(s cacheUtil.CacheStats) float64 { return float64(s.Size) }))
+	metrics.AddFunc("cache_hit_ratio", "Cache hits / (hits + misses) since start", metrics.KindGauge, cacheLabels, cacheStats(func(s cacheUtil.CacheStats) float64 {
+		if s.Hits+s.Misses == 0 {
+			return 0
+		}
+		return float64(s.Hits) / float64(s.Hits+s.Misses)
+	}))
+
+	metrics.AddFunc("sse_clients", "Connected SSE and websocket clients", metrics.KindGauge, nil, func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(sse.ConnectedClients())}}
+	})
+	metrics.AddFunc("sse_dropped_events_total", "SSE events not queued because the client buffer was full", metrics.KindCounter, nil, func() []metrics.Sample {
+		return []metrics.Sample{{Value: float64(sse.DroppedEvents())}}
+	})
+
+	pgStats := func(f func(s sql.DBStats) float64) func() []metrics.Sample {
+		return func() []metrics.Sample {
+			return []metrics.Sample{{Value: f(pg.Pg.Stats())}}
+		}
+	}
+	metrics.AddFunc("pg_pool_max_open_connections", "Maximum number of open connections to postgres", metrics.KindGauge, nil, pgStats(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	metrics.AddFunc("pg_pool_open_connections", "Open connections to postgres", metrics.KindGauge, nil, pgStats(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	metrics.AddFunc("pg_pool_in_use_connections", "Connections currently in use", metrics.KindGauge, nil, pgStats(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	metrics.AddFunc("pg_pool_idle_connections", "Idle connections", metrics.KindGauge, nil, pgStats(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	metrics.AddFunc("pg_pool_wait_count_total", "Connections waited for", metrics.KindCounter, nil, pgStats(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	metrics.AddFunc("pg_pool_wait_duration_seconds_total", "Time blocked waiting for a connection", metrics.KindCounter, nil, pgStats(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+}
+
+// metricsMiddleware количество и время выполнения запросов по роутам
+func metricsMiddleware(c *gin.Context) {
+	start := time.Now()
+	c.Next()
+	route := c.FullPath()
+	if len(route) == 0 {
+		route = "unmatched"
+	}
+	httpRequestsTotal.Inc(c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+	// SSE соединение длится до отключения клиента, его время в latency не учитываем
+	if c.Writer.Header().Get("Content-Type") != "text/event-stream" {
+		httpRequestDuration.Observe(time.Since(start).Seconds(), c.Request.Method, route)
+	}
+}
+
+// metricsHandler отдача метрик prometheus. Если указан token, то нужен заголовок Authorization: Bearer <token>
+func metricsHandler(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(token) > 0 && subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+			utils.HttpError(c, http.StatusUnauthorized, "wrong metrics token")
+			return
+		}
+		c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.Status(http.StatusOK)
+		metrics.WritePrometheus(c.Writer)
+	}
+}
+
+// время вызова pg метода и ошибки (запись apiAuditCall заполнена в callPgMethod)
+func observePgMethod(a *apiAuditCall) {
+	if !metrics.IsEnabled() {
+		return
+	}
+	// название метода из запроса попадает в метку только если метод есть в pgFuncList, иначе количество меток не ограничено
+	method := "unknown"
+	for _, v := range pgFuncList {
+		if v.Title == a.jsonParam.Method {
+			method = v.Title
+			break
+		}
+	}
+	source := "db"
+	if a.isCache {
+		source = "cache"
+	}
+	pgMethodDuration.Observe(time.Since(a.startedAt).Seconds(), method, source)
+	if !a.ok {
+		pgMethodErrorsTotal.Inc(method)
+	}
+}
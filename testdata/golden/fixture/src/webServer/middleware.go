@@ -0,0 +1,140 @@
+package webServer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"fixture/src/cacheUtil"
+	"fixture/src/pg"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"fixture/src/webServer/auth"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// проверка токена авторизации
+func authRequired(c *gin.Context) {
+	//println("in AuthRequired", c.Request.Method)
+	// ищем auth_token
+	// вначале ищем в header'е запроса
+	authToken := c.Request.Header.Get("Auth-token")
+
+	contentType := c.Request.Header.Get("Content-Type")
+
+	// вариант обработки POST запроса
+	if c.Request.Method == "POST" {
+		// если это не загрузка файлов (тогда в запросе нет json параметров)
+		if !strings.Contains(contentType, "multipart/form-data") {
+			var json JsonParamType
+			//println("AuthRequired Auth-token:", c.Request.Header.Get("Auth-token"))
+			// извлекаем json-параметры запроса
+			if err := c.BindJSON(&json); err != nil {
+				//println("AuthRequired step1.1", fmt.Sprintf("%s", err))
+				utils.HttpError(c, http.StatusOK, "post json params error:"+fmt.Sprintf("%s", err))
+				return
+			}
+			// если не находим, то смотрим в json-параметрах запроса
+			if len(authToken) == 0 {
+				authToken = json.AuthToken
+			}
+			c.Set(utils.ContextJsonParam, json)
+			c.Set(utils.ContextJsonParamFldParam, json.Params)
+		}
+	}
+
+	// в случае GET запроса смотрим токен в query параметрах
+	if c.Request.Method == "GET" {
+		if len(authToken) == 0 {
+			authToken = c.Query("authToken")
+		}
+		// точно используется при вебсокет соединении, когда отправляется первоначальный запрос
+		c.Set(utils.GinContextGetRequestQueryId, c.Query("id"))
+	}
+	// если токен не найден, то возвращаем ошибку
+	if len(authToken) == 0 {
+		utils.HttpError(c, http.StatusOK, "missed auth_token")
+		return
+	}
+
+	user, err := userFindByToken(authToken)
+	if err != nil {
+		utils.HttpError(c, http.StatusOK, fmt.Sprintf("%s", err))
+		return
+	}
+	c.Set(utils.GinContextUser, user)
+	c.Set(utils.GinContextUserId, user.Id)
+	c.Set(utils.GinContextSessionId, user.SessionId)
+}
+
+// проверка токена авторизации для REST api (/api/v1). В отличие от authRequired тело запроса не читается - в нем передается документ.
+// Токен ищется в header'ах Auth-token и Authorization: Bearer, затем в query параметре authToken
+func restAuthRequired(c *gin.Context) {
+	authToken := c.Request.Header.Get("Auth-token")
+	if len(authToken) == 0 {
+		if h := c.Request.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			authToken = strings.TrimPrefix(h, "Bearer ")
+		}
+	}
+	if len(authToken) == 0 {
+		authToken = c.Query("authToken")
+	}
+	if len(authToken) == 0 {
+		utils.HttpError(c, http.StatusUnauthorized, "missed auth_token")
+		return
+	}
+
+	user, err := userFindByToken(authToken)
+	if err != nil {
+		utils.HttpError(c, http.StatusUnauthorized, fmt.Sprintf("%s", err))
+		return
+	}
+	c.Set(utils.GinContextUser, user)
+	c.Set(utils.GinContextUserId, user.Id)
+	c.Set(utils.GinContextSessionId, user.SessionId)
+}
+
+// LiberalCORS is a very allowing CORS middleware. Используется в dev режиме, в production - Cors
+func LiberalCORS(c *gin.Context) {
+	c.Header("Access-Control-Allow-Origin", "*")
+	if c.Request.Method == "OPTIONS" {
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+		if len(c.Request.Header["Access-Control-Request-Headers"]) > 0 {
+			c.Header("Access-Control-Allow-Headers", c.Request.Header["Access-Control-Request-Headers"][0])
+		}
+		c.AbortWithStatus(http.StatusOK)
+	}
+}
+
+// поиск пользователя по access токену сессии (auth.SessionFindByAccessToken). Если разрешено в authSession.isLegacyTokenAllowed,
+// то затем по бессрочному токену из user_auth. Если сессии отключены - только по бессрочному токену
+func userFindByToken(token string) (*types.User, error) {
+	if auth.IsSessionDisabled() {
+		return userFindByAuthToken(token)
+	}
+	user, err := auth.SessionFindByAccessToken(token)
+	if err == auth.ErrInvalidToken && auth.IsLegacyTokenAllowed() {
+		return userFindByAuthToken(token)
+	}
+	return user, err
+}
+
+func userFindByAuthToken(token string) (user *types.User, err error) {
+	// ищем пользователя в кэше
+	userIntreface, _ := cacheUtil.GoCacheGet(cacheUtil.GetCacheKeyUserToken(token))
+	if userIntreface != nil {
+		var ok bool
+		user, ok = userIntreface.(*types.User)
+		if ok {
+			return
+		}
+	}
+	jsonStr, _ := json.Marshal(map[string]interface{}{"auth_token": token})
+	err = pg.CallPgFunc("user_get_by_auth_token", jsonStr, &user, nil)
+	// записываем в кэш
+	if err == nil {
+		cacheUtil.GoCacheSet(cacheUtil.GetCacheKeyUserToken(token), user, time.Minute*1)
+	}
+	return
+}
\ No newline at end of file
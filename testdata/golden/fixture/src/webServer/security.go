@@ -0,0 +1,130 @@
+package webServer
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"fixture/src/types"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Cors CORS по настройкам из секции webServer.cors в config.toml. Если источники не указаны, то разрешен только webServer.url
+func Cors(config types.WebServer) gin.HandlerFunc {
+	cors := config.Cors
+	cors.AllowOrigins = corsAllowOrigins(config)
+	if len(cors.AllowMethods) == 0 {
+		cors.AllowMethods = []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(cors.AllowHeaders) == 0 {
+		cors.AllowHeaders = []string{"Content-Type", "Auth-token", "Authorization"}
+	}
+	if cors.MaxAge <= 0 {
+		cors.MaxAge = 600
+	}
+	allowMethods := strings.Join(cors.AllowMethods, ", ")
+	allowHeaders := strings.Join(cors.AllowHeaders, ", ")
+	maxAge := strconv.FormatInt(cors.MaxAge, 10)
+	isAnyOrigin := false
+	for _, o := range cors.AllowOrigins {
+		if o == "*" {
+			isAnyOrigin = true
+		}
+	}
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if len(origin) == 0 {
+			return
+		}
+		c.Header("Vary", "Origin")
+		if !isCorsOriginAllowed(cors.AllowOrigins, origin) {
+			// запросы с того же домена браузер выполнит и без CORS заголовков, остальные будут заблокированы браузером
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+			}
+			return
+		}
+		// с credentials браузер не принимает "*", поэтому в этом случае возвращаем сам источник
+		if isAnyOrigin && !cors.IsAllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cors.IsAllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if c.Request.Method == http.MethodOptions {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			c.Header("Access-Control-Max-Age", maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+		}
+	}
+}
+
+// разрешенные источники из webServer.cors.allowOrigins, по умолчанию - webServer.url
+func corsAllowOrigins(config types.WebServer) []string {
+	if len(config.Cors.AllowOrigins) > 0 {
+		return config.Cors.AllowOrigins
+	}
+	if u, err := url.Parse(config.Url); err == nil && len(u.Scheme) > 0 && len(u.Host) > 0 {
+		return []string{u.Scheme + "://" + u.Host}
+	}
+	return nil
+}
+
+// источник разрешен, если совпадает полностью, указана "*" или совпадает по маске поддомена "https://*.site.ru"
+func isCorsOriginAllowed(allowOrigins []string, origin string) bool {
+	for _, o := range allowOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+		if i := strings.Index(o, "://*."); i > 0 {
+			prefix := o[:i+3]
+			suffix := o[i+4:]
+			if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) && len(origin) > len(prefix)+len(suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SecurityHeaders заголовки безопасности по настройкам из секции webServer.securityHeaders в config.toml.
+// Content-Security-Policy проставляется только для статики webClient, Strict-Transport-Security - только если webServer.url https
+func SecurityHeaders(config types.WebServer) gin.HandlerFunc {
+	sh := config.SecurityHeaders
+	if sh.IsDisabled {
+		return func(c *gin.Context) {}
+	}
+	if len(sh.FrameOptions) == 0 {
+		sh.FrameOptions = "DENY"
+	}
+	if len(sh.ReferrerPolicy) == 0 {
+		sh.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	if sh.HstsMaxAge <= 0 {
+		sh.HstsMaxAge = 31536000
+	}
+	hsts := ""
+	if strings.HasPrefix(config.Url, "https://") {
+		hsts = fmt.Sprintf("max-age=%v; includeSubDomains", sh.HstsMaxAge)
+	}
+	return func(c *gin.Context) {
+		h := c.Writer.Header()
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", sh.FrameOptions)
+		h.Set("Referrer-Policy", sh.ReferrerPolicy)
+		if len(hsts) > 0 {
+			h.Set("Strict-Transport-Security", hsts)
+		}
+		if len(sh.Csp) > 0 && !isApiPath(c.Request.URL.Path) {
+			h.Set("Content-Security-Policy", sh.Csp)
+		}
+	}
+}
+
+func isApiPath(path string) bool {
+	return strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/auth/")
+}
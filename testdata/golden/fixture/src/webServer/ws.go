@@ -0,0 +1,281 @@
+package webServer
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/tidwall/gjson"
+	"fixture/src/logger"
+	"fixture/src/sse"
+	"fixture/src/types"
+	"fixture/src/utils"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Websocket /api/ws: события из тех же топиков, что и /api/sse, и вызов pg методов из pgFuncList с теми же проверками ролей.
+// Авторизация как в authRequired - токен в query параметре authToken. Параметры topics и lastEventId как в /api/sse.
+// Сообщения в формате JSON:
+//   клиент: {"id": 1, "type": "call", "method": "task_list", "params": {...}}
+//           {"id": 2, "type": "subscribe", "topic": "doc:task:5"}
+//           {"id": 3, "type": "unsubscribe", "topic": "doc:task:5"}
+//   сервер: {"id": 1, "type": "result", "ok": true, "result": ..., "message": ..., "meta_info": ...}
+//           {"type": "event", "event_id": "...", "event": "...", "topic": "...", "data": ...}
+//           {"type": "reset"} - часть событий после lastEventId потеряна, нужно перечитать данные
+// Когда токен перестает действовать (истек или сессия отозвана), соединение закрывается с кодом 4001,
+// клиент переподключается с новым токеном и получает пропущенные события
+
+const (
+	wsWriteTimeout     = 10 * time.Second
+	wsMaxMessageSize   = 4 << 20
+	wsMaxParallelCalls = 8
+	wsSendBufferSize   = 64
+	// код закрытия соединения, если токен больше не действует
+	wsCloseTokenInvalid = 4001
+)
+
+type (
+	wsRequest struct {
+		Id     int64       `json:"id"`
+		Type   string      `json:"type"`
+		Method string      `json:"method"`
+		Params interface{} `json:"params"`
+		Topic  string      `json:"topic"`
+	}
+
+	wsMessage struct {
+		Id       int64           `json:"id,omitempty"`
+		Type     string          `json:"type"`
+		Ok       bool            `json:"ok,omitempty"`
+		Result   interface{}     `json:"result,omitempty"`
+		Message  interface{}     `json:"message,omitempty"`
+		MetaInfo interface{}     `json:"meta_info,omitempty"`
+		EventId  string          `json:"event_id,omitempty"`
+		Event    string          `json:"event,omitempty"`
+		Topic    string          `json:"topic,omitempty"`
+		Data     json.RawMessage `json:"data,omitempty"`
+	}
+
+	// wsConn одно websocket соединение. Писать в conn может только горутина run, остальные передают сообщения через out
+	wsConn struct {
+		c      *gin.Context
+		conn   *websocket.Conn
+		userId string
+		token  string
+		sub    *sse.Subscriber
+		out    chan *wsMessage
+		// закрывается при завершении run, после этого сообщения в out не принимаются
+		closed chan struct{}
+	}
+)
+
+// wsHandler обработчик /api/ws. Подключение разрешено с источников из webServer.cors (как для CORS), в dev режиме - с любого
+func wsHandler(config types.WebServer) gin.HandlerFunc {
+	allowOrigins := corsAllowOrigins(config)
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if len(origin) == 0 || os.Getenv("IS_DEVELOPMENT") == "true" {
+				return true
+			}
+			if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+				return true
+			}
+			return isCorsOriginAllowed(allowOrigins, origin)
+		},
+	}
+	return func(c *gin.Context) {
+		u, _ := c.Get(utils.GinContextUser)
+		user := u.(*types.User)
+		userId := user.IdString()
+		topics, status, err := sse.RequestTopics(c, userId)
+		if err != nil {
+			utils.HttpError(c, status, err.Error())
+			return
+		}
+		token := c.Query("authToken")
+		if len(token) == 0 {
+			token = c.GetHeader("Auth-token")
+		}
+
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// ответ с ошибкой уже отправлен upgrader'ом
+			utils.RequestLog(c).Warnf("websocket upgrade error: %s", err)
+			return
+		}
+		// вызовы pg методов прерываются при закрытии соединения. Горутинам вызовов передается копия контекста,
+		// так как после выхода из обработчика gin использует контекст для других запросов
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		sub, missed, isGap := sse.NewSubscriber(userId, topics, sse.RequestLastEventId(c))
+		ws := &wsConn{
+			c:      c.Copy(),
+			conn:   conn,
+			userId: userId,
+			token:  token,
+			sub:    sub,
+			out:    make(chan *wsMessage, wsSendBufferSize),
+			closed: make(chan struct{}),
+		}
+		ws.run(missed, isGap)
+	}
+}
+
+// run отправка сообщений клиенту. Чтение запросов - в отдельной горутине
+func (ws *wsConn) run(missed []*sse.Event, isGap bool) {
+	defer ws.sub.Close()
+	defer ws.conn.Close()
+	defer close(ws.closed)
+
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		ws.readLoop()
+	}()
+
+	if isGap {
+		if ws.write(&wsMessage{Type: "reset"}) != nil {
+			return
+		}
+	}
+	for _, ev := range missed {
+		if ws.write(wsEventMessage(ev)) != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sse.HeartbeatInterval())
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case ev := <-ws.sub.Events():
+			err = ws.write(wsEventMessage(ev))
+		case msg := <-ws.out:
+			err = ws.write(msg)
+		case <-heartbeat.C:
+			if _, tokenErr := userFindByToken(ws.token); tokenErr != nil {
+				ws.closeWith(wsCloseTokenInvalid, tokenErr.Error())
+				return
+			}
+			err = ws.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout))
+		case <-ws.sub.Kicked():
+			logger.Warnf("websocket client of user %s is too slow, disconnected", ws.userId)
+			ws.closeWith(websocket.CloseTryAgainLater, "too slow")
+			return
+		case <-readDone:
+			return
+		case <-sse.Shutdown():
+			// приложение останавливается - клиент переподключится к новому экземпляру
+			ws.closeWith(websocket.CloseGoingAway, "server shutdown")
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (ws *wsConn) write(msg *wsMessage) error {
+	_ = ws.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return ws.conn.WriteJSON(msg)
+}
+
+func (ws *wsConn) closeWith(code int, text string) {
+	_ = ws.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(wsWriteTimeout))
+}
+
+// readLoop чтение запросов клиента. Вызовы pg методов выполняются параллельно (не более wsMaxParallelCalls)
+func (ws *wsConn) readLoop() {
+	readTimeout := 2*sse.HeartbeatInterval() + wsWriteTimeout
+	ws.conn.SetReadLimit(wsMaxMessageSize)
+	_ = ws.conn.SetReadDeadline(time.Now().Add(readTimeout))
+	ws.conn.SetPongHandler(func(string) error {
+		return ws.conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+
+	calls := make(chan struct{}, wsMaxParallelCalls)
+	for {
+		_, data, err := ws.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = ws.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		var req wsRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			ws.reply(&wsMessage{Type: "result", Message: "wrong json: " + err.Error()})
+			continue
+		}
+		switch req.Type {
+		case "call":
+			select {
+			case calls <- struct{}{}:
+			case <-ws.closed:
+				return
+			}
+			go func() {
+				defer func() { <-calls }()
+				ws.reply(ws.call(req))
+			}()
+		case "subscribe":
+			if !sse.IsTopicAllowed(ws.c, ws.userId, req.Topic) {
+				ws.reply(&wsMessage{Id: req.Id, Type: "result", Message: "topic " + req.Topic + " not allowed"})
+				continue
+			}
+			ws.sub.Subscribe(req.Topic)
+			ws.reply(&wsMessage{Id: req.Id, Type: "result", Ok: true})
+		case "unsubscribe":
+			// от событий своего пользователя не отписываемся
+			if req.Topic != sse.UserTopic(ws.userId) {
+				ws.sub.Unsubscribe(req.Topic)
+			}
+			ws.reply(&wsMessage{Id: req.Id, Type: "result", Ok: true})
+		default:
+			ws.reply(&wsMessage{Id: req.Id, Type: "result", Message: "unknown message type: " + req.Type})
+		}
+	}
+}
+
+// call вызов pg метода с теми же проверками, что и в /api/call_pg_func
+func (ws *wsConn) call(req wsRequest) *wsMessage {
+	if len(req.Method) == 0 {
+		return &wsMessage{Id: req.Id, Type: "result", Message: "missed method name"}
+	}
+	queryRes, _, err := execPgMethod(ws.c, JsonParamType{Method: req.Method, Params: req.Params})
+	if err != nil {
+		return &wsMessage{Id: req.Id, Type: "result", Message: err.Error()}
+	}
+	return &wsMessage{
+		Id:       req.Id,
+		Type:     "result",
+		Ok:       gjson.GetBytes(queryRes, "ok").Bool(),
+		Result:   gjson.GetBytes(queryRes, "result").Value(),
+		Message:  gjson.GetBytes(queryRes, "message").Value(),
+		MetaInfo: gjson.GetBytes(queryRes, "meta_info").Value(),
+	}
+}
+
+func (ws *wsConn) reply(msg *wsMessage) {
+	select {
+	case ws.out <- msg:
+	case <-ws.closed:
+	}
+}
+
+func wsEventMessage(ev *sse.Event) *wsMessage {
+	msg := &wsMessage{Type: "event", EventId: ev.EventId(), Event: ev.Name, Topic: ev.Topic}
+	if json.Valid([]byte(ev.Data)) {
+		msg.Data = json.RawMessage(ev.Data)
+	} else {
+		msg.Data, _ = json.Marshal(ev.Data)
+	}
+	return msg
+}
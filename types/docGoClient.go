@@ -0,0 +1,175 @@
+package types
+
+import (
+	"fmt"
+	"github.com/serenize/snaker"
+	"strings"
+)
+
+// колонки, которые есть в каждой таблице документа и печатаются в структуре отдельно
+var goClientBaseFlds = []string{"id", "options", "created_at", "updated_at", "deleted"}
+
+// GoClientType тип поля в структуре go клиента
+func (fld *FldType) GoClientType() string {
+	switch fld.Type {
+	case FldTypeString, FldTypeText:
+		return "string"
+	case FldTypeJsonb:
+		return "Json"
+	default:
+		return fld.GoType()
+	}
+}
+
+// PrintGoClient печать структуры документа и типизированных функций для вызова его postgres функций (pgClient/docs.go)
+func (d DocType) PrintGoClient() string {
+	name := d.NameCamelCase()
+	arr := []string{fmt.Sprintf("%s struct {", name)}
+	arr = append(arr, "\tId int64 `json:\"id\"`")
+	optionFlds := []FldType{}
+	for _, fld := range d.Flds {
+		if len(fld.Name) == 0 || fld.Type == FldTypeVueComposition || goClientIsBaseFld(fld.Name) {
+			continue
+		}
+		if fld.Sql.IsOptionFld {
+			optionFlds = append(optionFlds, fld)
+			continue
+		}
+		arr = append(arr, fmt.Sprintf("\t%s %s `json:\"%s\"` // %s", snaker.SnakeToCamel(fld.Name), fld.GoClientType(), fld.Name, fld.NameRu))
+		// для ссылок get_by_id возвращает title связанной записи
+		if len(fld.Sql.Ref) > 0 {
+			titleName := strings.TrimSuffix(fld.Name, "_id") + "_title"
+			arr = append(arr, fmt.Sprintf("\t%s string `json:\"%s,omitempty\"`", snaker.SnakeToCamel(titleName), titleName))
+		}
+	}
+	arr = append(arr,
+		"\tOptions map[string]interface{} `json:\"options,omitempty\"`",
+		"\tCreatedAt string `json:\"created_at,omitempty\"`",
+		"\tUpdatedAt string `json:\"updated_at,omitempty\"`",
+		"\tDeleted bool `json:\"deleted\"`",
+		"}",
+	)
+	res := fmt.Sprintf("// %s - %s\ntype %s\n", name, d.NameRu, strings.Join(arr, "\n"))
+
+	// поля, которые хранятся в options
+	for _, fld := range optionFlds {
+		res += fmt.Sprintf(`
+// %[3]s %[4]s (хранится в options)
+func (d %[1]s) %[3]s() (res %[2]s) {
+	optionValue(d.Options, "%[5]s", &res)
+	return
+}
+
+func (d *%[1]s) Set%[3]s(v %[2]s) {
+	if d.Options == nil {
+		d.Options = map[string]interface{}{}
+	}
+	d.Options["%[5]s"] = v
+}
+`, name, fld.GoClientType(), snaker.SnakeToCamel(fld.Name), fld.NameRu, fld.Name)
+	}
+
+	res += fmt.Sprintf(`
+// %[1]sGet запись по id
+func %[1]sGet(userId, id int64) (*%[1]s, error) {
+	res := &%[1]s{}
+	err := call("%[2]s_get_by_id", map[string]interface{}{"id": id, "user_id": userId}, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// %[1]sList список записей по фильтру
+func %[1]sList(userId int64, filter ListFilter) ([]%[1]s, error) {
+	res := []%[1]s{}
+	err := call("%[2]s_list", filter.params(userId), &res)
+	return res, err
+}
+
+// %[1]sUpdate создание (если Id = 0) или обновление записи. Возвращает сохраненную запись
+func %[1]sUpdate(userId int64, doc %[1]s) (*%[1]s, error) {
+	params, err := updateParams(userId, doc.Id, doc)
+	if err != nil {
+		return nil, err
+	}
+	res := &%[1]s{}
+	err = call("%[2]s_update", params, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// %[1]sDelete пометка записи как удаленной
+func %[1]sDelete(userId, id int64) error {
+	return call("%[2]s_update", map[string]interface{}{"id": id, "deleted": true, "user_id": userId}, nil)
+}
+`, name, d.PgName())
+
+	if d.IsStateMachine() {
+		actions := []string{}
+		for _, st := range d.StateMachine.States {
+			for _, a := range st.Actions {
+				actionName := fmt.Sprintf("%s_to_%s", st.Title, a.To)
+				actions = append(actions, fmt.Sprintf("\t%sAction%s %sActionName = \"%s\" // %s", name, snaker.SnakeToCamel(actionName), name, actionName, a.Label))
+			}
+		}
+		res += fmt.Sprintf(`
+// %[1]sActionName название перехода state machine
+type %[1]sActionName string
+
+const (
+%[3]s
+)
+
+// %[1]sAction переход записи в другой стейт. В params передаются поля, которые заполняются при переходе
+func %[1]sAction(userId, id int64, action %[1]sActionName, params map[string]interface{}) (*%[1]s, error) {
+	p := map[string]interface{}{}
+	for k, v := range params {
+		p[k] = v
+	}
+	p["id"] = id
+	p["action_name"] = string(action)
+	p["user_id"] = userId
+	res := &%[1]s{}
+	err := call("%[2]s_action", p, res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+`, name, d.PgName(), strings.Join(actions, "\n"))
+	}
+	return res
+}
+
+// PrintGoClientDocs печать go клиента для всех документов, для которых генерируются стандартные sql функции
+func (p ProjectType) PrintGoClientDocs() string {
+	arr := []string{}
+	for _, d := range p.Docs {
+		if d.IsBaseTemplates.Sql {
+			arr = append(arr, d.PrintGoClient())
+		}
+	}
+	return strings.Join(arr, "\n")
+}
+
+// IsGoClient признак что есть документы, для которых генерируется go клиент
+func (p ProjectType) IsGoClient() bool {
+	for _, d := range p.Docs {
+		if d.IsBaseTemplates.Sql {
+			return true
+		}
+	}
+	return false
+}
+
+func goClientIsBaseFld(name string) bool {
+	for _, v := range goClientBaseFlds {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,183 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RestMethod название pg метода документа для REST api (list, get_by_id, update, action). Пустая строка, если у документа нет такого метода
+func (d DocType) RestMethod(kind string) string {
+	name := d.Name + "_" + kind
+	if _, ok := d.Sql.Methods[name]; ok {
+		return name
+	}
+	return ""
+}
+
+// IsRestApi признак что для документа генерируются роуты /api/v1/<doc>
+func (d DocType) IsRestApi() bool {
+	return len(d.RestMethod("list")) > 0 || len(d.RestMethod("get_by_id")) > 0 || len(d.RestMethod("update")) > 0
+}
+
+// IsRestApi признак что в проекте есть документы с REST api - генерируется webServer/restApi.go
+func (p ProjectType) IsRestApi() bool {
+	for _, d := range p.Docs {
+		if d.IsRestApi() {
+			return true
+		}
+	}
+	return false
+}
+
+// RestFilters query параметры для GET /api/v1/<doc>: поля из FilterList, ссылки и поля с IsSearch. Название -> тип (int, float, bool, string)
+func (d DocType) RestFilters() map[string]string {
+	res := map[string]string{}
+	for _, fld := range d.Flds {
+		if len(fld.Name) == 0 || fld.Name == "title" {
+			continue
+		}
+		if len(fld.Sql.Ref) > 0 || fld.Sql.IsSearch {
+			res[fld.Name] = fld.restParamType()
+		}
+	}
+	for _, f := range d.Vue.FilterList {
+		if len(f.FldName) == 0 {
+			continue
+		}
+		if f.IsRef {
+			res[f.FldName] = "int"
+			continue
+		}
+		res[f.FldName] = "string"
+		for _, fld := range d.Flds {
+			if fld.Name == f.FldName {
+				res[f.FldName] = fld.restParamType()
+			}
+		}
+	}
+	return res
+}
+
+// RestActions переходы state machine, доступные через POST /api/v1/<doc>/:id/actions/<action>
+func (d DocType) RestActions() []string {
+	res := []string{}
+	if !d.IsStateMachine() || len(d.RestMethod("action")) == 0 {
+		return res
+	}
+	for _, st := range d.StateMachine.States {
+		for _, a := range st.Actions {
+			res = append(res, fmt.Sprintf("%s_to_%s", st.Title, a.To))
+		}
+	}
+	return res
+}
+
+func (fld FldType) restParamType() string {
+	if len(fld.Sql.Ref) > 0 {
+		return "int"
+	}
+	switch fld.Type {
+	case FldTypeInt, FldTypeInt64:
+		return "int"
+	case FldTypeDouble:
+		return "float"
+	case FldTypeBool:
+		return "bool"
+	}
+	return "string"
+}
+
+// PrintRestResources печать списка документов для REST api (webServer/restApi.go)
+func (p ProjectType) PrintRestResources() string {
+	arr := []string{}
+	for _, d := range p.Docs {
+		if !d.IsRestApi() {
+			continue
+		}
+		filters := d.RestFilters()
+		keys := []string{}
+		for k := range filters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		filterArr := []string{}
+		for _, k := range keys {
+			filterArr = append(filterArr, fmt.Sprintf(`"%s": "%s"`, k, filters[k]))
+		}
+		str := fmt.Sprintf(`		{Doc: "%s", List: "%s", Get: "%s", Update: "%s", Filters: map[string]string{%s}`,
+			d.Name, d.RestMethod("list"), d.RestMethod("get_by_id"), d.RestMethod("update"), strings.Join(filterArr, ", "))
+		if actions := d.RestActions(); len(actions) > 0 {
+			str += fmt.Sprintf(`, Action: "%s", Actions: []string{"%s"}`, d.RestMethod("action"), strings.Join(actions, `", "`))
+		}
+		arr = append(arr, str+"},")
+	}
+	return strings.Join(arr, "\n")
+}
+
+// описание роутов /api/v1/<doc> в openapi.json
+func (o *OpenApiDoc) addRestPaths(p ProjectType) {
+	idParam := OpenApiParameter{Name: "id", In: "path", Required: true, Schema: &OpenApiSchema{Type: "integer", Format: "int64"}}
+	errResponse := &OpenApiResponse{Description: "ошибка", Content: openApiJson(openApiRef("Error"))}
+	for _, d := range p.Docs {
+		if !d.IsRestApi() {
+			continue
+		}
+		url := "/api/v1/" + d.Name
+		tag := "rest: " + d.Name
+		docRef := openApiRef(d.NameCamelCase())
+		ops := []*OpenApiOperation{}
+		if len(d.RestMethod("list")) > 0 {
+			params := []OpenApiParameter{}
+			filters := d.RestFilters()
+			for k, v := range map[string]string{"deleted": "bool", "order_by": "string", "page": "int", "per_page": "int", "search_text": "string"} {
+				filters[k] = v
+			}
+			keys := []string{}
+			for k := range filters {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				params = append(params, OpenApiParameter{Name: k, In: "query", Schema: openApiRestParamSchema(filters[k])})
+			}
+			ops = append(ops, o.addOperation(url, "get", "Список: "+d.NameRu, tag, nil, &OpenApiSchema{Type: "array", Items: docRef}, params, true))
+		}
+		if len(d.RestMethod("get_by_id")) > 0 {
+			ops = append(ops, o.addOperation(url+"/{id}", "get", "Запись по id: "+d.NameRu, tag, nil, docRef, []OpenApiParameter{idParam}, true))
+		}
+		if len(d.RestMethod("update")) > 0 {
+			create := o.addOperation(url, "post", "Создание записи: "+d.NameRu, tag, docRef, docRef, nil, true)
+			create.Responses["201"] = create.Responses["200"]
+			delete(create.Responses, "200")
+			ops = append(ops, create,
+				o.addOperation(url+"/{id}", "patch", "Изменение записи: "+d.NameRu, tag, docRef, docRef, []OpenApiParameter{idParam}, true),
+				o.addOperation(url+"/{id}", "delete", "Удаление записи: "+d.NameRu, tag, nil, docRef, []OpenApiParameter{idParam}, true))
+		}
+		if actions := d.RestActions(); len(actions) > 0 {
+			enum := []interface{}{}
+			for _, a := range actions {
+				enum = append(enum, a)
+			}
+			actionParam := OpenApiParameter{Name: "action", In: "path", Required: true, Schema: &OpenApiSchema{Type: "string", Enum: enum}}
+			action := o.addOperation(url+"/{id}/actions/{action}", "post", "Переход state machine: "+d.NameRu, tag, &OpenApiSchema{Type: "object", AdditionalProperties: true}, docRef, []OpenApiParameter{idParam, actionParam}, true)
+			action.RequestBody.Required = false
+			ops = append(ops, action)
+		}
+		for _, op := range ops {
+			op.Responses["400"] = errResponse
+		}
+	}
+}
+
+func openApiRestParamSchema(paramType string) *OpenApiSchema {
+	switch paramType {
+	case "int":
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case "float":
+		return &OpenApiSchema{Type: "number"}
+	case "bool":
+		return &OpenApiSchema{Type: "boolean"}
+	}
+	return &OpenApiSchema{Type: "string"}
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/tvitcom/nla_framework/utils"
 	"github.com/serenize/snaker"
-	"log"
+	"sort"
 	"strings"
 )
 
@@ -88,6 +88,10 @@ func (d DocType) PrintSqlModelTriggers() (res string) {
 	if d.Sql.IsAfterTrigger {
 		arr = append(arr, fmt.Sprintf("\t{name=\"%s_trigger_after\", when=\"after insert or update\", ref=\"for each row\", funcName=\"%s_trigger_after\"}", d.Name, d.Name))
 	}
+	// события нужны и для сброса кэша pg методов, которые зависят от таблицы документа
+	if d.Sql.IsNotifyEvent || d.Realtime != nil || (project != nil && project.isCachedMethodTable(d.PgName())) {
+		arr = append(arr, fmt.Sprintf("\t{name=\"%s_event\", when=\"after insert or update or delete\", ref=\"for each row\", funcName=\"notify_event\"}", d.Name))
+	}
 	if len(arr) > 0 {
 		res = fmt.Sprintf("triggers = [\n%s\n]", strings.Join(arr, ",\n"))
 	}
@@ -111,10 +115,14 @@ func (d DocType) PrintSqlModelIndexes() string {
 // main.toml печать methods
 func (d DocType) PrintSqlModelMethods() (res string) {
 	arr := []string{}
-	if d.Sql.Methods != nil {
-		for mName := range d.Sql.Methods {
-			arr = append(arr, fmt.Sprintf("\t\"%s\"", mName))
-		}
+	// порядок методов не должен зависеть от обхода map, иначе main.toml меняется при каждой генерации
+	names := []string{}
+	for mName := range d.Sql.Methods {
+		names = append(names, mName)
+	}
+	sort.Strings(names)
+	for _, mName := range names {
+		arr = append(arr, fmt.Sprintf("\t\"%s\"", mName))
 	}
 	if d.Sql.IsBeforeTrigger {
 		arr = append(arr, fmt.Sprintf("\t\"%s_trigger_before\"", d.Name))
@@ -131,33 +139,13 @@ func (d DocType) PrintSqlModelMethods() (res string) {
 
 // main.toml печать methods
 func (d DocType) PrintSqlModelAlterScripts() (res string) {
-	// подбираем postgres тип для alter script
-	getType := func(fld FldType) string {
-		if fld.Type == "string" {
-			if fld.Sql.Size > 0 {
-				return fmt.Sprintf("CHARACTER VARYING(%v)", fld.Sql.Size)
-			} else {
-				return "text"
-			}
-		}
-		if fld.Type == FldTypeInt64 {
-			return "int"
-		}
-		if fld.Type == FldTypeDouble {
-			return "double precision"
-		}
-		if utils.CheckContainsSliceStr(fld.Type, FldTypeDate, FldTypeDatetime) {
-			return "timestamp"
-		}
-		return fld.Type
-	}
 	arr := []string{}
 
 	for _, fld := range d.Flds {
 		if len(fld.Name) == 0 || fld.Sql.IsOptionFld || utils.CheckContainsSliceStr(fld.Name, "id", "created_at", "updated_at", "deleted") {
 			continue
 		}
-		arr = append(arr, fmt.Sprintf("\t\"alter table %s add column if not exists %s %s;\"", d.PgName(), fld.Name, getType(fld)))
+		arr = append(arr, fmt.Sprintf("\t\"alter table %s add column if not exists %s %s;\"", d.PgName(), fld.Name, fld.PgColumnType()))
 
 	}
 	if d.Sql.IsSearchText {
@@ -258,7 +246,7 @@ func (d DocType) SearchTxt() string {
 		}
 	}
 	if len(arr) == 0 {
-		log.Fatalf("missed isSearch fld: %s", d.Name)
+		utils.Fatalf("missed isSearch fld: %s", d.Name)
 	}
 	if len(arr) > 1 {
 		//concat(doc.description, ' ', doc.supplyer)
@@ -555,7 +543,7 @@ func (d DocType) PrintAfterTriggerUpdateLinkedRecords() string {
 			}
 		}
 		if !isFldTitleExist {
-			log.Fatal(fmt.Sprintf("PrintAfterTriggerUpdateLinkedRecords '%s' missed field 'title'", d.Name))
+			utils.Fatalf("PrintAfterTriggerUpdateLinkedRecords '%s' missed field 'title'", d.Name)
 		}
 		res1 := "IF (TG_OP = 'UPDATE') THEN\n-- при смене названия обновляем все ссылающиеся записи, чтобы там переписалось новое название\nif new.title != old.title then\n"
 		for _, arr := range linkedDocs {
@@ -644,3 +632,58 @@ func (d DocSqlHooks) Print(tmplName, hookName string) string {
 	}
 	return ""
 }
+
+// realtime.sql проверка операции
+func (d DocType) PrintSqlFuncRealtimeOps() string {
+	if d.Realtime == nil || len(d.Realtime.Ops) == 0 {
+		return "-- сообщаем обо всех операциях"
+	}
+	ops := []string{}
+	for _, op := range d.Realtime.Ops {
+		ops = append(ops, fmt.Sprintf("'%s'", strings.ToLower(op)))
+	}
+	return fmt.Sprintf("IF NOT op = ANY (ARRAY [%s])\n  THEN\n    RETURN NULL;\n  END IF;", strings.Join(ops, ", "))
+}
+
+// realtime.sql получатели событий
+func (d DocType) PrintSqlFuncRealtimeRecipients() string {
+	if d.Realtime == nil || len(d.Realtime.Recipients) == 0 {
+		return "-- получатели не указаны: событие только в топики документа"
+	}
+	res := []string{}
+	for _, rc := range d.Realtime.Recipients {
+		switch {
+		case len(rc.Fld) > 0:
+			res = append(res, fmt.Sprintf("-- %[1]s\n  users = users || ARRAY [(r ->> '%[1]s') :: BIGINT, (old ->> '%[1]s') :: BIGINT];", rc.Fld))
+		case len(rc.LinkTable) > 0:
+			docFld := rc.LinkDocFld
+			if len(docFld) == 0 {
+				docFld = d.PgName() + "_id"
+			}
+			userFld := rc.LinkUserFld
+			if len(userFld) == 0 {
+				userFld = "user_id"
+			}
+			res = append(res, fmt.Sprintf("-- %[1]s\n  users = users || ARRAY(SELECT %[3]s :: BIGINT FROM %[1]s WHERE %[2]s = (r ->> 'id') :: INT);", rc.LinkTable, docFld, userFld))
+		case len(rc.Role) > 0:
+			res = append(res, fmt.Sprintf("-- роль %[1]s\n  users = users || ARRAY(SELECT id :: BIGINT FROM \"user\" WHERE '%[1]s' = ANY (role) AND deleted = FALSE);", rc.Role))
+		}
+		// получатель без Fld, LinkTable и Role - ошибка в ProjectType.Validate
+	}
+	return strings.Join(res, "\n\n  ")
+}
+
+// realtime.sql данные события
+func (d DocType) PrintSqlFuncRealtimeData() string {
+	if d.Realtime == nil || len(d.Realtime.Flds) == 0 {
+		return "NULL"
+	}
+	arr := []string{"'id', r -> 'id'"}
+	for _, f := range d.Realtime.Flds {
+		if f == "id" {
+			continue
+		}
+		arr = append(arr, fmt.Sprintf("'%[1]s', r -> '%[1]s'", f))
+	}
+	return fmt.Sprintf("jsonb_build_object(%s)", strings.Join(arr, ", "))
+}
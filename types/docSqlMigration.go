@@ -0,0 +1,279 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// SqlModelSnapshot - состояние таблицы документа на момент последней генерации.
+	// Сохраняется в sql/migrations/snapshot и используется для построения миграции при следующей генерации
+	SqlModelSnapshot struct {
+		Doc   string                `json:"doc"`
+		Table string                `json:"table"`
+		Flds  []SqlModelSnapshotFld `json:"flds"`
+	}
+
+	SqlModelSnapshotFld struct {
+		Name      string `json:"name"`
+		Type      string `json:"type"`
+		Default   string `json:"default,omitempty"`
+		IsNotNull bool   `json:"isNotNull,omitempty"`
+		IsUniq    bool   `json:"isUniq,omitempty"`
+		Ref       string `json:"ref,omitempty"` // название таблицы, на которую ссылается поле
+	}
+
+	// SqlMigration - скрипты миграции таблицы. Down содержит обратные операции в обратном порядке
+	SqlMigration struct {
+		Doc   string
+		Table string
+		Up    []string
+		Down  []string
+	}
+)
+
+// SqlSnapshot текущее состояние таблицы документа по описанию полей
+func (d DocType) SqlSnapshot() SqlModelSnapshot {
+	res := SqlModelSnapshot{Doc: d.Name, Table: d.PgName(), Flds: []SqlModelSnapshotFld{}}
+	for _, fld := range d.Flds {
+		if len(fld.Name) == 0 || fld.Sql.IsOptionFld {
+			continue
+		}
+		res.Flds = append(res.Flds, SqlModelSnapshotFld{
+			Name:      fld.Name,
+			Type:      fld.PgColumnType(),
+			Default:   fld.Sql.Default,
+			IsNotNull: fld.Sql.IsRequired,
+			IsUniq:    fld.Sql.IsUniq,
+			Ref:       fld.Sql.Ref,
+		})
+	}
+	if d.Sql.IsSearchText {
+		res.Flds = append(res.Flds, SqlModelSnapshotFld{Name: "search_text", Type: "text"})
+	}
+	return res
+}
+
+func (s SqlModelSnapshot) fld(name string) *SqlModelSnapshotFld {
+	for i := range s.Flds {
+		if s.Flds[i].Name == name {
+			return &s.Flds[i]
+		}
+	}
+	return nil
+}
+
+// SqlMigration сравнивает предыдущее состояние таблицы с текущим описанием документа.
+// Переименование колонки определяется только по FldSql.PrevName, иначе колонка считается удаленной и добавленной
+func (d DocType) SqlMigration(prev SqlModelSnapshot) SqlMigration {
+	cur := d.SqlSnapshot()
+	m := SqlMigration{Doc: d.Name, Table: cur.Table}
+	t := cur.Table
+	// названия колонок из предыдущего состояния, которые есть в текущем (напрямую или через переименование)
+	isPrevFldUsed := map[string]bool{}
+
+	prevNames := map[string]string{}
+	for _, fld := range d.Flds {
+		if len(fld.Sql.PrevName) > 0 {
+			prevNames[fld.Name] = fld.Sql.PrevName
+		}
+	}
+
+	for _, f := range cur.Flds {
+		old := prev.fld(f.Name)
+		if old == nil && len(prevNames[f.Name]) > 0 {
+			if old = prev.fld(prevNames[f.Name]); old != nil {
+				m.add(fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", t, old.Name, f.Name),
+					fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s;", t, f.Name, old.Name))
+			}
+		}
+		if old == nil {
+			up := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s%s;", t, f.Name, f.Type, f.columnExt())
+			// NOT NULL колонку без DEFAULT нельзя добавить в непустую таблицу: добавляем без ограничения,
+			// заполняем существующие записи и только потом ставим NOT NULL
+			if f.IsNotNull && len(f.Default) == 0 {
+				nullable := f
+				nullable.IsNotNull = false
+				up = fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s%s;\n%s", t, f.Name, f.Type, nullable.columnExt(), setNotNullSql(t, f))
+			}
+			m.add(up, fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", t, f.Name))
+			continue
+		}
+		isPrevFldUsed[old.Name] = true
+		m.addFldChanges(t, *old, f)
+	}
+
+	// удаленные колонки. В down колонка восстанавливается, но без данных
+	for _, old := range prev.Flds {
+		if isPrevFldUsed[old.Name] {
+			continue
+		}
+		// down выполняется в обратном порядке: сначала восстанавливается колонка, затем ограничение
+		if old.IsUniq {
+			m.add(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", t, uniqConstraintName(m.Doc, old.Name)),
+				fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", t, uniqConstraintName(m.Doc, old.Name), old.Name))
+		}
+		m.add(fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;", t, old.Name),
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s%s;", t, old.Name, old.Type, old.columnExt()))
+	}
+	return m
+}
+
+// изменения колонки, которая есть и в предыдущем и в текущем состоянии. f.Name - текущее название колонки
+func (m *SqlMigration) addFldChanges(t string, old, f SqlModelSnapshotFld) {
+	if old.Type != f.Type {
+		m.add(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", t, f.Name, f.Type, f.Name, f.Type),
+			fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s;", t, f.Name, old.Type, f.Name, old.Type))
+	}
+	if old.Default != f.Default {
+		m.add(alterColumnDefault(t, f.Name, f.Default), alterColumnDefault(t, f.Name, old.Default))
+	}
+	if old.IsNotNull != f.IsNotNull {
+		up, down := alterColumnNotNull(t, f.Name, f.IsNotNull), alterColumnNotNull(t, f.Name, old.IsNotNull)
+		if f.IsNotNull {
+			up = setNotNullSql(t, f)
+		} else {
+			old.Name = f.Name
+			down = setNotNullSql(t, old)
+		}
+		m.add(up, down)
+	}
+	// ограничение на уникальность
+	if old.IsUniq != f.IsUniq || (old.IsUniq && old.Name != f.Name) {
+		dropOld := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", t, uniqConstraintName(m.Doc, old.Name))
+		addOld := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", t, uniqConstraintName(m.Doc, old.Name), f.Name)
+		dropNew := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s;", t, uniqConstraintName(m.Doc, f.Name))
+		addNew := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);", t, uniqConstraintName(m.Doc, f.Name), f.Name)
+		if old.IsUniq {
+			m.add(dropOld, addOld)
+		}
+		if f.IsUniq {
+			m.add(dropNew+"\n"+addNew, dropNew)
+		}
+	}
+	// внешний ключ. Создание fk остается за pg_generate (fkConstraints в main.toml), в миграции только удаление старого
+	if old.Ref != f.Ref {
+		if len(old.Ref) > 0 {
+			m.add(dropFkSql(t, f.Name), fmt.Sprintf("ALTER TABLE %s ADD FOREIGN KEY (%s) REFERENCES %s (id);", t, f.Name, pgTableName(old.Ref)))
+		}
+		if len(f.Ref) > 0 {
+			m.add("", dropFkSql(t, f.Name))
+		}
+	}
+}
+
+// добавление пары скриптов. Down скрипты выполняются в обратном порядке
+func (m *SqlMigration) add(up, down string) {
+	if len(up) > 0 {
+		m.Up = append(m.Up, up)
+	}
+	if len(down) > 0 {
+		m.Down = append([]string{down}, m.Down...)
+	}
+}
+
+func (m SqlMigration) IsEmpty() bool {
+	return len(m.Up) == 0 && len(m.Down) == 0
+}
+
+// PrintUp текст файла миграции. Скрипты выполняются только если таблица уже существует,
+// в новой базе таблица создается pg_generate сразу в актуальном виде
+func (m SqlMigration) PrintUp() string {
+	return m.print(m.Up)
+}
+
+func (m SqlMigration) PrintDown() string {
+	return m.print(m.Down)
+}
+
+func (m SqlMigration) print(arr []string) string {
+	return fmt.Sprintf(`-- миграция таблицы %[1]s. Файл сгенерирован автоматически
+DO $migration$
+DECLARE
+	r record;
+BEGIN
+IF EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = '%[1]s') THEN
+	%[2]s
+END IF;
+END;
+$migration$ LANGUAGE plpgsql;
+`, m.Table, strings.Replace(strings.Join(arr, "\n"), "\n", "\n\t", -1))
+}
+
+func (f SqlModelSnapshotFld) columnExt() string {
+	res := ""
+	if f.IsNotNull {
+		res = res + " NOT NULL"
+	}
+	if len(f.Default) > 0 {
+		res = res + " DEFAULT " + f.Default
+	}
+	return res
+}
+
+func alterColumnDefault(t, name, def string) string {
+	if len(def) == 0 {
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT;", t, name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s;", t, name, def)
+}
+
+func alterColumnNotNull(t, name string, isNotNull bool) string {
+	if isNotNull {
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL;", t, name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL;", t, name)
+}
+
+// установка NOT NULL с заполнением пустых значений: DEFAULT колонки или нулевое значение для ее типа.
+// Если подходящего значения нет (например, uuid), то миграция останавливается, когда в таблице есть пустые значения
+func setNotNullSql(t string, f SqlModelSnapshotFld) string {
+	if v := f.backfillValue(); len(v) > 0 {
+		return fmt.Sprintf("UPDATE %[1]s SET %[2]s = %[3]s WHERE %[2]s IS NULL;\n%[4]s", t, f.Name, v, alterColumnNotNull(t, f.Name, true))
+	}
+	return fmt.Sprintf("IF EXISTS (SELECT 1 FROM %[1]s WHERE %[2]s IS NULL) THEN\n\tRAISE EXCEPTION 'column %[1]s.%[2]s is NOT NULL without DEFAULT: fill empty values before migration';\nEND IF;\n%[3]s",
+		t, f.Name, alterColumnNotNull(t, f.Name, true))
+}
+
+// значение для заполнения пустых значений колонки перед установкой NOT NULL
+func (f SqlModelSnapshotFld) backfillValue() string {
+	if len(f.Default) > 0 {
+		return f.Default
+	}
+	switch {
+	case strings.HasSuffix(f.Type, "[]"):
+		return "'{}'"
+	case f.Type == "text" || strings.HasPrefix(f.Type, "CHARACTER VARYING"):
+		return "''"
+	case f.Type == "int" || f.Type == "double precision":
+		return "0"
+	case f.Type == "bool":
+		return "false"
+	case f.Type == "jsonb":
+		return "'{}'"
+	case f.Type == "timestamp":
+		return "now()"
+	}
+	return ""
+}
+
+// название совпадает с тем, что пишется в fkConstraints в main.toml
+func uniqConstraintName(docName, fldName string) string {
+	return fmt.Sprintf("%s_%s_already_exist", docName, fldName)
+}
+
+// удаление всех внешних ключей по колонке. Название fk задает pg_generate, поэтому ищем по колонке
+func dropFkSql(t, fldName string) string {
+	return fmt.Sprintf(`FOR r IN SELECT con.conname FROM pg_constraint con JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = ANY(con.conkey)
+	WHERE con.conrelid = '%[1]s'::regclass AND con.contype = 'f' AND a.attname = '%[2]s' LOOP
+	EXECUTE format('ALTER TABLE %[1]s DROP CONSTRAINT %%I', r.conname);
+END LOOP;`, t, fldName)
+}
+
+// таблица user - зарезервированное слово в postgres
+func pgTableName(name string) string {
+	if name == "user" {
+		return `"user"`
+	}
+	return name
+}
@@ -0,0 +1,217 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+// тело DO блока миграции без обертки и отступа - по одному скрипту (или его строке) на строку
+func migrationBody(s string) string {
+	start := strings.Index(s, "THEN\n")
+	end := strings.LastIndex(s, "\nEND IF;\nEND;")
+	if start < 0 || end < start {
+		return s
+	}
+	return strings.Replace(s[start+len("THEN\n"):end], "\n\t", "\n", -1)[1:]
+}
+
+func TestDocSqlMigration(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []SqlModelSnapshotFld
+		flds []FldType
+		up   []string
+		down []string
+	}{
+		{
+			name: "no changes",
+			prev: []SqlModelSnapshotFld{{Name: "inn", Type: "CHARACTER VARYING(20)"}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil)},
+		},
+		{
+			name: "added column",
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil)},
+			up:   []string{"ALTER TABLE client ADD COLUMN IF NOT EXISTS inn CHARACTER VARYING(20);"},
+			down: []string{"ALTER TABLE client DROP COLUMN IF EXISTS inn;"},
+		},
+		{
+			name: "added column with default",
+			flds: []FldType{GetFldDouble("amount", "сумма", nil).SetIsRequired().SetDefault("1")},
+			up:   []string{"ALTER TABLE client ADD COLUMN IF NOT EXISTS amount double precision NOT NULL DEFAULT 1;"},
+			down: []string{"ALTER TABLE client DROP COLUMN IF EXISTS amount;"},
+		},
+		{
+			name: "added not null column with backfill",
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil).SetIsRequired()},
+			up: []string{
+				"ALTER TABLE client ADD COLUMN IF NOT EXISTS inn CHARACTER VARYING(20);",
+				"UPDATE client SET inn = '' WHERE inn IS NULL;",
+				"ALTER TABLE client ALTER COLUMN inn SET NOT NULL;",
+			},
+			down: []string{"ALTER TABLE client DROP COLUMN IF EXISTS inn;"},
+		},
+		{
+			name: "added not null column without backfill value",
+			flds: []FldType{GetFldUuid("guid", "guid", nil).SetIsRequired()},
+			up: []string{
+				"ALTER TABLE client ADD COLUMN IF NOT EXISTS guid uuid;",
+				"IF EXISTS (SELECT 1 FROM client WHERE guid IS NULL) THEN",
+				"	RAISE EXCEPTION 'column client.guid is NOT NULL without DEFAULT: fill empty values before migration';",
+				"END IF;",
+				"ALTER TABLE client ALTER COLUMN guid SET NOT NULL;",
+			},
+			down: []string{"ALTER TABLE client DROP COLUMN IF EXISTS guid;"},
+		},
+		{
+			name: "dropped column",
+			prev: []SqlModelSnapshotFld{{Name: "inn", Type: "CHARACTER VARYING(20)", IsNotNull: true, Default: "''"}},
+			up:   []string{"ALTER TABLE client DROP COLUMN IF EXISTS inn;"},
+			down: []string{"ALTER TABLE client ADD COLUMN IF NOT EXISTS inn CHARACTER VARYING(20) NOT NULL DEFAULT '';"},
+		},
+		{
+			name: "dropped unique column",
+			prev: []SqlModelSnapshotFld{{Name: "inn", Type: "CHARACTER VARYING(20)", IsUniq: true}},
+			up: []string{
+				"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;",
+				"ALTER TABLE client DROP COLUMN IF EXISTS inn;",
+			},
+			down: []string{
+				"ALTER TABLE client ADD COLUMN IF NOT EXISTS inn CHARACTER VARYING(20);",
+				"ALTER TABLE client ADD CONSTRAINT client_inn_already_exist UNIQUE (inn);",
+			},
+		},
+		{
+			name: "renamed column",
+			prev: []SqlModelSnapshotFld{{Name: "tax_id", Type: "CHARACTER VARYING(20)"}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil).SetPrevName("tax_id")},
+			up:   []string{"ALTER TABLE client RENAME COLUMN tax_id TO inn;"},
+			down: []string{"ALTER TABLE client RENAME COLUMN inn TO tax_id;"},
+		},
+		{
+			name: "renamed column without prev name",
+			prev: []SqlModelSnapshotFld{{Name: "tax_id", Type: "CHARACTER VARYING(20)"}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil)},
+			up: []string{
+				"ALTER TABLE client ADD COLUMN IF NOT EXISTS inn CHARACTER VARYING(20);",
+				"ALTER TABLE client DROP COLUMN IF EXISTS tax_id;",
+			},
+			down: []string{
+				"ALTER TABLE client ADD COLUMN IF NOT EXISTS tax_id CHARACTER VARYING(20);",
+				"ALTER TABLE client DROP COLUMN IF EXISTS inn;",
+			},
+		},
+		{
+			name: "renamed unique column",
+			prev: []SqlModelSnapshotFld{{Name: "tax_id", Type: "CHARACTER VARYING(20)", IsUniq: true}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil).SetPrevName("tax_id").SetIsUniq()},
+			up: []string{
+				"ALTER TABLE client RENAME COLUMN tax_id TO inn;",
+				"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_tax_id_already_exist;",
+				"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;",
+				"ALTER TABLE client ADD CONSTRAINT client_inn_already_exist UNIQUE (inn);",
+			},
+			down: []string{
+				"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;",
+				"ALTER TABLE client ADD CONSTRAINT client_tax_id_already_exist UNIQUE (inn);",
+				"ALTER TABLE client RENAME COLUMN inn TO tax_id;",
+			},
+		},
+		{
+			name: "type change",
+			prev: []SqlModelSnapshotFld{{Name: "amount", Type: "int"}},
+			flds: []FldType{GetFldDouble("amount", "сумма", nil)},
+			up:   []string{"ALTER TABLE client ALTER COLUMN amount TYPE double precision USING amount::double precision;"},
+			down: []string{"ALTER TABLE client ALTER COLUMN amount TYPE int USING amount::int;"},
+		},
+		{
+			name: "default change",
+			prev: []SqlModelSnapshotFld{{Name: "amount", Type: "double precision", Default: "1"}},
+			flds: []FldType{GetFldDouble("amount", "сумма", nil)},
+			up:   []string{"ALTER TABLE client ALTER COLUMN amount DROP DEFAULT;"},
+			down: []string{"ALTER TABLE client ALTER COLUMN amount SET DEFAULT 1;"},
+		},
+		{
+			name: "not null with backfill",
+			prev: []SqlModelSnapshotFld{{Name: "amount", Type: "double precision"}},
+			flds: []FldType{GetFldDouble("amount", "сумма", nil).SetIsRequired()},
+			up: []string{
+				"UPDATE client SET amount = 0 WHERE amount IS NULL;",
+				"ALTER TABLE client ALTER COLUMN amount SET NOT NULL;",
+			},
+			down: []string{"ALTER TABLE client ALTER COLUMN amount DROP NOT NULL;"},
+		},
+		{
+			name: "not null with backfill by default",
+			prev: []SqlModelSnapshotFld{{Name: "amount", Type: "double precision", Default: "1"}},
+			flds: []FldType{GetFldDouble("amount", "сумма", nil).SetIsRequired().SetDefault("1")},
+			up: []string{
+				"UPDATE client SET amount = 1 WHERE amount IS NULL;",
+				"ALTER TABLE client ALTER COLUMN amount SET NOT NULL;",
+			},
+			down: []string{"ALTER TABLE client ALTER COLUMN amount DROP NOT NULL;"},
+		},
+		{
+			name: "drop not null",
+			prev: []SqlModelSnapshotFld{{Name: "title", Type: "text", IsNotNull: true}},
+			flds: []FldType{GetFldString("title", "название", 0, nil)},
+			up:   []string{"ALTER TABLE client ALTER COLUMN title DROP NOT NULL;"},
+			down: []string{
+				"UPDATE client SET title = '' WHERE title IS NULL;",
+				"ALTER TABLE client ALTER COLUMN title SET NOT NULL;",
+			},
+		},
+		{
+			name: "unique added",
+			prev: []SqlModelSnapshotFld{{Name: "inn", Type: "CHARACTER VARYING(20)"}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil).SetIsUniq()},
+			up: []string{
+				"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;",
+				"ALTER TABLE client ADD CONSTRAINT client_inn_already_exist UNIQUE (inn);",
+			},
+			down: []string{"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;"},
+		},
+		{
+			name: "unique removed",
+			prev: []SqlModelSnapshotFld{{Name: "inn", Type: "CHARACTER VARYING(20)", IsUniq: true}},
+			flds: []FldType{GetFldString("inn", "ИНН", 20, nil)},
+			up:   []string{"ALTER TABLE client DROP CONSTRAINT IF EXISTS client_inn_already_exist;"},
+			down: []string{"ALTER TABLE client ADD CONSTRAINT client_inn_already_exist UNIQUE (inn);"},
+		},
+		{
+			name: "fk changed",
+			prev: []SqlModelSnapshotFld{{Name: "manager_id", Type: "int", Ref: "user"}},
+			flds: []FldType{GetFldRef("manager_id", "менеджер", "employee", nil)},
+			up:   strings.Split(dropFkSql("client", "manager_id"), "\n"),
+			down: append(strings.Split(dropFkSql("client", "manager_id"), "\n"),
+				`ALTER TABLE client ADD FOREIGN KEY (manager_id) REFERENCES "user" (id);`),
+		},
+		{
+			name: "fk removed",
+			prev: []SqlModelSnapshotFld{{Name: "manager_id", Type: "int", Ref: "employee"}},
+			flds: []FldType{GetFldInt("manager_id", "менеджер", nil)},
+			up:   strings.Split(dropFkSql("client", "manager_id"), "\n"),
+			down: []string{"ALTER TABLE client ADD FOREIGN KEY (manager_id) REFERENCES employee (id);"},
+		},
+		{
+			name: "fk added",
+			prev: []SqlModelSnapshotFld{{Name: "manager_id", Type: "int"}},
+			flds: []FldType{GetFldRef("manager_id", "менеджер", "employee", nil)},
+			down: strings.Split(dropFkSql("client", "manager_id"), "\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := DocType{Name: "client", Flds: tt.flds}
+			m := d.SqlMigration(SqlModelSnapshot{Doc: "client", Table: "client", Flds: tt.prev})
+			if m.IsEmpty() != (len(tt.up) == 0 && len(tt.down) == 0) {
+				t.Fatalf("IsEmpty = %v\nup:\n%s\ndown:\n%s", m.IsEmpty(), m.PrintUp(), m.PrintDown())
+			}
+			if got, want := migrationBody(m.PrintUp()), strings.Join(tt.up, "\n"); got != want {
+				t.Errorf("PrintUp:\n%s\nwant:\n%s", got, want)
+			}
+			if got, want := migrationBody(m.PrintDown()), strings.Join(tt.down, "\n"); got != want {
+				t.Errorf("PrintDown:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
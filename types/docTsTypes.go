@@ -0,0 +1,159 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TsType тип поля в typescript интерфейсе документа. Варианты выбора из Vue.Options описываются как union
+func (fld *FldType) TsType() string {
+	if values := fld.tsOptionValues(); len(values) > 0 {
+		return strings.Join(values, " | ")
+	}
+	switch fld.Type {
+	case FldTypeInt, FldTypeInt64, FldTypeDouble:
+		return "number"
+	case FldTypeBool:
+		return "boolean"
+	case FldTypeTextArray:
+		return "string[]"
+	case FldTypeIntArray:
+		return "number[]"
+	case FldTypeJsonb:
+		if fld.Vue.Type == FldVueTypeJsonList || fld.Vue.Type == FldVueTypeFiles || fld.Vue.Type == FldVueTypeImgList {
+			return "any[]"
+		}
+		return "any"
+	default:
+		return "string"
+	}
+}
+
+func (fld *FldType) tsOptionValues() []string {
+	res := []string{}
+	for _, o := range fld.Vue.Options {
+		switch v := o.Value.(type) {
+		case string:
+			res = append(res, fmt.Sprintf("'%s'", strings.Replace(v, "'", "\\'", -1)))
+		case int, int64, float64, bool:
+			res = append(res, fmt.Sprintf("%v", v))
+		}
+	}
+	return res
+}
+
+// PrintTsInterface печать интерфейса документа для pgApi.d.ts
+func (d DocType) PrintTsInterface() string {
+	name := d.NameCamelCase()
+	flds := []string{"  id: number"}
+	optionFlds := []string{}
+	for _, fld := range d.Flds {
+		if len(fld.Name) == 0 || fld.Type == FldTypeVueComposition || goClientIsBaseFld(fld.Name) {
+			continue
+		}
+		tsType := fld.TsType()
+		if !fld.Sql.IsRequired {
+			tsType += " | null"
+		}
+		str := fmt.Sprintf("  /** %s */\n  %s: %s", fld.NameRu, fld.Name, tsType)
+		if fld.Sql.IsOptionFld {
+			optionFlds = append(optionFlds, strings.Replace(str, ": ", "?: ", 1))
+			continue
+		}
+		flds = append(flds, str)
+		// для ссылок get_by_id возвращает title связанной записи
+		if len(fld.Sql.Ref) > 0 {
+			flds = append(flds, fmt.Sprintf("  %s_title?: string", strings.TrimSuffix(fld.Name, "_id")))
+		}
+	}
+	optionFlds = append(optionFlds, "  [key: string]: any")
+	flds = append(flds, fmt.Sprintf("  options: %sOptions", name), "  created_at?: string", "  updated_at?: string", "  deleted: boolean")
+
+	res := fmt.Sprintf("/** %s */\nexport interface %s {\n%s\n}\n\nexport interface %[2]sOptions {\n%[4]s\n}\n",
+		d.NameRu, name, strings.Join(flds, "\n"), strings.Join(optionFlds, "\n"))
+	if d.IsStateMachine() {
+		actions := []string{}
+		for _, st := range d.StateMachine.States {
+			for _, a := range st.Actions {
+				actions = append(actions, fmt.Sprintf("'%s_to_%s'", st.Title, a.To))
+			}
+		}
+		if len(actions) > 0 {
+			res += fmt.Sprintf("\nexport type %sActionName = %s\n", name, strings.Join(actions, " | "))
+		}
+	}
+	return res
+}
+
+// PrintTsDocInterfaces печать интерфейсов всех документов
+func (p ProjectType) PrintTsDocInterfaces() string {
+	arr := []string{}
+	for _, d := range p.Docs {
+		arr = append(arr, d.PrintTsInterface())
+	}
+	return strings.Join(arr, "\n")
+}
+
+// PrintTsPgMethodMap печать соответствия pg метода и типов его параметров и результата
+func (p ProjectType) PrintTsPgMethodMap() string {
+	docByMethod := p.apiPgMethodDocs()
+	arr := []string{}
+	for _, m := range append(p.apiUserPgMethods(), p.ApiPgMethods()...) {
+		params, result := "Record<string, any>", "any"
+		switch m.Name {
+		case "user_list":
+			result = "User[]"
+		case "user_update", "user_get_by_id", "user_get_by_id_for_ui", "current_user_update":
+			result = "User"
+		}
+		if d, ok := docByMethod[m.Name]; ok {
+			name := d.NameCamelCase()
+			switch strings.TrimPrefix(m.Name, d.Name+"_") {
+			case "list":
+				params, result = fmt.Sprintf("ListParams & Partial<%s>", name), name+"[]"
+			case "get_by_id":
+				params, result = "{id: number}", name
+			case "update":
+				params, result = fmt.Sprintf("Partial<%s> & {id: number}", name), name
+			case "action":
+				if d.IsStateMachine() {
+					params, result = fmt.Sprintf("Partial<%[1]s> & {id: number, action_name: %[1]sActionName}", name), name
+				}
+			}
+		}
+		if len(m.Params) > 0 && params == "Record<string, any>" {
+			flds := []string{}
+			keys := []string{}
+			for k := range m.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fld := FldType{Type: m.Params[k]}
+				flds = append(flds, fmt.Sprintf("%s?: %s", k, fld.TsType()))
+			}
+			params = "{" + strings.Join(flds, ", ") + "}"
+		}
+		arr = append(arr, fmt.Sprintf("  %s: {params: %s, result: %s}", m.Name, params, result))
+	}
+	return strings.Join(arr, "\n")
+}
+
+// PrintJsPgMethodConsts печать констант с названиями pg методов: CLIENT_LIST: 'client_list'
+func (p ProjectType) PrintJsPgMethodConsts() string {
+	arr := []string{}
+	for _, m := range append(p.apiUserPgMethods(), p.ApiPgMethods()...) {
+		arr = append(arr, fmt.Sprintf("  %s: '%s',", strings.ToUpper(m.Name), m.Name))
+	}
+	return strings.Join(arr, "\n")
+}
+
+// PrintTsPgMethodConsts печать типа констант с названиями pg методов для pgApi.d.ts
+func (p ProjectType) PrintTsPgMethodConsts() string {
+	arr := []string{}
+	for _, m := range append(p.apiUserPgMethods(), p.ApiPgMethods()...) {
+		arr = append(arr, fmt.Sprintf("  readonly %s: '%s'", strings.ToUpper(m.Name), m.Name))
+	}
+	return strings.Join(arr, "\n")
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/tvitcom/nla_framework/utils"
 	"github.com/serenize/snaker"
 	"github.com/spf13/cast"
-	"log"
 	"strings"
 )
 
@@ -357,7 +356,7 @@ func GetVueCompLinkListWidget (p ProjectType, d DocType, tableName string, opts
 					} else {
 						depDoc := p.GetDocByName(f.Sql.Ref)
 						if depDoc == nil {
-							log.Fatalf(fmt.Sprintf("GetVueCompLinkListWidget not found '%s'", f.Sql.Ref))
+							utils.Fatalf("GetVueCompLinkListWidget not found '%s'", f.Sql.Ref)
 						}
 						tableDependName = depDoc.Name
 						tableDependRoute = depDoc.Vue.RouteName
@@ -438,19 +437,19 @@ type VueCompRefListWidgetParams struct {
 // создание поля-виджета со связями один-к-многим
 func GetFldVueCompositionRefList (d *DocType, refDoc VueCompRefListWidgetParams, rowCol [][]int, params... string) (fld FldType) {
 	if len(refDoc.Label) == 0 {
-		log.Fatalf("doc: '%s'. Missed Label in FldVueCompositionRefList", d.Name)
+		utils.Fatalf("doc: '%s'. Missed Label in FldVueCompositionRefList", d.Name)
 	}
 	if len(refDoc.FldName) == 0 {
-		log.Fatalf("doc: '%s'. Missed FldName in FldVueCompositionRefList", d.Name)
+		utils.Fatalf("doc: '%s'. Missed FldName in FldVueCompositionRefList", d.Name)
 	}
 	if len(refDoc.TableName) == 0 {
-		log.Fatalf("doc: '%s'. Missed TableName in FldVueCompositionRefList", d.Name)
+		utils.Fatalf("doc: '%s'. Missed TableName in FldVueCompositionRefList", d.Name)
 	}
 	if len(refDoc.RefFldName) == 0 {
-		log.Fatalf("doc: '%s'. Missed RefFldName in FldVueCompositionRefList", d.Name)
+		utils.Fatalf("doc: '%s'. Missed RefFldName in FldVueCompositionRefList", d.Name)
 	}
 	if len(refDoc.Avatar) == 0 {
-		log.Fatalf("doc: '%s'. Missed Avatar in FldVueCompositionRefList", d.Name)
+		utils.Fatalf("doc: '%s'. Missed Avatar in FldVueCompositionRefList", d.Name)
 	}
 	//var refFldName, refTableRoute string
 	fldName := refDoc.FldName + "RefListWidget"
@@ -487,7 +486,7 @@ func GetFldVueCompositionRefList (d *DocType, refDoc VueCompRefListWidgetParams,
 	}
 	d.Templates[fmt.Sprintf("%s_ref_list_widget", refDoc.FldName)] = &DocTemplate{
 		Source:       fmt.Sprintf("%s/templates/webClient/quasar_%v/doc/comp/refListWidget.vue", getRootDirPath(), d.GetProject().GetQuasarVersion()),
-		DistPath:     fmt.Sprintf("../src/webClient/src/app/components/%s/comp", d.PgName()),
+		DistPath:     fmt.Sprintf("%s/webClient/src/app/components/%s/comp", DefaultDistPath, d.PgName()),
 		DistFilename: snaker.SnakeToCamelLower(refDoc.FldName) + "RefListWidget.vue",
 		FuncMap: map[string]interface{}{
 			"GetTableName": func() string {return refDoc.TableName},
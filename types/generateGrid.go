@@ -4,6 +4,10 @@ package types
 func makeGrid(doc DocType) []VueGridDiv {
 	res := []VueGridDiv{}
 	for _, fld := range doc.Flds {
+		// некорректный RowCol попадает в отчет Validate, в сетку такое поле не добавляем
+		if !isRowColValid(fld.Vue.RowCol) {
+			continue
+		}
 		var cell *VueGridDiv
 		for i, arr := range fld.Vue.RowCol {
 			// дефолтная ширина колонки, которую перезаписываем классом в зависимости от уровня вложенности
@@ -25,6 +29,15 @@ func makeGrid(doc DocType) []VueGridDiv {
 	return res
 }
 
+func isRowColValid(rowCol [][]int) bool {
+	for _, arr := range rowCol {
+		if len(arr) < 2 || arr[0] < 1 || arr[1] < 1 {
+			return false
+		}
+	}
+	return true
+}
+
 // функция по созданию или получению существующей ячейки из сетки
 func getCell(grid *[]VueGridDiv, rowNum, colNum int, class string) *VueGridDiv {
 	// создание строки если необходимо
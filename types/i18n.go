@@ -14,6 +14,7 @@ func (p *ProjectType) FillI18n()  {
 				"file": "файл",
 				"files": "файлы",
 				"photo": "фото",
+				"doc_deleted": "документ удален",
 			},
 			"auth": {
 				"login": "войти",
@@ -79,6 +80,7 @@ func (p *ProjectType) FillI18n()  {
 				"file": "file",
 				"files": "files",
 				"photo": "photo",
+				"doc_deleted": "document deleted",
 			},
 			"auth": {
 				"login": "login",
@@ -0,0 +1,464 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tvitcom/nla_framework/utils"
+	"sort"
+	"strings"
+)
+
+type (
+	// OpenApiDoc - описание api сгенерированного сервера в формате OpenAPI 3
+	OpenApiDoc struct {
+		OpenApi    string                                  `json:"openapi"`
+		Info       OpenApiInfo                             `json:"info"`
+		Servers    []OpenApiServer                         `json:"servers,omitempty"`
+		Paths      map[string]map[string]*OpenApiOperation `json:"paths"` // url -> http метод -> операция
+		Components OpenApiComponents                       `json:"components"`
+	}
+
+	OpenApiInfo struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+
+	OpenApiServer struct {
+		Url string `json:"url"`
+	}
+
+	OpenApiOperation struct {
+		Summary     string                      `json:"summary,omitempty"`
+		Description string                      `json:"description,omitempty"`
+		Tags        []string                    `json:"tags,omitempty"`
+		Parameters  []OpenApiParameter          `json:"parameters,omitempty"`
+		RequestBody *OpenApiRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenApiResponse `json:"responses"`
+		Security    []map[string][]string       `json:"security"`
+	}
+
+	OpenApiParameter struct {
+		Name     string         `json:"name"`
+		In       string         `json:"in"`
+		Required bool           `json:"required"`
+		Schema   *OpenApiSchema `json:"schema"`
+	}
+
+	OpenApiRequestBody struct {
+		Required bool                         `json:"required"`
+		Content  map[string]*OpenApiMediaType `json:"content"`
+	}
+
+	OpenApiResponse struct {
+		Description string                       `json:"description"`
+		Content     map[string]*OpenApiMediaType `json:"content,omitempty"`
+	}
+
+	OpenApiMediaType struct {
+		Schema *OpenApiSchema `json:"schema"`
+	}
+
+	OpenApiSchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Enum                 []interface{}             `json:"enum,omitempty"`
+		Items                *OpenApiSchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenApiSchema `json:"properties,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		OneOf                []*OpenApiSchema          `json:"oneOf,omitempty"`
+		Discriminator        *OpenApiDiscriminator     `json:"discriminator,omitempty"`
+		AdditionalProperties interface{}               `json:"additionalProperties,omitempty"`
+		Roles                []string                  `json:"x-roles,omitempty"` // роли, которым разрешен вызов pg метода. Пустой список - разрешено всем авторизованным
+	}
+
+	OpenApiDiscriminator struct {
+		PropertyName string            `json:"propertyName"`
+		Mapping      map[string]string `json:"mapping,omitempty"`
+	}
+
+	OpenApiComponents struct {
+		Schemas         map[string]*OpenApiSchema         `json:"schemas"`
+		SecuritySchemes map[string]*OpenApiSecurityScheme `json:"securitySchemes"`
+	}
+
+	OpenApiSecurityScheme struct {
+		Type string `json:"type"`
+		In   string `json:"in"`
+		Name string `json:"name"`
+	}
+)
+
+const openApiSchemaRefPrefix = "#/components/schemas/"
+
+// ApiPgMethods список postgres функций, которые разрешено вызывать через /api/call_pg_func (кроме встроенных методов user_*).
+// Отсортирован, чтобы порядок не менялся от генерации к генерации
+func (p ProjectType) ApiPgMethods() []DocSqlMethod {
+	res := []DocSqlMethod{}
+	keys := []string{}
+	for k := range p.Sql.Methods {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		arr := append([]DocSqlMethod{}, p.Sql.Methods[k]...)
+		sort.SliceStable(arr, func(i, j int) bool { return arr[i].Name < arr[j].Name })
+		res = append(res, arr...)
+	}
+	for _, d := range p.Docs {
+		arr := []DocSqlMethod{}
+		for _, m := range d.Sql.Methods {
+			arr = append(arr, *m)
+		}
+		sort.Slice(arr, func(i, j int) bool { return arr[i].Name < arr[j].Name })
+		res = append(res, arr...)
+	}
+	return res
+}
+
+// встроенные методы для таблицы user, которые прописаны в pgFuncList (webServer/apiCallPgFunc.go)
+func (p ProjectType) apiUserPgMethods() []DocSqlMethod {
+	res := []DocSqlMethod{
+		{Name: "user_update", Roles: append([]string{"admin"}, p.Config.User.Roles.UserUpdate...)},
+		{Name: "user_list", Roles: p.Config.User.Roles.UserList},
+		{Name: "user_get_by_id"},
+		{Name: "user_get_by_id_for_ui"},
+		{Name: "current_user_update"},
+		{Name: "current_user_get_auth_providers"},
+	}
+	if p.IsAuthSession() {
+		res = append(res,
+			DocSqlMethod{Name: "user_session_list", Roles: []string{"admin"}},
+			DocSqlMethod{Name: "user_session_revoke", Roles: []string{"admin"}},
+			DocSqlMethod{Name: "current_user_session_list"},
+			DocSqlMethod{Name: "current_user_session_revoke"},
+		)
+	}
+	return res
+}
+
+// документ, к которому относится pg метод. Ключ - название метода
+func (p ProjectType) apiPgMethodDocs() map[string]DocType {
+	res := map[string]DocType{}
+	for _, d := range p.Docs {
+		for name := range d.Sql.Methods {
+			res[name] = d
+		}
+	}
+	return res
+}
+
+// PrintOpenApiSpec печать webServer/openapi.json
+func (p ProjectType) PrintOpenApiSpec() string {
+	res, err := json.MarshalIndent(p.OpenApiSpec(), "", "  ")
+	utils.CheckErr(err, "PrintOpenApiSpec")
+	return string(res)
+}
+
+// OpenApiSpec описание всех роутов webServer/main.go и всех pg методов, доступных через /api/call_pg_func
+func (p ProjectType) OpenApiSpec() OpenApiDoc {
+	res := OpenApiDoc{
+		OpenApi: "3.0.3",
+		Info:    OpenApiInfo{Title: p.Name, Version: "1.0.0"},
+		Paths:   map[string]map[string]*OpenApiOperation{},
+		Components: OpenApiComponents{
+			Schemas: map[string]*OpenApiSchema{
+				"User":  openApiUserSchema(),
+				"Error": openApiObject(map[string]*OpenApiSchema{"ok": {Type: "boolean", Enum: []interface{}{false}}, "message": {Type: "string"}}, "ok", "message"),
+			},
+			SecuritySchemes: map[string]*OpenApiSecurityScheme{
+				"authTokenHeader": {Type: "apiKey", In: "header", Name: "Auth-token"},
+				"authTokenQuery":  {Type: "apiKey", In: "query", Name: "authToken"},
+			},
+		},
+	}
+	if len(p.Config.WebServer.Url) > 0 {
+		res.Servers = []OpenApiServer{{Url: p.Config.WebServer.Url}}
+	}
+	for _, d := range p.Docs {
+		res.Components.Schemas[d.NameCamelCase()] = d.OpenApiSchema()
+	}
+
+	// авторизация
+	authParams := func(flds ...string) *OpenApiSchema {
+		props := map[string]*OpenApiSchema{}
+		for _, f := range flds {
+			arr := strings.Split(f, ":")
+			props[arr[0]] = openApiTypeSchema(arr[1])
+		}
+		return openApiObject(map[string]*OpenApiSchema{"params": openApiObject(props)}, "params")
+	}
+	res.addOperation("/auth/email", "post", "Авторизация или регистрация по email", "auth", authParams("login:string", "password:string", "last_name:string", "first_name:string", "is_register:bool"), openApiRef("User"), nil, false)
+	res.addOperation("/auth/check_user_email", "post", "Подтверждение email при регистрации", "auth", authParams("token:string"), openApiRef("User"), nil, false)
+	res.addOperation("/auth/email_auth_start_recover_password", "post", "Отправка письма для восстановления пароля", "auth", authParams("email:string"), nil, nil, false)
+	res.addOperation("/auth/email_auth_recover_password", "post", "Восстановление пароля по токену из письма", "auth", authParams("password:string", "token:string", "is_token_check:bool"), openApiRef("User"), nil, false)
+	if p.IsAuthSession() {
+		res.addOperation("/auth/refresh", "post", "Новые access и refresh токены сессии по refresh токену", "auth", authParams("refresh_token:string"), openApiRef("User"), nil, false)
+	}
+	if p.Config.Auth.ByPhone {
+		res.addOperation("/auth/phone", "post", "Авторизация или регистрация по номеру телефона", "auth", authParams("login:string", "password:string", "last_name:string", "first_name:string", "options:jsonb", "is_register:bool"), openApiRef("User"), nil, false)
+		res.addOperation("/auth/check_sms_code", "post", "Проверка кода из sms", "auth", authParams("phone:string", "token:string"), openApiRef("User"), nil, false)
+		res.addOperation("/auth/phone_auth_start_recover_password", "post", "Отправка sms для восстановления пароля", "auth", authParams("phone:string", "token:string"), nil, nil, false)
+		res.addOperation("/auth/phone_auth_recover_password", "post", "Восстановление пароля по коду из sms", "auth", authParams("phone:string", "token:string", "password:string"), openApiRef("User"), nil, false)
+	}
+	// количество запросов к /auth ограничено (webServer/auth/rateLimit.go)
+	for url, ops := range res.Paths {
+		if strings.HasPrefix(url, "/auth/") {
+			for _, op := range ops {
+				op.Responses["429"] = &OpenApiResponse{Description: "превышено количество запросов или логин временно заблокирован. Заголовок Retry-After - через сколько секунд можно повторить", Content: openApiJson(openApiRef("Error"))}
+			}
+		}
+	}
+
+	// api
+	res.addOperation("/api/current_user", "post", "Текущий пользователь", "api", openApiObject(nil), openApiRef("User"), nil, true)
+	if p.IsAuthSession() {
+		res.addOperation("/api/logout", "post", "Выход: отзыв текущей сессии", "api", openApiObject(nil), nil, nil, true)
+		res.addOperation("/api/logout_all", "post", "Выход на всех устройствах", "api", openApiObject(nil), nil, nil, true)
+	}
+	res.addPgMethods(p)
+	res.addRestPaths(p)
+	res.addOperation("/api/log", "post", "Запись в лог приложения с клиента", "api", openApiObject(map[string]*OpenApiSchema{"params": {Type: "object"}}), nil, nil, true)
+	sseParams := []OpenApiParameter{
+		{Name: "topics", In: "query", Schema: &OpenApiSchema{Type: "string", Description: "топики через запятую (doc:<table>, doc:<table>:<id>, chat:<id>). Топик user:<id> текущего пользователя добавляется автоматически"}},
+		{Name: "Last-Event-ID", In: "header", Schema: &OpenApiSchema{Type: "string", Description: "id последнего полученного события для повтора пропущенных"}},
+	}
+	sse := res.addOperation("/api/sse", "get", "Подключение по SSE", "api", nil, nil, sseParams, true)
+	sse.Responses["200"] = &OpenApiResponse{Description: "поток событий", Content: map[string]*OpenApiMediaType{"text/event-stream": {Schema: &OpenApiSchema{Type: "string"}}}}
+	ws := res.addOperation("/api/ws", "get", "Подключение по websocket: события из топиков и вызов pg методов", "api", nil, nil, sseParams, true)
+	ws.Responses["101"] = &OpenApiResponse{Description: "переключение на протокол websocket"}
+	fileToken := []OpenApiParameter{{Name: "fileToken", In: "path", Required: true, Schema: &OpenApiSchema{Type: "string"}}}
+	res.addMultipartOperation("/api/upload_file", "Загрузка файла")
+	download := res.addOperation("/api/file/{fileToken}", "get", "Скачивание файла", "api", nil, nil, fileToken, true)
+	download.Responses["200"] = &OpenApiResponse{Description: "файл", Content: map[string]*OpenApiMediaType{"application/octet-stream": {Schema: &OpenApiSchema{Type: "string", Format: "binary"}}}}
+	res.addOperation("/api/remove_file/{fileToken}", "post", "Удаление файла", "api", nil, nil, fileToken, true)
+	res.addMultipartOperation("/api/upload_image", "Загрузка изображения")
+	res.addMultipartOperation("/api/upload_profile_image", "Загрузка аватара пользователя")
+	if p.IsTelegramIntegration() {
+		res.addOperation("/api/telegram_auth", "post", "Привязка аккаунта telegram", "telegram", openApiObject(map[string]*OpenApiSchema{"params": {Type: "object"}}), nil, nil, true)
+	}
+	for _, d := range p.Docs {
+		if d.IsBitrixIntegration() {
+			res.addOperation("/api/bitrix/import_"+d.Name, "post", "Импорт из Битрикс: "+d.NameRu, "bitrix", nil, nil, nil, true)
+		}
+		if d.IsBitrixIntegrationDebugMode() {
+			res.addOperation("/bitrix/import_"+d.Name, "get", "Импорт из Битрикс (отладка): "+d.NameRu, "bitrix", nil, nil, nil, false)
+		}
+		if d.IsOdataIntegration() {
+			res.addOperation("/api/odata/import_"+d.Name, "post", "Импорт из 1С: "+d.NameRu, "odata", nil, nil, nil, true)
+		}
+		if d.IsOdataIntegrationDebugMode() {
+			res.addOperation("/odata/import_"+d.Name, "get", "Импорт из 1С (отладка): "+d.NameRu, "odata", nil, nil, nil, false)
+		}
+	}
+	return res
+}
+
+// OpenApiSchema схема записи документа. Поля, которые хранятся в options, описываются внутри options
+func (d DocType) OpenApiSchema() *OpenApiSchema {
+	props := map[string]*OpenApiSchema{"id": {Type: "integer", Format: "int64"}}
+	required := []string{"id"}
+	optionProps := map[string]*OpenApiSchema{}
+	optionRequired := []string{}
+	for i := range d.Flds {
+		fld := d.Flds[i]
+		if len(fld.Name) == 0 || fld.Type == FldTypeVueComposition {
+			continue
+		}
+		s := fld.OpenApiSchema()
+		if fld.Sql.IsOptionFld {
+			optionProps[fld.Name] = s
+			if fld.Sql.IsRequired {
+				optionRequired = append(optionRequired, fld.Name)
+			}
+			continue
+		}
+		props[fld.Name] = s
+		if fld.Sql.IsRequired {
+			required = append(required, fld.Name)
+		}
+	}
+	props["options"] = openApiObject(optionProps, optionRequired...)
+	props["options"].AdditionalProperties = true
+	props["created_at"] = &OpenApiSchema{Type: "string", Format: "date-time"}
+	props["updated_at"] = &OpenApiSchema{Type: "string", Format: "date-time"}
+	props["deleted"] = &OpenApiSchema{Type: "boolean"}
+	res := openApiObject(props, required...)
+	res.Description = d.NameRu
+	return res
+}
+
+// OpenApiSchema схема поля по его типу. Варианты выбора из Vue.Options описываются как enum
+func (fld *FldType) OpenApiSchema() *OpenApiSchema {
+	var res *OpenApiSchema
+	switch fld.Type {
+	case FldTypeJsonb:
+		if utils.CheckContainsSliceStr(fld.Vue.Type, FldVueTypeJsonList, FldVueTypeFiles, FldVueTypeImgList) {
+			res = &OpenApiSchema{Type: "array", Items: &OpenApiSchema{Type: "object"}}
+		} else {
+			res = openApiTypeSchema(fld.Type)
+		}
+	default:
+		res = openApiTypeSchema(fld.GoType())
+		if fld.Type == FldTypeDate {
+			res.Format = "date"
+		}
+		if fld.Type == FldTypeDatetime {
+			res.Format = "date-time"
+		}
+		if fld.Type == FldTypeUuid {
+			res.Format = "uuid"
+		}
+	}
+	res.Description = fld.NameRu
+	if len(fld.Sql.Ref) > 0 {
+		res.Description = fmt.Sprintf("%s (id записи %s)", fld.NameRu, fld.Sql.Ref)
+	}
+	for _, o := range fld.Vue.Options {
+		if o.Value != nil {
+			res.Enum = append(res.Enum, o.Value)
+		}
+	}
+	return res
+}
+
+// схема по названию go типа (результат FldType.GoType) или типа параметра в DocSqlMethod.Params
+func openApiTypeSchema(goType string) *OpenApiSchema {
+	switch goType {
+	case "string", FldTypeText, FldTypeUuid:
+		return &OpenApiSchema{Type: "string"}
+	case "int":
+		return &OpenApiSchema{Type: "integer"}
+	case "int64":
+		return &OpenApiSchema{Type: "integer", Format: "int64"}
+	case "float64", FldTypeDouble:
+		return &OpenApiSchema{Type: "number"}
+	case "bool":
+		return &OpenApiSchema{Type: "boolean"}
+	case "[]string", FldTypeTextArray:
+		return &OpenApiSchema{Type: "array", Items: &OpenApiSchema{Type: "string"}}
+	case "[]int", FldTypeIntArray:
+		return &OpenApiSchema{Type: "array", Items: &OpenApiSchema{Type: "integer"}}
+	case FldTypeDate:
+		return &OpenApiSchema{Type: "string", Format: "date"}
+	case FldTypeDatetime:
+		return &OpenApiSchema{Type: "string", Format: "date-time"}
+	default:
+		return &OpenApiSchema{Type: "object"}
+	}
+}
+
+func openApiUserSchema() *OpenApiSchema {
+	props := map[string]*OpenApiSchema{}
+	for _, f := range []string{"id:int64", "username:string", "first_name:string", "last_name:string", "fullname:string", "avatar:string", "role:[]string", "state:string", "auth_provider:string", "auth_provider_id:string", "auth_token:string", "refresh_token:string", "access_expires_in:int64", "session_id:int64", "options:jsonb", "deleted:bool", "phone:string", "email:string"} {
+		arr := strings.Split(f, ":")
+		props[arr[0]] = openApiTypeSchema(arr[1])
+	}
+	return openApiObject(props, "id")
+}
+
+func openApiObject(props map[string]*OpenApiSchema, required ...string) *OpenApiSchema {
+	return &OpenApiSchema{Type: "object", Properties: props, Required: required}
+}
+
+func openApiRef(name string) *OpenApiSchema {
+	return &OpenApiSchema{Ref: openApiSchemaRefPrefix + name}
+}
+
+// схема ответа: {ok: true, result: ...}
+func openApiSuccess(result *OpenApiSchema) *OpenApiSchema {
+	if result == nil {
+		result = &OpenApiSchema{}
+	}
+	return openApiObject(map[string]*OpenApiSchema{"ok": {Type: "boolean", Enum: []interface{}{true}}, "result": result}, "ok")
+}
+
+func openApiJson(s *OpenApiSchema) map[string]*OpenApiMediaType {
+	return map[string]*OpenApiMediaType{"application/json": {Schema: s}}
+}
+
+func (o *OpenApiDoc) addOperation(url, method, summary, tag string, body, result *OpenApiSchema, params []OpenApiParameter, isAuth bool) *OpenApiOperation {
+	op := &OpenApiOperation{
+		Summary:    summary,
+		Tags:       []string{tag},
+		Parameters: params,
+		Responses: map[string]*OpenApiResponse{
+			// ошибки сервер возвращает со статусом 200 и ok: false
+			"200": {Description: "ok", Content: openApiJson(&OpenApiSchema{OneOf: []*OpenApiSchema{openApiSuccess(result), openApiRef("Error")}})},
+		},
+		Security: []map[string][]string{},
+	}
+	if body != nil {
+		op.RequestBody = &OpenApiRequestBody{Required: true, Content: openApiJson(body)}
+	}
+	if isAuth {
+		op.Security = []map[string][]string{{"authTokenHeader": {}}, {"authTokenQuery": {}}}
+	}
+	if o.Paths[url] == nil {
+		o.Paths[url] = map[string]*OpenApiOperation{}
+	}
+	o.Paths[url][method] = op
+	return op
+}
+
+func (o *OpenApiDoc) addMultipartOperation(url, summary string) {
+	op := o.addOperation(url, "post", summary, "file", nil, nil, nil, true)
+	op.RequestBody = &OpenApiRequestBody{Required: true, Content: map[string]*OpenApiMediaType{
+		"multipart/form-data": {Schema: openApiObject(map[string]*OpenApiSchema{"file": {Type: "string", Format: "binary"}}, "file")},
+	}}
+}
+
+// /api/call_pg_func - один url для всех pg методов. Каждый метод описан отдельной схемой, вариант выбирается по полю method
+func (o *OpenApiDoc) addPgMethods(p ProjectType) {
+	methods := append(p.apiUserPgMethods(), p.ApiPgMethods()...)
+	docByMethod := p.apiPgMethodDocs()
+
+	variants := []*OpenApiSchema{}
+	mapping := map[string]string{}
+	for _, m := range methods {
+		params := &OpenApiSchema{Type: "object", AdditionalProperties: true}
+		if d, ok := docByMethod[m.Name]; ok {
+			params = openApiPgMethodParams(d, strings.TrimPrefix(m.Name, d.Name+"_"))
+		}
+		for k, v := range m.Params {
+			if params.Properties == nil {
+				params.Properties = map[string]*OpenApiSchema{}
+			}
+			params.Properties[k] = openApiTypeSchema(v)
+		}
+		name := "PgMethod_" + m.Name
+		s := openApiObject(map[string]*OpenApiSchema{
+			"method": {Type: "string", Enum: []interface{}{m.Name}},
+			"params": params,
+		}, "method")
+		s.Roles = m.Roles
+		o.Components.Schemas[name] = s
+		variants = append(variants, openApiRef(name))
+		mapping[m.Name] = openApiSchemaRefPrefix + name
+	}
+	body := &OpenApiSchema{OneOf: variants, Discriminator: &OpenApiDiscriminator{PropertyName: "method", Mapping: mapping}}
+	op := o.addOperation("/api/call_pg_func", "post", "Вызов postgres функции", "api", body, nil, nil, true)
+	op.Description = "Список разрешенных методов и роли для них описаны в схемах PgMethod_*, роли в поле x-roles. Если роли не указаны, метод доступен всем авторизованным пользователям"
+	op.Responses["400"] = &OpenApiResponse{Description: "ошибка при выполнении функции", Content: openApiJson(openApiRef("Error"))}
+	op.Responses["504"] = &OpenApiResponse{Description: "превышен таймаут выполнения функции", Content: openApiJson(openApiRef("Error"))}
+}
+
+// параметры базовых методов документа: list, update, get_by_id
+func openApiPgMethodParams(d DocType, method string) *OpenApiSchema {
+	switch method {
+	case "list":
+		return openApiObject(map[string]*OpenApiSchema{
+			"deleted":     {Type: "boolean", Description: "удаленные / существующие. Дефолт: false"},
+			"order_by":    {Type: "string", Description: "поле для сортировки и направление сортировки, например 'id desc'"},
+			"page":        {Type: "integer", Description: "номер страницы. Дефолт: 1"},
+			"per_page":    {Type: "integer", Description: "количество записей на странице. Дефолт: 1000"},
+			"search_text": {Type: "string", Description: "текстовый поиск"},
+		})
+	case "get_by_id":
+		return openApiObject(map[string]*OpenApiSchema{"id": {Type: "integer", Format: "int64"}}, "id")
+	case "update":
+		return openApiRef(d.NameCamelCase())
+	}
+	return &OpenApiSchema{Type: "object", AdditionalProperties: true}
+}
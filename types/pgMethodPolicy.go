@@ -0,0 +1,106 @@
+package types
+
+import (
+	"fmt"
+	"github.com/tvitcom/nla_framework/utils"
+	"sort"
+	"strings"
+)
+
+const (
+	// PgMethodHookAddUserId встроенный hook: добавляет в параметры user_id текущего пользователя (BeforeHookAddUserId)
+	PgMethodHookAddUserId = "addUserId"
+
+	PgMethodCacheScopeUser   = "user"   // отдельный кэш для каждого пользователя
+	PgMethodCacheScopeRole   = "role"   // отдельный кэш для каждого набора ролей
+	PgMethodCacheScopeParams = "params" // отдельный кэш для каждого набора параметров
+)
+
+// AddPgMethodHook добавление before hook'а, который можно указать в DocSqlMethod.Hooks.
+// code - go выражение типа func(*gin.Context, interface{}) error в пакете webServer: литерал функции или название функции,
+// например "func(c *gin.Context, p interface{}) error { return nil }"
+func (p *ProjectType) AddPgMethodHook(name, code string) {
+	if len(name) == 0 || len(code) == 0 {
+		utils.Fatalf("AddPgMethodHook: empty name or code")
+	}
+	if name == PgMethodHookAddUserId {
+		utils.Fatalf("AddPgMethodHook: '%s' is builtin hook", name)
+	}
+	if p.Sql.MethodHooks == nil {
+		p.Sql.MethodHooks = map[string]string{}
+	}
+	if _, ok := p.Sql.MethodHooks[name]; ok {
+		utils.Fatalf("AddPgMethodHook: hook '%s' already exist", name)
+	}
+	p.Sql.MethodHooks[name] = code
+}
+
+// IsPgMethodPolicy в проекте есть pg методы с кэшем или цепочкой hook'ов - генерируется webServer/pgMethodHooks.go
+func (p ProjectType) IsPgMethodPolicy() bool {
+	if len(p.Sql.MethodHooks) > 0 {
+		return true
+	}
+	for _, m := range p.ApiPgMethods() {
+		if m.Cache != nil {
+			return true
+		}
+		if len(m.Hooks) > 0 && !(len(m.Hooks) == 1 && m.Hooks[0] == PgMethodHookAddUserId) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintPgMethodHooks печать hook'ов, добавленных через AddPgMethodHook (webServer/pgMethodHooks.go)
+func (p ProjectType) PrintPgMethodHooks() string {
+	names := []string{}
+	for k := range p.Sql.MethodHooks {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	arr := []string{}
+	for _, name := range names {
+		arr = append(arr, fmt.Sprintf("\t\t\"%s\": %s,", name, p.Sql.MethodHooks[name]))
+	}
+	return strings.Join(arr, "\n")
+}
+
+// печать полей BeforeHook и Cache для PgMethod в pgFuncList
+func (p ProjectType) printPgMethodPolicy(m DocSqlMethod) string {
+	res := ""
+	hooks := m.Hooks
+	if hooks == nil {
+		hooks = []string{PgMethodHookAddUserId}
+	}
+	for _, h := range hooks {
+		if _, ok := p.Sql.MethodHooks[h]; !ok && h != PgMethodHookAddUserId {
+			utils.Fatalf("pg method '%s': unknown hook '%s'. Add it by ProjectType.AddPgMethodHook", m.Name, h)
+		}
+	}
+	if len(hooks) == 1 && hooks[0] == PgMethodHookAddUserId {
+		res += ", BeforeHook: BeforeHookAddUserId"
+	} else if len(hooks) > 0 {
+		res += fmt.Sprintf(`, BeforeHook: pgMethodHookChain("%s")`, strings.Join(hooks, `", "`))
+	}
+
+	if m.Cache != nil {
+		if m.Cache.Ttl <= 0 {
+			utils.Fatalf("pg method '%s': cache ttl must be positive", m.Name)
+		}
+		for _, s := range m.Cache.Scope {
+			if s != PgMethodCacheScopeUser && s != PgMethodCacheScopeRole && s != PgMethodCacheScopeParams {
+				utils.Fatalf("pg method '%s': unknown cache scope '%s'", m.Name, s)
+			}
+		}
+		// результат метода зависит от пользователя - без scope user он попадет из кэша к другим пользователям
+		if utils.CheckContainsSliceStr(PgMethodHookAddUserId, hooks...) && !utils.CheckContainsSliceStr(PgMethodCacheScopeUser, m.Cache.Scope...) {
+			utils.Fatalf("pg method '%s': cache of method with hook '%s' must have scope '%s'", m.Name, PgMethodHookAddUserId, PgMethodCacheScopeUser)
+		}
+		scope := ""
+		if len(m.Cache.Scope) > 0 {
+			scope = fmt.Sprintf(`, "%s"`, strings.Join(m.Cache.Scope, `", "`))
+		}
+		res += fmt.Sprintf(`, Cache: newPgMethodCache("%s", %v%s)`, m.Name, m.Cache.Ttl, scope)
+	}
+	return res
+}
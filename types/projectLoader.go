@@ -0,0 +1,358 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Описание проекта в виде YAML/JSON файлов - альтернатива описанию через Go код.
+// Файл проекта содержит поля ProjectType и список файлов документов (DocFiles), по одному документу в файле.
+// Названия полей такие же как в Go структурах, регистр не важен (name, nameRu, vue.routeName...).
+//
+// Пример файла документа:
+//
+//	name: client
+//	nameRu: клиент
+//	vue: {routeName: client, menuIcon: image/client.svg, roles: [admin]}
+//	isBaseTemplates: {vue: true, sql: true}
+//	sqlBaseMethodsRoles: [admin]
+//	flds:
+//	  - {kind: title}
+//	  - {kind: string, name: inn, nameRu: ИНН, size: 20, rowCol: [[1, 2]], isSearch: true}
+//	  - {kind: ref, name: manager_id, nameRu: менеджер, ref: user, rowCol: [[2, 1]], params: [isShowLink]}
+//
+// Функции (Vue.Composition, TmplFuncs, FuncMap) описать в файле нельзя - такие документы описываются в Go коде.
+type (
+	ProjectDefinition struct {
+		ProjectType
+		DocFiles []string // пути к файлам документов относительно файла проекта. Можно указывать маску, например docs/*.yaml
+	}
+
+	DocDefinition struct {
+		DocType
+		Flds                []FldDefinition
+		SqlBaseMethodsRoles []string                      // если указано, то добавляются стандартные методы list, update, get_by_id с этими ролями (DocSql.FillBaseMethods)
+		IsVueTaskAndTabs    bool                          // DocType.AddVueTaskAndTabs
+		RecursionTitle      string                        // если указано, то документ рекурсивный (DocType.SetIsRecursion)
+		VueCompositions     []DocDefinitionVueComposition // DocType.AddVueComposition
+		CustomTemplates     []string                      // GetCustomTemplates
+		StateMachineParams  map[string]interface{}        // параметры для DocSm.GenerateTmpls
+	}
+
+	DocDefinitionVueComposition struct {
+		TmplName string
+		CompName string
+	}
+
+	// FldDefinition - описание поля. Kind - название shortcut функции без префикса GetFld (string, ref, selectString...).
+	// Если Kind не указан, то поле читается из Fld как есть
+	FldDefinition struct {
+		Kind        string
+		Name        string
+		NameRu      string
+		Size        int
+		RowCol      [][]int
+		Params      []string // те же параметры, что передаются в shortcut функции
+		Options     []FldVueOptionsItem
+		Ref         string // таблица для ref и linkListWidget
+		ClassStr    string
+		CompName    string                 // jsonbComposition
+		Html        string                 // simpleHtml
+		TriggerSql  string                 // titleComputed
+		LinkOpts    map[string]interface{} // linkListWidget
+		FilesParams FldVueFilesParams
+		ImgParams   FldVueImgParams
+		JsonList    struct {
+			Flds []FldDefinition
+			Icon string
+		}
+		Fld FldType
+		// модификаторы - аналоги Set* методов
+		IsRequired     bool
+		IsUniq         bool
+		IsNotUniq      bool
+		IsSearch       bool
+		IsOptionFld    bool
+		IsNotUpdatable bool
+		IsHide         bool
+		IsBorderless   bool
+		Default        string
+		SqlSize        int
+		PrevName       string
+		Readonly       string
+		Vif            string
+		Bitrix         *BitrixFld
+		Odata          *OdataFld
+	}
+)
+
+// LoadProject читает описание проекта из YAML или JSON файла и файлов документов.
+// Результат такой же, как при описании через Go код: документы проинициализированы, заполнены роуты и боковое меню
+func LoadProject(filename string) (*ProjectType, error) {
+	pd := ProjectDefinition{}
+	if err := readDefinitionFile(filename, &pd); err != nil {
+		return nil, err
+	}
+	p := pd.ProjectType
+	p.Docs = []DocType{}
+	dir := filepath.Dir(filename)
+	for _, pattern := range pd.DocFiles {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("LoadProject docFiles '%s': %s", pattern, err)
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("LoadProject docFiles '%s': no files found", pattern)
+		}
+		sort.Strings(files)
+		for _, f := range files {
+			d, err := LoadDoc(&p, f)
+			if err != nil {
+				return nil, err
+			}
+			p.Docs = append(p.Docs, d)
+		}
+	}
+	p.FillVueBaseRoutes()
+	if p.Vue.Menu != nil {
+		p.FillSideMenu()
+	}
+	return &p, nil
+}
+
+// LoadDoc читает описание документа из YAML или JSON файла
+func LoadDoc(p *ProjectType, filename string) (DocType, error) {
+	dd := DocDefinition{}
+	if err := readDefinitionFile(filename, &dd); err != nil {
+		return DocType{}, err
+	}
+	d := dd.DocType
+	d.Project = p
+	d.Flds = []FldType{}
+	for i, fd := range dd.Flds {
+		fld, err := fd.Build()
+		if err != nil {
+			return DocType{}, fmt.Errorf("%s: doc '%s' fld #%v: %s", filename, d.Name, i+1, err)
+		}
+		d.Flds = append(d.Flds, fld)
+	}
+	if dd.SqlBaseMethodsRoles != nil {
+		d.Sql.FillBaseMethods(d.Name, dd.SqlBaseMethodsRoles...)
+	}
+	if dd.IsVueTaskAndTabs {
+		d.AddVueTaskAndTabs()
+	}
+	if len(dd.RecursionTitle) > 0 {
+		d.SetIsRecursion(dd.RecursionTitle)
+	}
+	if len(dd.CustomTemplates) > 0 {
+		if d.Templates == nil {
+			d.Templates = map[string]*DocTemplate{}
+		}
+		for k, v := range GetCustomTemplates(dd.CustomTemplates...) {
+			d.Templates[k] = v
+		}
+	}
+	for _, v := range dd.VueCompositions {
+		d.AddVueComposition(v.TmplName, v.CompName)
+	}
+	if d.StateMachine != nil {
+		d.StateMachine.fillUpdateFldsByName(d)
+		d.StateMachine.GenerateTmpls(&d, dd.StateMachineParams)
+	}
+	d.Init()
+	return d, nil
+}
+
+// Build создает поле через соответствующую shortcut функцию и применяет модификаторы
+func (fd FldDefinition) Build() (fld FldType, err error) {
+	switch fd.Kind {
+	case "":
+		fld = fd.Fld
+	case "title":
+		fld = GetFldTitle(fd.Params...)
+	case "titleComputed":
+		fld = GetFldTitleComputed(fd.TriggerSql, fd.Params...)
+	case "string":
+		fld = GetFldString(fd.Name, fd.NameRu, fd.Size, fd.RowCol, fd.Params...)
+	case "double":
+		fld = GetFldDouble(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "date":
+		fld = GetFldDate(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "dateTime":
+		fld = GetFldDateTime(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "int":
+		fld = GetFldInt(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "int64":
+		fld = GetFldInt64(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "uuid":
+		fld = GetFldUuid(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "checkbox":
+		fld = GetFldCheckbox(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "radioString":
+		fld = GetFldRadioString(fd.Name, fd.NameRu, fd.RowCol, fd.Options, fd.Params...)
+	case "ref":
+		fld = GetFldRef(fd.Name, fd.NameRu, fd.Ref, fd.RowCol, fd.Params...)
+	case "phone":
+		fld = GetFldPhone(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "email":
+		fld = GetFldEmail(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "jsonbComposition":
+		fld = GetFldJsonbComposition(fd.Name, fd.NameRu, fd.RowCol, fd.ClassStr, fd.CompName, fd.Params...)
+	case "jsonbCompositionWithoutFld":
+		fld = GetFldJsonbCompositionWithoutFld(fd.RowCol, fd.ClassStr, fd.CompName, fd.Params...)
+	case "simpleHtml":
+		fld = GetFldSimpleHtml(fd.RowCol, fd.ClassStr, fd.Html)
+	case "selectString":
+		fld = GetFldSelectString(fd.Name, fd.NameRu, fd.Size, fd.RowCol, fd.Options, fd.Params...)
+	case "selectMultiple":
+		fld = GetFldSelectMultiple(fd.Name, fd.NameRu, fd.RowCol, fd.Options, fd.Params...)
+	case "tag":
+		fld = GetFldTag(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "linkListWidget":
+		fld = GetFldLinkListWidget(fd.Ref, fd.RowCol, fd.ClassStr, fd.LinkOpts)
+	case "dadataAddress":
+		fld = GetFldDadataAddress(fd.Name, fd.NameRu, fd.RowCol, fd.Params...)
+	case "jsonList":
+		listParams := FldVueJsonList{Icon: fd.JsonList.Icon, Flds: []FldType{}}
+		for _, v := range fd.JsonList.Flds {
+			f, err := v.Build()
+			if err != nil {
+				return fld, err
+			}
+			listParams.Flds = append(listParams.Flds, f)
+		}
+		fld = GetFldJsonList(fd.Name, fd.NameRu, fd.RowCol, listParams, fd.Params...)
+	case "files":
+		fld = GetFldFiles(fd.Name, fd.NameRu, fd.RowCol, fd.FilesParams, fd.Params...)
+	case "imgList":
+		fld = GetFldImgList(fd.Name, fd.NameRu, fd.RowCol, fd.ImgParams, fd.Params...)
+	case "img":
+		fld = GetFldImg(fd.Name, fd.NameRu, fd.RowCol, fd.ImgParams, fd.Params...)
+	default:
+		return fld, fmt.Errorf("unknown fld kind '%s'", fd.Kind)
+	}
+
+	if fd.IsRequired {
+		fld = fld.SetIsRequired()
+	}
+	if fd.IsUniq {
+		fld = fld.SetIsUniq()
+	}
+	if fd.IsNotUniq {
+		fld = fld.SetIsNotUniq()
+	}
+	if fd.IsSearch {
+		fld = fld.SetIsSearch()
+	}
+	if fd.IsOptionFld {
+		fld = fld.SetIsOptionFld()
+	}
+	if fd.IsNotUpdatable {
+		fld = fld.SetIsNotUpdatable()
+	}
+	if fd.IsHide {
+		fld = fld.SetIsHide()
+	}
+	if fd.IsBorderless {
+		fld = fld.SetIsBorderless()
+	}
+	if len(fd.Default) > 0 {
+		fld = fld.SetDefault(fd.Default)
+	}
+	if fd.SqlSize > 0 {
+		fld = fld.SetSqlSize(fd.SqlSize)
+	}
+	if len(fd.PrevName) > 0 {
+		fld = fld.SetPrevName(fd.PrevName)
+	}
+	if len(fd.Readonly) > 0 {
+		fld = fld.SetReadonly(fd.Readonly)
+	}
+	if len(fd.Vif) > 0 {
+		fld = fld.SetVif(fd.Vif)
+	}
+	if fd.Bitrix != nil {
+		fld = fld.SetBitrixInfo(*fd.Bitrix)
+	}
+	if fd.Odata != nil {
+		fld = fld.SetOdataInfo(*fd.Odata)
+	}
+	return fld, nil
+}
+
+// в файле в UpdateFlds можно указать только название поля документа, тогда поле копируется из документа
+func (sm *DocSm) fillUpdateFldsByName(d DocType) {
+	fill := func(flds []FldType) {
+		for i, f := range flds {
+			if len(f.Type) > 0 {
+				continue
+			}
+			for _, docFld := range d.Flds {
+				if docFld.Name == f.Name {
+					flds[i] = docFld
+				}
+			}
+		}
+	}
+	for _, st := range sm.States {
+		fill(st.UpdateFlds)
+		for _, actn := range st.Actions {
+			fill(actn.UpdateFlds)
+		}
+	}
+}
+
+// чтение YAML или JSON файла в структуру. YAML приводится к JSON, чтобы названия полей
+// в обоих форматах сопоставлялись одинаково (без учета регистра)
+func readDefinitionFile(filename string, res interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+	case ".yaml", ".yml":
+		var raw interface{}
+		if err = yaml.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+		if data, err = json.Marshal(yamlToJsonValue(raw)); err != nil {
+			return fmt.Errorf("%s: %s", filename, err)
+		}
+	default:
+		return fmt.Errorf("%s: unknown definition file format. Use .yaml, .yml or .json", filename)
+	}
+	if err = json.Unmarshal(data, res); err != nil {
+		return fmt.Errorf("%s: %s", filename, err)
+	}
+	return nil
+}
+
+// в yaml ключи могут быть не строками (например числа), json такое не поддерживает
+func yamlToJsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, v1 := range val {
+			val[k] = yamlToJsonValue(v1)
+		}
+		return val
+	case map[interface{}]interface{}:
+		res := map[string]interface{}{}
+		for k, v1 := range val {
+			res[fmt.Sprint(k)] = yamlToJsonValue(v1)
+		}
+		return res
+	case []interface{}:
+		for i, v1 := range val {
+			val[i] = yamlToJsonValue(v1)
+		}
+		return val
+	}
+	return v
+}
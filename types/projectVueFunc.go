@@ -1,7 +1,7 @@
 package types
 
 import (
-	"log"
+	"github.com/tvitcom/nla_framework/utils"
 )
 
 // прописываем стандартные routes
@@ -25,7 +25,7 @@ func (p *ProjectType) FillVueBaseRoutes() {
 		itemRouteIndex := 0
 		for i, arr := range p.Vue.Routes {
 			if len(arr) < 2 {
-				log.Fatalf("'%s' in  project.Routes route: %v length < 2", arr)
+				utils.Fatalf("project.Vue.Routes route: %v length < 2", arr)
 			}
 			if arr[0] == d.Vue.RouteName {
 				indexRouteIndex = i
@@ -51,6 +51,28 @@ func (p *ProjectType) FillVueBaseRoutes() {
 			}
 		}
 	}
+	// экран сессий пользователей
+	isSessionRouteExist := false
+	for _, arr := range p.Vue.Routes {
+		if arr[0] == "user_session" {
+			isSessionRouteExist = true
+		}
+	}
+	if !isSessionRouteExist && p.IsAuthSession() {
+		p.Vue.Routes = append(p.Vue.Routes, []string{"user_session", "userSession/index.vue"})
+	}
+	// экран журнала вызовов api
+	if p.IsApiAudit() {
+		isExist := false
+		for _, arr := range p.Vue.Routes {
+			if arr[0] == "api_audit" {
+				isExist = true
+			}
+		}
+		if !isExist {
+			p.Vue.Routes = append(p.Vue.Routes, []string{"api_audit", "apiAudit/index.vue"})
+		}
+	}
 }
 
 func (pv *ProjectVue) AddRoute(r []string)  {
@@ -445,17 +445,7 @@ func GetFldImgList(name, nameRu string, rowCol [][]int, fileParams FldVueImgPara
 		ext["canAddUrls"] = "true"
 	}
 	if len(fileParams.Crop) > 0 {
-		// проверка что crop имеет формат 300x400
-		arr := strings.Split(fileParams.Crop, "x")
-		if len(arr) != 2 {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. You write this: %s", name, fileParams.Crop)
-		}
-		if _, err := strconv.Atoi(arr[0]); err != nil {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. %s not number", name, arr[0])
-		}
-		if _, err := strconv.Atoi(arr[1]); err != nil {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. %s not number", name, arr[1])
-		}
+		// формат 300x400 проверяется в Validate
 		ext["crop"] = fileParams.Crop
 	}
 	if fileParams.Width > 0 {
@@ -484,17 +474,7 @@ func GetFldImg(name, nameRu string, rowCol [][]int, fileParams FldVueImgParams,
 		ext["canAddUrls"] = "true"
 	}
 	if len(fileParams.Crop) > 0 {
-		// проверка что crop имеет формат 300x400
-		arr := strings.Split(fileParams.Crop, "x")
-		if len(arr) != 2 {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. You write this: %s", name, fileParams.Crop)
-		}
-		if _, err := strconv.Atoi(arr[0]); err != nil {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. %s not number", name, arr[0])
-		}
-		if _, err := strconv.Atoi(arr[1]); err != nil {
-			log.Fatalf("GetFldImgList error fld: '%s' in FldVueImgParams.Crop must be such format '300x400'. %s not number", name, arr[1])
-		}
+		// формат 300x400 проверяется в Validate
 		ext["crop"] = fileParams.Crop
 	}
 	if fileParams.Width > 0 {
@@ -527,7 +507,7 @@ func (vt VueTab) AddCounter(d *DocType, tabName, pgMethod, pgParams string)  Vue
 	if len(d.Vue.Path) > 0 {
 		docRouteName = d.Vue.Path
 	}
-	distPath := fmt.Sprintf("../src/webClient/src/app/components/%s/mixins", docRouteName)
+	distPath := fmt.Sprintf("%s/webClient/src/app/components/%s/mixins", DefaultDistPath, docRouteName)
 	d.Templates["webClient_mixin_tabCounter"+tabName+".js"] = &DocTemplate{
 		Source: sourcePath,
 		DistPath: distPath,
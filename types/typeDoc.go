@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/tvitcom/nla_framework/utils"
 	"github.com/serenize/snaker"
-	"log"
 	"text/template"
 )
 
@@ -29,6 +28,7 @@ type (
 		PathPrefix           string                      // префикс,если папка, в которой лежит папка с описанием документа находится не на одном уровне с main.go. Например 'docs', если docs/client/...
 		IsTaskAllowed        bool                        // признак, что к таблице можно прикреплять задачи
 		StateMachine         *DocSm
+		Realtime             *DocRealtime // рассылка изменений записей клиентам по SSE и websocket. nil - без рассылки
 		IsRecursion          bool // признак, что документ имеет рекурсию. Есть parent_id - ссылка на самого себя
 		Integrations         DocIntegrations
 		I18n map[string]map[string]string //RU : save: 'сохранить'
@@ -131,6 +131,7 @@ type (
 		IsUniqLink      bool        // флаг, что таблица является связью двух таблиц и связь между ними уникальная
 		IsBeforeTrigger bool        // флаг что добавляем before триггер
 		IsAfterTrigger  bool        // флаг что добавляем after триггер
+		IsNotifyEvent   bool        // флаг что добавляем триггер notify_event: об изменениях записей приходят события в pg.processPgEvent (сброс кэша). Для таблиц pg методов с кэшем и Realtime добавляется автоматически
 		IsSearchText    bool        // флаг что добавляем поле search_text
 		Indexes         []string    // индексы
 		Hooks           DocSqlHooks // куски sql кода
@@ -138,16 +139,44 @@ type (
 		UniqConstrains []DocSqlUniqConstraint // список ограничений на уникаальность
 	}
 
+	// DocRealtime правила рассылки изменений записей. Добавляется триггер notify_event и sql функция <doc>_realtime,
+	// которая определяет получателей. Если получатели указаны, события отправляются только в их топики user:<id>,
+	// иначе - в топики doc:<doc> и doc:<doc>:<id>. Списки и карточки документа обновляются при изменении записей
+	DocRealtime struct {
+		Ops        []string               // операции: insert, update, delete. Пусто - все
+		Recipients []DocRealtimeRecipient // получатели событий в топик user:<id>. Пусто - подписчики топиков документа
+		Flds       []string               // поля записи в событии. Пусто - измененные поля (при удалении - вся запись)
+		Roles      []string               // роли, которым разрешена подписка на топики документа. Пусто - Vue.Roles, затем роли метода <doc>_list. Если ролей нет, подписка запрещена
+	}
+
+	// DocRealtimeRecipient получатели события. Указывается одно из: Fld, LinkTable или Role
+	DocRealtimeRecipient struct {
+		Fld         string // поле документа с id пользователя, например manager_id. При изменении поля событие получают прежний и новый пользователь
+		LinkTable   string // таблица связи, например deal_member. При удалении документа каскадно удаленные связи уже не учитываются
+		LinkDocFld  string // поле в таблице связи со ссылкой на документ. Дефолт: <doc>_id
+		LinkUserFld string // поле в таблице связи со ссылкой на пользователя. Дефолт: user_id
+		Role        string // все активные пользователи с ролью
+	}
+
 	DocIsBaseTemplates struct {
 		Vue bool
 		Sql bool
 	}
 
 	DocSqlMethod struct {
-		Name   string
-		Roles  []string
-		Params map[string]string
-		Tmpl   DocSqlMethodTmpl
+		Name    string
+		Roles   []string
+		Params  map[string]string  // параметры метода: название -> тип (string, int, int64, double, bool, date, jsonb...). Используются в описании OpenAPI
+		Timeout int                // таймаут вызова через api в секундах. Если 0, то pg.DefaultCallTimeout
+		Tables  []string           // таблицы, при изменении которых сбрасывается кэш метода. Если не указано, то таблица документа
+		Cache   *DocSqlMethodCache // кэширование результата вызова через api. nil - без кэша
+		Hooks   []string           // цепочка before hook'ов по названиям (PgMethodHookAddUserId или ProjectType.AddPgMethodHook). nil - только PgMethodHookAddUserId, пустой список - без hook'ов
+		Tmpl    DocSqlMethodTmpl
+	}
+
+	DocSqlMethodCache struct {
+		Ttl   int      // время кэширования в секундах
+		Scope []string // из чего составляется ключ кэша: PgMethodCacheScopeUser, PgMethodCacheScopeRole, PgMethodCacheScopeParams. С hook'ом addUserId обязателен PgMethodCacheScopeUser
 	}
 
 	DocSqlMethodTmpl struct {
@@ -234,8 +263,10 @@ func (d DocType) Fld(fldName string) *FldType {
 			return &f
 		}
 	}
-	log.Fatalf("d.Fld: doc '%s' fld '%s' not found", d.Name, fldName)
-	return nil
+	// при построении описания возвращаем пустое поле, чтобы собрать все ошибки за один проход - ошибка попадет в отчет Validate.
+	// Во время генерации (вызов из шаблона) генерация прерывается
+	addDeferredIssue(ValidationIssue{ValidationSeverityError, d.Name, fldName, "", "d.Fld: fld not found"})
+	return &FldType{Name: fldName}
 }
 
 // место вызова разных доп функций для инициализации документа, после того как основные поля заполнены
@@ -294,6 +325,28 @@ func (d DocType) IsStateMachine() bool {
 	return d.StateMachine != nil
 }
 
+// IsRealtimeRecipientsOnly события документа получают только пользователи из Realtime.Recipients
+func (d DocType) IsRealtimeRecipientsOnly() bool {
+	return d.Realtime != nil && len(d.Realtime.Recipients) > 0
+}
+
+// RealtimeTopicRoles роли для подписки на топики doc:<doc> и doc:<doc>:<id>: Realtime.Roles, Vue.Roles или роли метода <doc>_list
+func (d DocType) RealtimeTopicRoles() []string {
+	if d.Realtime == nil || d.IsRealtimeRecipientsOnly() {
+		return nil
+	}
+	if len(d.Realtime.Roles) > 0 {
+		return d.Realtime.Roles
+	}
+	if len(d.Vue.Roles) > 0 {
+		return d.Vue.Roles
+	}
+	if m, ok := d.Sql.Methods[d.Name+"_list"]; ok && m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
 func (d DocType) IsBitrixIntegration() bool {
 	return len(d.Integrations.Bitrix.UrlName) > 0
 }
@@ -230,7 +230,7 @@ func (sm *DocSm) GenerateTmpls(doc *DocType, params map[string]interface{}) {
 		}
 		doc.Templates[fileName] = &DocTemplate{
 			Source:       cardTmplPath,
-			DistPath:     fmt.Sprintf("../src/webClient/src/app/components/%s/comp", doc.Name),
+			DistPath:     fmt.Sprintf("%s/webClient/src/app/components/%s/comp", DefaultDistPath, doc.Name),
 			DistFilename: fileName,
 			FuncMap: map[string]interface{}{
 				"GetStateName": func() string { return stTitle },
@@ -272,7 +272,7 @@ func (sm *DocSm) GenerateTmpls(doc *DocType, params map[string]interface{}) {
 			}
 			doc.Templates[fileName] = &DocTemplate{
 				Source:       actionBtnPath,
-				DistPath:     fmt.Sprintf("../src/webClient/src/app/components/%s/comp", doc.Name),
+				DistPath:     fmt.Sprintf("%s/webClient/src/app/components/%s/comp", DefaultDistPath, doc.Name),
 				DistFilename: fileName,
 				FuncMap: map[string]interface{}{
 					"GetLabel":          func() string { return actnLabel },
@@ -3,7 +3,6 @@ package types
 import (
 	"fmt"
 	"github.com/tvitcom/nla_framework/utils"
-	"log"
 	"strings"
 )
 
@@ -73,6 +72,7 @@ type (
 		Default                  string
 		IsNotUpdatable           bool   // признак, что поле не обновляется вручную. Либо заполняется только при создании, либо обновляется триггером
 		FillValueInBeforeTrigger string // строка, которая выполняется в trigger и результат, которой присваивается полю. Например new.fullname
+		PrevName                 string // предыдущее название колонки. Если указано, то в миграции колонка переименовывается, а не удаляется и создается заново
 	}
 
 	FldVueOptionsItem struct {
@@ -132,6 +132,26 @@ func (fld *FldType) PrintPgModel() string {
 	return res
 }
 
+// тип колонки в postgres для alter скриптов и миграций
+func (fld *FldType) PgColumnType() string {
+	if fld.Type == FldTypeString {
+		if fld.Sql.Size > 0 {
+			return fmt.Sprintf("CHARACTER VARYING(%v)", fld.Sql.Size)
+		}
+		return "text"
+	}
+	if fld.Type == FldTypeInt64 {
+		return "int"
+	}
+	if fld.Type == FldTypeDouble {
+		return "double precision"
+	}
+	if utils.CheckContainsSliceStr(fld.Type, FldTypeDate, FldTypeDatetime) {
+		return "timestamp"
+	}
+	return fld.Type
+}
+
 func (fld *FldType) GoType() string {
 	switch fld.Type {
 	case FldTypeDouble:
@@ -186,7 +206,8 @@ func (fld FldType) SetRowCol(n ...int) FldType {
 		if fld.Doc != nil {
 			docName = fld.Doc.Name
 		}
-		log.Fatalf("Doc: '%s' Fld: '%s' SetRowCol params must be more tan two numbers. Get %v", docName, fld.Name, n)
+		addDeferredIssue(ValidationIssue{ValidationSeverityError, docName, fld.Name, "", fmt.Sprintf("SetRowCol params must be more tan two numbers. Get %v", n)})
+		return fld
 	}
 	fld.Vue.RowCol = [][]int{{n[0], n[1]}}
 	// если указано третье число, то заменяем класс, описыающий ширину колонки
@@ -243,6 +264,12 @@ func (fld FldType) SetDefault(s string) FldType {
 	return fld
 }
 
+// переименование поля. Указывается старое название колонки, чтобы в миграции сохранились данные
+func (fld FldType) SetPrevName(s string) FldType {
+	fld.Sql.PrevName = s
+	return fld
+}
+
 func (fld FldType) SetIsNotUpdatable() FldType {
 	fld.Sql.IsNotUpdatable = true
 	return fld
@@ -3,7 +3,7 @@ package types
 import (
 	"fmt"
 	"github.com/serenize/snaker"
-	"log"
+	"github.com/tvitcom/nla_framework/utils"
 	"strings"
 )
 
@@ -48,10 +48,10 @@ type (
 
 func GetFldVueCompositionTable(d *DocType, tbl FldVueCompositionTable, rowCol [][]int, params... string) (fld FldType) {
 	if len(tbl.FldName) == 0 {
-		log.Fatalf("doc: '%s'. Missed FldName in FldVueCompositionTable", d.Name)
+		utils.Fatalf("doc: '%s'. Missed FldName in FldVueCompositionTable", d.Name)
 	}
 	if len(tbl.PgMethod) == 0 {
-		log.Fatalf("doc: '%s'. Missed PgMethod in FldVueCompositionTable", d.Name)
+		utils.Fatalf("doc: '%s'. Missed PgMethod in FldVueCompositionTable", d.Name)
 	}
 	// если в snake стиле название, то переводим в camel
 	if strings.Contains(tbl.FldName, "_") {
@@ -60,7 +60,7 @@ func GetFldVueCompositionTable(d *DocType, tbl FldVueCompositionTable, rowCol []
 	// проставляем дефолты в columns
 	for i, col := range tbl.Columns {
 		if len(col.Name) == 0 {
-			log.Fatalf("doc: '%s'. Missed column name in FldVueCompositionTable", d.Name)
+			utils.Fatalf("doc: '%s'. Missed column name in FldVueCompositionTable", d.Name)
 		}
 		if len(col.Field) == 0 {
 			col.Field = col.Name
@@ -82,7 +82,7 @@ func GetFldVueCompositionTable(d *DocType, tbl FldVueCompositionTable, rowCol []
 	}
 	d.Templates[fmt.Sprintf("%s_common_table", tbl.FldName)] = &DocTemplate{
 		Source:       fmt.Sprintf("%s/templates/webClient/quasar_%v/doc/comp/commonTable.vue", getRootDirPath(), d.GetProject().GetQuasarVersion()),
-		DistPath:     "../src/webClient/src/app/components/partner/comp",
+		DistPath:     DefaultDistPath + "/webClient/src/app/components/partner/comp",
 		DistFilename: tbl.FldName + "CommonTable.vue",
 		FuncMap: map[string]interface{}{
 			"GetTableTitle": func() string {return tbl.TableName},
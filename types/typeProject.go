@@ -5,12 +5,25 @@ import (
 	"github.com/tvitcom/nla_framework/utils"
 	"github.com/serenize/snaker"
 	"go/build"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+const (
+	// DefaultOutputRoot корень генерируемого проекта по умолчанию - на уровень выше директории projectTemplate
+	DefaultOutputRoot = ".."
+	// DefaultDistPath директория с исходниками проекта по умолчанию. Пути DocTemplate.DistPath, которые начинаются с нее,
+	// при генерации переносятся в DistPath проекта
+	DefaultDistPath = DefaultOutputRoot + "/src"
+)
+
+const (
+	GeneratedFilesOnHandEditWarn      = "warn"      // предупреждение в лог, файл перезаписывается
+	GeneratedFilesOnHandEditRefuse    = "refuse"    // генерация не выполняется, пока файл не добавлен в Keep или не возвращен к сгенерированному виду
+	GeneratedFilesOnHandEditOverwrite = "overwrite" // файл молча перезаписывается
+)
+
 type (
 	ProjectType struct {
 		Name                     string
@@ -24,6 +37,12 @@ type (
 		IsDebugMode              bool
 		OverridePathForTemplates map[string]string // map для замены путей к исходным файлам. Ключ - путь к генерируемому файлу, значение - новый путь к исходному файлу.
 		I18n I18nType
+		GeneratedFiles           ProjectGeneratedFiles // настройки манифеста сгенерированных файлов
+	}
+	ProjectGeneratedFiles struct {
+		Keep        []string // маски файлов относительно корня проекта (например src/webServer/custom.go или src/webClient/src/app/components/client/), которые генератор не перезаписывает и не удаляет
+		OnHandEdit  string   // что делать если сгенерированный файл изменен вручную с прошлой генерации: warn (дефолт), refuse, overwrite
+		IsNoCleanup bool     // не удалять файлы, которые перестали генерироваться (например после удаления документа)
 	}
 	ProjectConfig struct {
 		Logo             string
@@ -42,6 +61,43 @@ type (
 		Backup           BackupConfig
 		Docker 			 DockerConfig
 		Graylog 		 GraylogConfig
+		Logger           LoggerConfig
+		ApiAudit         ApiAuditConfig
+		Metrics          MetricsConfig
+		Sse              SseConfig
+	}
+	// LoggerConfig лог приложения (пакет logger). Записи пишутся в stdout, в файл (если указан File)
+	// и в graylog (если указан Graylog.Host). Название приложения в записях - ProjectType.Name
+	LoggerConfig struct {
+		Level            string // debug, info, warn, error. Дефолт: info. Можно переопределить через LOG_LEVEL
+		Format           string // json или text. Дефолт: json, в dev режиме - text
+		File             string // путь к файлу лога. Пустая строка - не писать в файл
+		IsStdoutDisabled bool   // docker-compose при указанном Graylog.Host пересылает stdout в graylog (logging driver gelf), чтобы записи не дублировались, stdout можно отключить
+	}
+	// SseConfig доставка событий клиентам по SSE (/api/sse). Нулевые значения заменяются дефолтными при старте приложения (sse/main.go)
+	SseConfig struct {
+		BufferSize        int64  // размер очереди событий одного подключения. Дефолт: 64
+		HeartbeatInterval int64  // период отправки комментария-пинга в секундах, чтобы прокси не закрывали соединение. Дефолт: 25
+		ReplaySize        int64  // количество последних событий в памяти для повтора после переподключения (Last-Event-ID). Дефолт: 256
+		SlowClientPolicy  string // что делать при переполненной очереди: drop - пропустить событие, disconnect - закрыть соединение (клиент переподключится и получит пропущенное из повтора). Дефолт: drop
+	}
+	// MetricsConfig метрики prometheus. Роут генерируется в webServer/main.go, если IsEnabled.
+	// В config.toml (секция metrics) его можно отключить без перегенерации
+	MetricsConfig struct {
+		IsEnabled bool
+		Path      string // дефолт /metrics
+		Token     string // если указан, то запрос с заголовком Authorization: Bearer <token>. Можно переопределить через METRICS_TOKEN
+	}
+	// ApiAuditConfig журнал вызовов pg методов через api (таблица api_audit). Для просмотра генерируется экран /api_audit,
+	// пункт меню добавляется в проекте: VueMenu{Url: "api_audit", Text: "Журнал api", Roles: []string{"admin"}}.
+	// Нулевые значения заменяются дефолтными при старте приложения (webServer/apiAudit.go)
+	ApiAuditConfig struct {
+		IsEnabled     bool
+		RedactFields  []string // параметры, значения которых не сохраняются (без учета регистра, на любом уровне вложенности). Дефолт: password, token, auth_token, refresh_token, secret, sms_code, code
+		RetentionDays int64    // сколько дней хранятся записи. Дефолт: 90
+		BatchSize     int64    // количество записей в одном insert. Дефолт: 100
+		FlushInterval int64    // период записи накопленных записей в миллисекундах. Дефолт: 1000
+		BufferSize    int64    // размер очереди записей. При переполнении записи отбрасываются, чтобы не задерживать запросы. Дефолт: 10000
 	}
 	AuthConfig struct {
 		ByEmail               bool // дефолт - авторизация по email
@@ -50,6 +106,30 @@ type (
 		IsPassStepWaitingAuth bool // возможность отключить статус waiting_auth для вновь зарегестрированных пользователей
 		SmsService            AuthConfigSmsService
 		UserSqlFunction []string // дополнительные sql функции для таблицы User
+		RateLimit       AuthConfigRateLimit
+		Session         AuthConfigSession
+	}
+	// AuthConfigSession сессии пользователей (таблица user_session). При входе выдается короткий access токен и refresh токен,
+	// который меняется при каждом обновлении. Для просмотра и отзыва сессий генерируется экран /user_session,
+	// пункт меню добавляется в проекте: VueMenu{Url: "user_session", Text: "Сессии", Roles: []string{"admin"}}.
+	// Нулевые значения заменяются дефолтными при старте приложения (webServer/auth/session.go)
+	AuthConfigSession struct {
+		IsDisabled           bool  // без сессий: вход по бессрочному токену user_auth.auth_token, таблица user_session и экран /user_session не генерируются
+		AccessTtl            int64 // время жизни access токена в секундах. Дефолт: 900
+		RefreshTtl           int64 // время жизни refresh токена в секундах. Дефолт: 2592000 (30 дней)
+		IsLegacyTokenAllowed bool  // принимать бессрочные токены из user_auth.auth_token (для api клиентов, которые еще не перешли на refresh)
+	}
+	// AuthConfigRateLimit ограничение количества запросов к /auth и временная блокировка после неудачных попыток входа.
+	// Нулевые значения заменяются дефолтными при старте приложения (webServer/auth/rateLimit.go)
+	AuthConfigRateLimit struct {
+		IsDisabled      bool  // отключить ограничения
+		Window          int64 // период, за который считаются запросы, в секундах. Дефолт: 600
+		PerIp           int64 // запросов с одного ip за период. Дефолт: 100
+		PerLogin        int64 // запросов на один email за период (для каждого роута отдельно). Дефолт: 10
+		PerPhone        int64 // запросов на один телефон за период (для каждого роута отдельно, ограничивает отправку sms). Дефолт: 5
+		LockoutFailures int64 // неудачных попыток за период, после которых логин блокируется. Дефолт: 5
+		LockoutDuration int64 // время блокировки в секундах. Дефолт: 900
+		IsPgStore       bool  // хранить счетчики в postgres (таблица auth_rate_limit), если запущено несколько экземпляров приложения
 	}
 	AuthConfigSqlHooks struct {
 		CheckIsUserExist []string
@@ -80,6 +160,26 @@ type (
 		Ip       string
 		Username string // root или ...
 		SshPort  int64
+		ShutdownTimeout int64 // время на остановку приложения по SIGTERM в секундах. Дефолт: 30
+		TrustedProxies  []string // ip или подсети прокси (например nginx), которым доверяем X-Forwarded-For. Пустой список - не доверяем никому
+		Cors            WebServerCorsConfig
+		SecurityHeaders WebServerSecurityHeadersConfig
+	}
+	// WebServerCorsConfig CORS для production. В dev режиме (IS_DEVELOPMENT) разрешены любые источники
+	WebServerCorsConfig struct {
+		AllowOrigins       []string // по умолчанию только Url. "*" - любой источник, "https://*.site.ru" - поддомены
+		AllowMethods       []string // по умолчанию GET, POST, PATCH, DELETE, OPTIONS
+		AllowHeaders       []string // по умолчанию Content-Type, Auth-token, Authorization
+		IsAllowCredentials bool
+		MaxAge             int64 // секунды, по умолчанию 600
+	}
+	// WebServerSecurityHeadersConfig заголовки безопасности ответов webServer
+	WebServerSecurityHeadersConfig struct {
+		IsDisabled     bool
+		Csp            string // Content-Security-Policy для webClient/dist. По умолчанию формируется по подключенным интеграциям
+		FrameOptions   string // X-Frame-Options, по умолчанию DENY
+		ReferrerPolicy string // по умолчанию strict-origin-when-cross-origin
+		HstsMaxAge     int64  // секунды, по умолчанию год. Strict-Transport-Security отправляется только если Url - https
 	}
 	DevModeConfig struct {
 		IsDocker bool
@@ -152,9 +252,10 @@ type (
 	ProjectSql struct {
 		Methods     map[string][]DocSqlMethod // имя документа и список методов. Например "task": []{"task_by_deal"}
 		InitialData []string                  // данные при первоначальной загрузке
+		MethodHooks map[string]string         // before hook'и pg методов: название -> go выражение типа func(*gin.Context, interface{}) error
 	}
 	ProjectGo struct {
-		JobList []string // список job'ов
+		JobList []string // список job'ов. Задачи, которые нужно останавливать вместе с приложением, запускаются через Go(func(ctx context.Context) {...})
 		Routes  ProjectGoRoutes
 	}
 	ProjectRole struct {
@@ -282,13 +383,15 @@ func (p *ProjectType) FillVueFlds() {
 // заполняем боковое меню для Vue
 func (p *ProjectType) FillSideMenu() {
 	if p.Vue.Menu == nil {
-		log.Fatalf("ProjectType.FillSideMenu p.Vue.Menu == nil")
+		addDeferredIssue(ValidationIssue{ValidationSeverityError, "", "", "", "ProjectType.FillSideMenu p.Vue.Menu == nil"})
+		return
 	}
 	for i, v := range p.Vue.Menu {
 		if len(v.DocName) > 0 {
 			d := p.GetDocByName(v.DocName)
+			// документ не найден - ошибка попадет в отчет Validate
 			if d == nil {
-				log.Fatalf("ProjectType.FillSideMenu p.GetDocByName doc '%s' not found", v.DocName)
+				continue
 			}
 			if len(v.Icon) == 0 {
 				p.Vue.Menu[i].Icon = d.Vue.MenuIcon
@@ -315,7 +418,7 @@ func (p *ProjectType) FillSideMenu() {
 				if len(v1.DocName) > 0 {
 					d := p.GetDocByName(v1.DocName)
 					if d == nil {
-						log.Fatalf("ProjectType.FillSideMenu p.GetDocByName doc '%s' not found", v1.DocName)
+						continue
 					}
 					if len(v1.Icon) == 0 {
 						p.Vue.Menu[i].LinkList[j].Icon = d.Vue.MenuIcon
@@ -361,6 +464,39 @@ func (p ProjectType) IsBitrixIntegration() bool {
 	return len(p.Config.Bitrix.ApiUrl) > 0
 }
 
+// признак что включен журнал вызовов api (таблица api_audit)
+func (p ProjectType) IsApiAudit() bool {
+	return p.Config.ApiAudit.IsEnabled
+}
+
+// признак что вход выполняется через сессии пользователей (таблица user_session)
+func (p ProjectType) IsAuthSession() bool {
+	return !p.Config.Auth.Session.IsDisabled
+}
+
+// признак что есть документы с рассылкой изменений записей (DocType.Realtime)
+func (p ProjectType) IsRealtime() bool {
+	for _, d := range p.Docs {
+		if d.Realtime != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// признак что генерируется роут метрик prometheus
+func (p ProjectType) IsMetrics() bool {
+	return p.Config.Metrics.IsEnabled
+}
+
+// путь к метрикам prometheus
+func (p ProjectType) MetricsPath() string {
+	if len(p.Config.Metrics.Path) > 0 {
+		return p.Config.Metrics.Path
+	}
+	return "/metrics"
+}
+
 // признак что есть интеграция с Telegram
 func (p ProjectType) IsTelegramIntegration() bool {
 	return len(p.Config.Telegram.Token) > 0
@@ -407,11 +543,32 @@ func (p ProjectType) PrintJsRoles() string {
 	return res
 }
 
+// SetOutputRoot задает корень генерируемого проекта. Исходники пишутся в <root>/src, остальные файлы (docker-compose и т.д.) в <root>.
+// Пути шаблонов документов, указанные относительно DefaultDistPath, переносятся в новый DistPath
+func (p *ProjectType) SetOutputRoot(root string) {
+	p.DistPath = filepath.ToSlash(filepath.Join(root, "src"))
+	if p.DistPath == DefaultDistPath {
+		return
+	}
+	for _, d := range p.Docs {
+		for _, t := range d.Templates {
+			if t.DistPath == DefaultDistPath || strings.HasPrefix(t.DistPath, DefaultDistPath+"/") {
+				t.DistPath = p.DistPath + strings.TrimPrefix(t.DistPath, DefaultDistPath)
+			}
+		}
+	}
+}
+
+// OutputRoot корень генерируемого проекта (на уровень выше DistPath)
+func (p ProjectType) OutputRoot() string {
+	return filepath.ToSlash(filepath.Dir(p.DistPath))
+}
+
 // FillLocalPath Если не указан путь к локальному проекту, то вычисляем его автоматически
 func (p *ProjectType) FillLocalPath() string {
 	// для старого варианта, когда проект находится в директории GOPATH
 	if len(p.Config.LocalProjectPath) == 0 {
-		if _, err := os.Stat("../go.mod"); os.IsNotExist(err) {
+		if _, err := os.Stat(filepath.Join(p.OutputRoot(), "go.mod")); os.IsNotExist(err) {
 			// путь к локальной директории
 			path, _ := filepath.Abs("./")
 			// находим gopath
@@ -447,21 +604,62 @@ func (p ProjectType) PrintApiCallPgFuncMethods() string {
 		if len(m.Roles) > 0 {
 			roles = fmt.Sprintf(`"%s"`, strings.Join(m.Roles, `", "`))
 		}
-		res = fmt.Sprintf("%s\n\t\tPgMethod{\"%s\", []string{%s}, nil, BeforeHookAddUserId},", res, m.Name, roles)
+		var timeout string
+		if m.Timeout > 0 {
+			timeout = fmt.Sprintf(", Timeout: %d * time.Second", m.Timeout)
+		}
+		var tables string
+		if arr := project.pgMethodTables(m); len(arr) > 0 {
+			tables = fmt.Sprintf(`, Tables: []string{"%s"}`, strings.Join(arr, `", "`))
+		}
+		res = fmt.Sprintf("%s\n\t\tPgMethod{Title: \"%s\", Roles: []string{%s}%s%s%s},", res, m.Name, roles, project.printPgMethodPolicy(m), timeout, tables)
+	}
+	for _, m := range project.ApiPgMethods() {
+		printPgMethod(m)
 	}
-	if project.Sql.Methods != nil {
-		for _, v := range project.Sql.Methods {
-			for _, m := range v {
-				printPgMethod(m)
+	return res
+}
+
+// таблицы, при изменении которых сбрасывается кэш pg метода. Если в методе не указаны, то таблица документа, к которому относится метод
+func (p ProjectType) pgMethodTables(m DocSqlMethod) []string {
+	if len(m.Tables) > 0 {
+		return m.Tables
+	}
+	if d, ok := p.apiPgMethodDocs()[m.Name]; ok {
+		return []string{d.PgName()}
+	}
+	for docName, arr := range p.Sql.Methods {
+		for _, v := range arr {
+			if v.Name == m.Name {
+				return []string{docName}
 			}
 		}
 	}
-	for _, d := range project.Docs {
-		for _, m := range d.Sql.Methods {
-			printPgMethod(*m)
+	return nil
+}
+
+// isCachedMethodTable есть pg метод с кэшем, который сбрасывается при изменении таблицы
+func (p ProjectType) isCachedMethodTable(tableName string) bool {
+	for _, m := range p.ApiPgMethods() {
+		if m.Cache != nil && utils.CheckContainsSliceStr(tableName, p.pgMethodTables(m)...) {
+			return true
 		}
 	}
-	return res
+	return false
+}
+
+// PrintRealtimeTopicRoles роли для подписки на топики документов с правилами рассылки (DocType.Realtime).
+// Документы без ролей и с получателями (Realtime.Recipients) не печатаются - подписка на их топики запрещена
+func (p ProjectType) PrintRealtimeTopicRoles() string {
+	var res []string
+	for _, d := range p.Docs {
+		roles := d.RealtimeTopicRoles()
+		if len(roles) == 0 {
+			continue
+		}
+		res = append(res, fmt.Sprintf("\t\t\"%s\": {\"%s\"},", d.PgName(), strings.Join(roles, `", "`)))
+	}
+	return strings.Join(res, "\n")
 }
 
 // PrintProcessPgErrorMsgs печать перевода сообщений из postgres
@@ -0,0 +1,279 @@
+package types
+
+import (
+	"fmt"
+	"github.com/spf13/cast"
+	"github.com/tvitcom/nla_framework/utils"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning" // генерация продолжается, но описание скорее всего содержит ошибку
+)
+
+type (
+	// ValidationIssue одна проблема в описании проекта
+	ValidationIssue struct {
+		Severity string
+		Doc      string
+		Fld      string
+		Template string
+		Message  string
+	}
+
+	// ValidationReport результат проверки всего описания проекта.
+	// Реализует error, чтобы его можно было вернуть из Start
+	ValidationReport struct {
+		Issues []ValidationIssue
+	}
+)
+
+var (
+	// проблемы, найденные во время построения описания проекта (SetRowCol, DocType.Fld, FillSideMenu...).
+	// Там процесс не прерывается, а проблема попадает в отчет при вызове Validate, после чего список очищается
+	deferredIssues []ValidationIssue
+	// признак что описание уже проверено и идет генерация. Проблемы, найденные в процессе генерации, в отчет Validate
+	// уже не попадут, поэтому генерация прерывается через utils.Fatalf
+	isGenerating bool
+)
+
+func addDeferredIssue(issue ValidationIssue) {
+	if isGenerating {
+		utils.Fatalf("%s", issue)
+	}
+	deferredIssues = append(deferredIssues, issue)
+}
+
+// SetIsGenerating переключение между построением описания проекта (проблемы собираются для Validate)
+// и генерацией (проблемы прерывают генерацию)
+func SetIsGenerating(v bool) {
+	isGenerating = v
+}
+
+func (r *ValidationReport) AddError(docName, fldName, tmplName, msg string) {
+	r.Issues = append(r.Issues, ValidationIssue{ValidationSeverityError, docName, fldName, tmplName, msg})
+}
+
+func (r *ValidationReport) AddWarning(docName, fldName, tmplName, msg string) {
+	r.Issues = append(r.Issues, ValidationIssue{ValidationSeverityWarning, docName, fldName, tmplName, msg})
+}
+
+func (r *ValidationReport) HasErrors() bool {
+	for _, v := range r.Issues {
+		if v.Severity == ValidationSeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ValidationReport) Warnings() []ValidationIssue {
+	res := []ValidationIssue{}
+	for _, v := range r.Issues {
+		if v.Severity == ValidationSeverityWarning {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+func (r *ValidationReport) Error() string {
+	arr := []string{}
+	for _, v := range r.Issues {
+		arr = append(arr, v.String())
+	}
+	return fmt.Sprintf("project validation: %v issue(s)\n%s", len(r.Issues), strings.Join(arr, "\n"))
+}
+
+// путь к месту ошибки в описании проекта. Например "doc: client / fld: title"
+func (i ValidationIssue) Path() string {
+	arr := []string{}
+	if len(i.Doc) > 0 {
+		arr = append(arr, "doc: "+i.Doc)
+	}
+	if len(i.Fld) > 0 {
+		arr = append(arr, "fld: "+i.Fld)
+	}
+	if len(i.Template) > 0 {
+		arr = append(arr, "template: "+i.Template)
+	}
+	return strings.Join(arr, " / ")
+}
+
+func (i ValidationIssue) String() string {
+	if path := i.Path(); len(path) > 0 {
+		return fmt.Sprintf("[%s] %s: %s", i.Severity, path, i.Message)
+	}
+	return fmt.Sprintf("[%s] %s", i.Severity, i.Message)
+}
+
+// Validate проверяет все описание проекта и собирает все найденные проблемы в один отчет
+func (p ProjectType) Validate() *ValidationReport {
+	r := &ValidationReport{Issues: []ValidationIssue{}}
+	r.Issues = append(r.Issues, deferredIssues...)
+	deferredIssues = nil
+
+	// проверяем что название проекта без пробелов
+	if strings.Contains(p.Name, " ") {
+		r.AddError("", "", "", fmt.Sprintf("wrong project name: '%s'. Remove spaces.", p.Name))
+	}
+	// проверяем что если авторизация через email, то должны быть заполнены необходимые поля
+	if p.Config.Auth.ByEmail {
+		if len(p.Config.Email.Sender) == 0 || len(p.Config.Email.Host) == 0 || p.Config.Email.Port == 0 {
+			r.AddError("", "", "", "in Config.Email fill fields: 'Sender', 'Host', 'Port'")
+		}
+	}
+
+	docNames := map[string]bool{}
+	for _, d := range p.Docs {
+		if len(d.Name) == 0 {
+			r.AddError("", "", "", fmt.Sprintf("doc with empty name (NameRu: '%s')", d.NameRu))
+			continue
+		}
+		if docNames[d.Name] {
+			r.AddError(d.Name, "", "", "duplicate doc name")
+		}
+		docNames[d.Name] = true
+	}
+
+	for _, d := range p.Docs {
+		p.validateDocFlds(r, d, docNames)
+		p.validateDocTemplates(r, d)
+		p.validateDocRealtime(r, d)
+		// проверка что если документ - это уникальная связь двух таблиц, то в нем поле title если есть, то не должно быть уникальным
+		if d.Sql.IsUniqLink {
+			for _, fld := range d.Flds {
+				if fld.Name == "title" && fld.Sql.IsUniq {
+					r.AddError(d.Name, fld.Name, "", "field 'title' must be not uniq. Remove fld 'title' or t.GetFldTitle().SetIsNotUniq()")
+				}
+			}
+		}
+	}
+
+	// боковое меню и роуты
+	for _, m := range p.Vue.Menu {
+		if len(m.DocName) > 0 && !docNames[m.DocName] {
+			r.AddError(m.DocName, "", "", "Vue.Menu references doc that is not found")
+		}
+		for _, m1 := range m.LinkList {
+			if len(m1.DocName) > 0 && !docNames[m1.DocName] {
+				r.AddError(m1.DocName, "", "", "Vue.Menu references doc that is not found")
+			}
+		}
+	}
+	for _, route := range p.Vue.Routes {
+		if len(route) < 2 {
+			r.AddError("", "", "", fmt.Sprintf("Vue.Routes route %v length < 2", route))
+		}
+	}
+	return r
+}
+
+func (p ProjectType) validateDocFlds(r *ValidationReport, d DocType, docNames map[string]bool) {
+	fldNames := map[string]bool{}
+	for _, fld := range d.Flds {
+		// проверяем чтобы не было поля user_id, потому что это служебное поле
+		if fld.Name == "user_id" {
+			r.AddError(d.Name, fld.Name, "", "field with name 'user_id' is not allowed. Rename field.")
+		}
+		if len(fld.Name) > 0 {
+			if fldNames[fld.Name] && !fld.Sql.IsOptionFld {
+				r.AddError(d.Name, fld.Name, "", "duplicate field name")
+			}
+			fldNames[fld.Name] = true
+		}
+		for _, v := range fld.Vue.Options {
+			if strings.Contains(cast.ToString(v.Value), " ") {
+				r.AddError(d.Name, fld.Name, "", fmt.Sprintf("option value '%s' contains spaces. Remove spaces from value.", v.Value))
+			}
+		}
+		if !isRowColValid(fld.Vue.RowCol) {
+			r.AddError(d.Name, fld.Name, "", fmt.Sprintf("Vue.RowCol must contain row and column numbers starting from 1. Get %v", fld.Vue.RowCol))
+		}
+		if len(fld.Sql.Ref) > 0 && fld.Sql.Ref != "user" && !docNames[fld.Sql.Ref] {
+			r.AddWarning(d.Name, fld.Name, "", fmt.Sprintf("Sql.Ref references table '%s' that is not described in project docs", fld.Sql.Ref))
+		}
+		if (fld.Type == FldTypeVueComposition || fld.Vue.Type == FldTypeVueComposition) && fld.Vue.Composition == nil {
+			r.AddError(d.Name, fld.Name, "", fmt.Sprintf("fld have type '%s', but fld.Vue.Composition function is nil", FldTypeVueComposition))
+		}
+		if len(fld.Sql.PrevName) > 0 && fldNames[fld.Sql.PrevName] {
+			r.AddError(d.Name, fld.Name, "", fmt.Sprintf("Sql.PrevName '%s' is the name of another field", fld.Sql.PrevName))
+		}
+		if crop, ok := fld.Vue.Ext["crop"]; ok && !isImgCropValid(crop) {
+			r.AddError(d.Name, fld.Name, "", fmt.Sprintf("FldVueImgParams.Crop must be such format '300x400'. You write this: %s", crop))
+		}
+		if (fld.Vue.Type == FldVueTypeSelect || fld.Vue.Type == FldVueTypeMultipleSelect || fld.Vue.Type == FldVueTypeRadio) && len(fld.Vue.Options) == 0 {
+			r.AddWarning(d.Name, fld.Name, "", fmt.Sprintf("fld with vue type '%s' has no options", fld.Vue.Type))
+		}
+	}
+}
+
+// validateDocRealtime проверка правил рассылки изменений (DocType.Realtime)
+func (p ProjectType) validateDocRealtime(r *ValidationReport, d DocType) {
+	if d.Realtime == nil {
+		return
+	}
+	for _, op := range d.Realtime.Ops {
+		if !utils.CheckContainsSliceStr(strings.ToLower(op), "insert", "update", "delete") {
+			r.AddError(d.Name, "", "", fmt.Sprintf("Realtime.Ops: unknown operation '%s'. Use insert, update or delete", op))
+		}
+	}
+	for i, rc := range d.Realtime.Recipients {
+		switch {
+		case len(rc.Fld) > 0:
+			isFound := false
+			for _, f := range d.Flds {
+				isFound = isFound || f.Name == rc.Fld
+			}
+			if !isFound {
+				r.AddError(d.Name, rc.Fld, "", fmt.Sprintf("Realtime.Recipients[%v]: field not found", i))
+			}
+		case len(rc.LinkTable) > 0, len(rc.Role) > 0:
+		default:
+			r.AddError(d.Name, "", "", fmt.Sprintf("Realtime.Recipients[%v]: one of Fld, LinkTable or Role must be specified", i))
+		}
+	}
+}
+
+// проверка что crop имеет формат 300x400
+func isImgCropValid(crop string) bool {
+	arr := strings.Split(crop, "x")
+	if len(arr) != 2 {
+		return false
+	}
+	for _, v := range arr {
+		if _, err := strconv.Atoi(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p ProjectType) validateDocTemplates(r *ValidationReport, d DocType) {
+	for tName, dt := range d.Templates {
+		if dt == nil {
+			r.AddError(d.Name, "", tName, "template is nil")
+			continue
+		}
+		// шаблоны с уже заполненным Tmpl (например интеграции) не читаются с диска
+		if dt.Tmpl != nil || len(dt.Source) == 0 {
+			continue
+		}
+		if _, err := os.Stat(dt.Source); err != nil {
+			r.AddError(d.Name, "", tName, fmt.Sprintf("template source '%s' not found", dt.Source))
+		}
+	}
+	// шаблоны для табов ищутся среди стандартных шаблонов и шаблонов документа
+	for _, tab := range d.Vue.Tabs {
+		if _, ok := d.Templates["webClient_"+tab.TmplName]; ok {
+			continue
+		}
+		if tab.TmplName == "tabInfo.vue" || (tab.TmplName == "tabTasks.vue" && p.GetQuasarVersion() == 1) {
+			continue
+		}
+		r.AddError(d.Name, "", "webClient_"+tab.TmplName, fmt.Sprintf("template not found for tab '%s'", tab.Title))
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"net/url"
+	"strings"
+)
+
+// WebServerCsp Content-Security-Policy для статики webClient. Если в Config.WebServer.SecurityHeaders.Csp не указано,
+// то разрешаются свой домен и источники подключенных интеграций (Яндекс.Метрика, Telegram)
+func (p ProjectType) WebServerCsp() string {
+	if len(p.Config.WebServer.SecurityHeaders.Csp) > 0 {
+		return p.Config.WebServer.SecurityHeaders.Csp
+	}
+	script := []string{"'self'", "'unsafe-eval'"}
+	img := []string{"'self'", "data:", "blob:"}
+	connect := []string{"'self'"}
+	// websocket на тот же домен в части браузеров не попадает под 'self'
+	if u, err := url.Parse(p.Config.WebServer.Url); err == nil && len(u.Host) > 0 {
+		connect = append(connect, strings.Replace(u.Scheme, "http", "ws", 1)+"://"+u.Host)
+	}
+	frame := []string{"'self'"}
+	if len(p.Config.Yandex.MetrikaId) > 0 {
+		script = append(script, "'unsafe-inline'", "https://mc.yandex.ru")
+		img = append(img, "https://mc.yandex.ru")
+		connect = append(connect, "https://mc.yandex.ru")
+		frame = append(frame, "https://mc.yandex.ru")
+	}
+	if p.IsTelegramIntegration() {
+		script = append(script, "https://telegram.org")
+		img = append(img, "https://t.me", "https://*.telegram.org")
+		frame = append(frame, "https://oauth.telegram.org")
+	}
+	return strings.Join([]string{
+		"default-src 'self'",
+		"script-src " + strings.Join(script, " "),
+		"style-src 'self' 'unsafe-inline'",
+		"font-src 'self' data:",
+		"img-src " + strings.Join(img, " "),
+		"connect-src " + strings.Join(connect, " "),
+		"frame-src " + strings.Join(frame, " "),
+		"frame-ancestors 'none'",
+		"object-src 'none'",
+		"base-uri 'self'",
+	}, "; ")
+}
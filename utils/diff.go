@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// количество строк контекста вокруг изменений в unified diff
+const diffContextLines = 3
+
+type diffLine struct {
+	Op   byte // ' ', '-', '+'
+	Text string
+}
+
+// UnifiedDiff формирует unified diff между старой и новой версией файла
+func UnifiedDiff(path string, old, new []byte) string {
+	oldLines := splitLines(string(old))
+	newLines := splitLines(string(new))
+	lines := diffLines(oldLines, newLines)
+
+	res := []string{}
+	// ищем блоки изменений и вокруг них собираем hunk'и с контекстом
+	i := 0
+	for i < len(lines) {
+		if lines[i].Op == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		// расширяем hunk пока между изменениями не больше 2*diffContextLines строк без изменений
+		end := i
+		for end < len(lines) {
+			if lines[end].Op != ' ' {
+				end++
+				continue
+			}
+			j := end
+			for j < len(lines) && lines[j].Op == ' ' {
+				j++
+			}
+			if j == len(lines) || j-end > 2*diffContextLines {
+				end = end + diffContextLines
+				if end > len(lines) {
+					end = len(lines)
+				}
+				break
+			}
+			end = j
+		}
+		res = append(res, printHunk(lines, start, end))
+		i = end
+	}
+	if len(res) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("--- %[1]s\n+++ %[1]s\n%s", path, strings.Join(res, ""))
+}
+
+func printHunk(lines []diffLine, start, end int) string {
+	// номера строк в старом и новом файле, с которых начинается hunk
+	oldStart, newStart := 1, 1
+	for _, l := range lines[:start] {
+		if l.Op != '+' {
+			oldStart++
+		}
+		if l.Op != '-' {
+			newStart++
+		}
+	}
+	oldCnt, newCnt := 0, 0
+	body := ""
+	for _, l := range lines[start:end] {
+		if l.Op != '+' {
+			oldCnt++
+		}
+		if l.Op != '-' {
+			newCnt++
+		}
+		body = fmt.Sprintf("%s%c%s\n", body, l.Op, l.Text)
+	}
+	// для пустого диапазона в unified diff указывается номер предыдущей строки
+	if oldCnt == 0 {
+		oldStart--
+	}
+	if newCnt == 0 {
+		newStart--
+	}
+	return fmt.Sprintf("@@ -%v,%v +%v,%v @@\n%s", oldStart, oldCnt, newStart, newCnt, body)
+}
+
+// построчное сравнение через наибольшую общую подпоследовательность
+func diffLines(a, b []string) []diffLine {
+	// общее начало и конец не участвуют в поиске, чтобы не строить таблицу на весь файл
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+
+	// lcs[i][j] - длина общей подпоследовательности для midA[i:] и midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	res := []diffLine{}
+	for _, s := range a[:prefix] {
+		res = append(res, diffLine{' ', s})
+	}
+	i, j := 0, 0
+	for i < len(midA) && j < len(midB) {
+		if midA[i] == midB[j] {
+			res = append(res, diffLine{' ', midA[i]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			res = append(res, diffLine{'-', midA[i]})
+			i++
+		} else {
+			res = append(res, diffLine{'+', midB[j]})
+			j++
+		}
+	}
+	for ; i < len(midA); i++ {
+		res = append(res, diffLine{'-', midA[i]})
+	}
+	for ; j < len(midB); j++ {
+		res = append(res, diffLine{'+', midB[j]})
+	}
+	for _, s := range a[len(a)-suffix:] {
+		res = append(res, diffLine{' ', s})
+	}
+	return res
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// CompareDirs сравнивает два дерева файлов. Пути в результате относительно директорий, отсортированы.
+// Файлы, которых нет в oldDir - FileChangeAdded, которых нет в newDir - FileChangeDeleted
+func CompareDirs(oldDir, newDir string) ([]FileChange, error) {
+	oldFiles, err := readDirFiles(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newFiles, err := readDirFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+	res := []FileChange{}
+	for p, data := range newFiles {
+		old, ok := oldFiles[p]
+		if !ok {
+			res = append(res, FileChange{Path: p, Status: FileChangeAdded, New: data})
+			continue
+		}
+		if !ByteSliceEqual(old, data) {
+			res = append(res, FileChange{Path: p, Status: FileChangeChanged, Old: old, New: data})
+		}
+	}
+	for p, data := range oldFiles {
+		if _, ok := newFiles[p]; !ok {
+			res = append(res, FileChange{Path: p, Status: FileChangeDeleted, Old: data})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res, nil
+}
+
+// содержимое всех файлов директории. Ключ - путь относительно директории. Если директории нет, то пустой список
+func readDirFiles(dir string) (map[string][]byte, error) {
+	res := map[string][]byte{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		res[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	return res, err
+}
@@ -0,0 +1,191 @@
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+type (
+	// FileSystem - файловая система, в которую пишутся сгенерированные файлы проекта.
+	// Чтение исходных шаблонов идет напрямую с диска, через FileSystem только то, что относится к конечному проекту
+	FileSystem interface {
+		ReadFile(path string) ([]byte, error)
+		WriteFile(path string, data []byte) error
+		MkdirAll(path string) error
+		RemoveAll(path string) error
+		ReadDir(path string) ([]string, error) // список имен файлов и директорий
+	}
+
+	// OsFileSystem - запись напрямую на диск
+	OsFileSystem struct{}
+
+	// MemFileSystem - запись в память. Чтение файлов, которые не были записаны, идет с диска.
+	// Используется для dry-run режима, чтобы посмотреть изменения до того как они попадут в проект
+	MemFileSystem struct {
+		Files   map[string][]byte // записанные файлы. Ключ - путь к файлу
+		Removed []string          // удаленные файлы и директории
+	}
+
+	// FileChange - изменение файла относительно того, что лежит на диске
+	FileChange struct {
+		Path   string
+		Status string // FileChangeAdded, FileChangeChanged, FileChangeDeleted
+		Old    []byte
+		New    []byte
+	}
+)
+
+const (
+	FileChangeAdded   = "added"
+	FileChangeChanged = "changed"
+	FileChangeDeleted = "deleted"
+)
+
+// Fs файловая система, через которую генератор пишет файлы проекта
+var Fs FileSystem = OsFileSystem{}
+
+func SetFileSystem(fs FileSystem) {
+	Fs = fs
+}
+
+func (OsFileSystem) ReadFile(path string) ([]byte, error) {
+	return ioutil.ReadFile(path)
+}
+
+func (OsFileSystem) WriteFile(path string, data []byte) error {
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func (OsFileSystem) MkdirAll(path string) error {
+	return os.MkdirAll(path, os.ModePerm)
+}
+
+func (OsFileSystem) RemoveAll(path string) error {
+	return os.RemoveAll(path)
+}
+
+func (OsFileSystem) ReadDir(path string) ([]string, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	res := []string{}
+	for _, f := range files {
+		res = append(res, f.Name())
+	}
+	return res, nil
+}
+
+func NewMemFileSystem() *MemFileSystem {
+	return &MemFileSystem{Files: map[string][]byte{}, Removed: []string{}}
+}
+
+func (m *MemFileSystem) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	if data, ok := m.Files[path]; ok {
+		return data, nil
+	}
+	// файл был в удаленной директории и после этого не записывался
+	if m.isRemoved(path) {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (m *MemFileSystem) WriteFile(path string, data []byte) error {
+	m.Files[filepath.Clean(path)] = append([]byte{}, data...)
+	return nil
+}
+
+func (m *MemFileSystem) MkdirAll(path string) error {
+	return nil
+}
+
+func (m *MemFileSystem) RemoveAll(path string) error {
+	path = filepath.Clean(path)
+	for p := range m.Files {
+		if isPathInDir(p, path) {
+			delete(m.Files, p)
+		}
+	}
+	m.Removed = append(m.Removed, path)
+	return nil
+}
+
+func (m *MemFileSystem) ReadDir(path string) ([]string, error) {
+	path = filepath.Clean(path)
+	names := map[string]bool{}
+	if !m.isRemoved(path) {
+		if diskNames, err := (OsFileSystem{}).ReadDir(path); err == nil {
+			for _, n := range diskNames {
+				// файл или директория могли быть удалены по отдельности
+				if !m.isRemoved(filepath.Join(path, n)) {
+					names[n] = true
+				}
+			}
+		}
+	}
+	for p := range m.Files {
+		if isPathInDir(p, path) {
+			names[strings.Split(strings.TrimPrefix(p, path+string(os.PathSeparator)), string(os.PathSeparator))[0]] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil, os.ErrNotExist
+	}
+	res := []string{}
+	for n := range names {
+		res = append(res, n)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+// Changes список изменений относительно файлов на диске. Отсортирован по пути
+func (m *MemFileSystem) Changes() []FileChange {
+	res := []FileChange{}
+	for p, data := range m.Files {
+		old, err := ioutil.ReadFile(p)
+		if err != nil {
+			res = append(res, FileChange{Path: p, Status: FileChangeAdded, New: data})
+			continue
+		}
+		if !ByteSliceEqual(old, data) {
+			res = append(res, FileChange{Path: p, Status: FileChangeChanged, Old: old, New: data})
+		}
+	}
+	// файлы, которые были в удаленных директориях и не были созданы заново
+	isChecked := map[string]bool{}
+	for _, dir := range m.Removed {
+		_ = filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || isChecked[p] {
+				return nil
+			}
+			isChecked[p] = true
+			if _, ok := m.Files[p]; !ok {
+				old, _ := ioutil.ReadFile(p)
+				res = append(res, FileChange{Path: p, Status: FileChangeDeleted, Old: old})
+			}
+			return nil
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Path < res[j].Path })
+	return res
+}
+
+func (m *MemFileSystem) isRemoved(path string) bool {
+	for _, dir := range m.Removed {
+		if isPathInDir(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// признак что путь совпадает с директорией или находится внутри нее
+func isPathInDir(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
@@ -7,9 +7,34 @@ import (
 	"strings"
 )
 
+// FatalError ошибка генерации, которая в обычном режиме завершает процесс.
+// Если включен режим SetIsPanicOnFatal, то вместо завершения процесса вызывается panic с этой ошибкой
+type FatalError struct {
+	Msg string
+}
+
+func (e FatalError) Error() string {
+	return e.Msg
+}
+
+var isPanicOnFatal bool
+
+// SetIsPanicOnFatal включает режим, в котором Fatalf и CheckErr не завершают процесс, а вызывают panic(FatalError).
+// Используется в Start, чтобы вернуть ошибку вызывающему коду
+func SetIsPanicOnFatal(v bool) {
+	isPanicOnFatal = v
+}
+
+func Fatalf(format string, v ...interface{}) {
+	if isPanicOnFatal {
+		panic(FatalError{fmt.Sprintf(format, v...)})
+	}
+	log.Fatalf(format, v...)
+}
+
 func CheckErr(err error, msg string) {
 	if err != nil {
-		log.Fatalf("%s: %s", msg, err)
+		Fatalf("%s: %s", msg, err)
 	}
 }
 
@@ -26,7 +51,7 @@ func ParseDocTemplateFilename(docName, filename, globalDistPath string, docIndex
 	// разбираем имя шаблона на части
 	arr := strings.Split(filename, "_")
 	if len(arr) < 2 {
-		log.Fatalf("'%s' wrong template name %s. Must be at least two parts separete bay '_'\n", docName, filename)
+		Fatalf("'%s' wrong template name %s. Must be at least two parts separete bay '_'\n", docName, filename)
 	}
 	// имя итогового файла это последний элемент в массиве
 	distFilename = arr[len(arr)-1]
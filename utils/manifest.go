@@ -0,0 +1,270 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// файл манифеста лежит в корне проекта (на уровень выше DistPath)
+const ManifestFilename = ".generated_manifest.json"
+
+type (
+	// Manifest - список файлов, записанных генератором. Пути относительно корня проекта
+	Manifest struct {
+		Root  string          `json:"-"`
+		Keep  []string        `json:"-"` // маски файлов, которые генератор не перезаписывает и не удаляет
+		Files []ManifestEntry `json:"files"`
+		index map[string]int
+	}
+
+	ManifestEntry struct {
+		Path   string `json:"path"`
+		Hash   string `json:"hash"`   // sha256 содержимого файла
+		Source string `json:"source"` // шаблон или исходный файл, из которого сгенерирован файл
+	}
+)
+
+var (
+	// манифест текущей генерации. Если nil, то файлы пишутся без учета в манифесте
+	manifest *Manifest
+	// пути к файлам шаблонов для поля Source манифеста. Названия шаблонов повторяются в разных директориях (main.toml, main.go)
+	templateSources = map[*template.Template]string{}
+)
+
+func SetManifest(m *Manifest) {
+	manifest = m
+}
+
+func NewManifest(root string, keep []string) *Manifest {
+	return &Manifest{Root: root, Keep: keep, Files: []ManifestEntry{}, index: map[string]int{}}
+}
+
+// ReadManifest читает манифест предыдущей генерации. Если манифеста нет, то возвращается пустой
+func ReadManifest(root string, keep []string) (*Manifest, error) {
+	m := NewManifest(root, keep)
+	data, err := Fs.ReadFile(filepath.Join(root, ManifestFilename))
+	if err != nil {
+		return m, nil
+	}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	for i, e := range m.Files {
+		m.index[e.Path] = i
+	}
+	return m, nil
+}
+
+func (m *Manifest) Save() error {
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	data, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return Fs.WriteFile(filepath.Join(m.Root, ManifestFilename), data)
+}
+
+func (m *Manifest) Get(path string) (ManifestEntry, bool) {
+	i, ok := m.index[m.relPath(path)]
+	if !ok {
+		return ManifestEntry{}, false
+	}
+	return m.Files[i], true
+}
+
+func (m *Manifest) Add(path, source string, data []byte) {
+	m.addEntry(ManifestEntry{Path: m.relPath(path), Hash: HashBytes(data), Source: source})
+}
+
+func (m *Manifest) addEntry(e ManifestEntry) {
+	if i, ok := m.index[e.Path]; ok {
+		m.Files[i] = e
+		return
+	}
+	m.index[e.Path] = len(m.Files)
+	m.Files = append(m.Files, e)
+}
+
+// IsKept признак что файл в списке Keep. Маска сравнивается с путем относительно корня проекта,
+// маска, оканчивающаяся на '/', означает всю директорию
+func (m *Manifest) IsKept(path string) bool {
+	rel := m.relPath(path)
+	for _, pattern := range m.Keep {
+		if strings.HasSuffix(pattern, "/") && strings.HasPrefix(rel, pattern) {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HandEdited файлы, содержимое которых на диске отличается от записанного при генерации.
+// Удаленные файлы не считаются измененными - они будут сгенерированы заново
+func (m *Manifest) HandEdited() []ManifestEntry {
+	res := []ManifestEntry{}
+	for _, e := range m.Files {
+		if m.IsKept(e.Path) {
+			continue
+		}
+		if m.isHandEdited(e) {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+func (m *Manifest) isHandEdited(e ManifestEntry) bool {
+	data, err := Fs.ReadFile(filepath.Join(m.Root, e.Path))
+	return err == nil && HashBytes(data) != e.Hash
+}
+
+// RemoveOrphans удаляет файлы, которые были в предыдущей генерации, но не сгенерированы в текущей.
+// Файлы из списка Keep и измененные вручную не удаляются. Возвращает удаленные и пропущенные файлы
+func (m *Manifest) RemoveOrphans(cur *Manifest) (removed, skipped []ManifestEntry, err error) {
+	for _, e := range m.Files {
+		if _, ok := cur.index[e.Path]; ok {
+			continue
+		}
+		if m.IsKept(e.Path) || m.isHandEdited(e) {
+			skipped = append(skipped, e)
+			continue
+		}
+		if err = Fs.RemoveAll(filepath.Join(m.Root, e.Path)); err != nil {
+			return
+		}
+		m.removeEmptyDirs(filepath.Dir(filepath.Join(m.Root, e.Path)))
+		removed = append(removed, e)
+	}
+	return
+}
+
+// после удаления файла удаляем опустевшие директории вплоть до корня проекта
+func (m *Manifest) removeEmptyDirs(dir string) {
+	root := filepath.Clean(m.Root)
+	for dir != root && dir != "." && dir != string(filepath.Separator) {
+		names, err := Fs.ReadDir(dir)
+		if err != nil || len(names) > 0 {
+			return
+		}
+		if Fs.RemoveAll(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// CopyKept переносит в новый манифест записи о файлах из списка Keep, чтобы сохранить исходный hash
+func (m *Manifest) CopyKept(cur *Manifest) {
+	for _, e := range m.Files {
+		if _, ok := cur.index[e.Path]; !ok && m.IsKept(e.Path) {
+			cur.addEntry(e)
+		}
+	}
+}
+
+func (m *Manifest) relPath(path string) string {
+	if rel, err := filepath.Rel(m.Root, filepath.Clean(path)); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func HashBytes(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// WriteGeneratedFile записывает сгенерированный файл и учитывает его в манифесте.
+// Файлы из списка Keep не перезаписываются
+func WriteGeneratedFile(path, source string, data []byte) error {
+	if manifest != nil {
+		if manifest.IsKept(path) {
+			return nil
+		}
+		manifest.Add(path, source, data)
+	}
+	return Fs.WriteFile(path, data)
+}
+
+// ParseTemplateFiles то же, что t.ParseFiles, но запоминает путь к файлу шаблона для манифеста (см. TemplateSource)
+func ParseTemplateFiles(t *template.Template, filenames ...string) (*template.Template, error) {
+	res, err := t.ParseFiles(filenames...)
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		source := frameworkRelPath(filename)
+		if tmpl := res.Lookup(filepath.Base(filename)); tmpl != nil {
+			templateSources[tmpl] = source
+		}
+		if len(filenames) == 1 {
+			templateSources[res] = source
+		}
+	}
+	return res, nil
+}
+
+// TemplateSource путь к файлу шаблона относительно директории фреймворка. Для шаблонов не из файла - название шаблона
+func TemplateSource(t *template.Template) string {
+	if source, ok := templateSources[t]; ok {
+		return source
+	}
+	return t.Name()
+}
+
+// путь относительно директории фреймворка, чтобы манифест не зависел от того, где лежит фреймворк.
+// Шаблоны из проекта (TemplatePathOverride) остаются с полным путем
+func frameworkRelPath(filename string) string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		return filename
+	}
+	root := path.Dir(path.Dir(file))
+	return strings.TrimPrefix(strings.Replace(filename, "\\", "/", -1), root+"/")
+}
+
+// RemoveAllExceptKept удаление файла или директории целиком, кроме файлов из списка Keep манифеста текущей генерации.
+// Сохраненные файлы генератор не перезаписывает, поэтому удалять их нельзя - они не появятся снова
+func RemoveAllExceptKept(path string) error {
+	if manifest == nil || len(manifest.Keep) == 0 {
+		return Fs.RemoveAll(path)
+	}
+	return manifest.removeAllExceptKept(path)
+}
+
+func (m *Manifest) removeAllExceptKept(path string) error {
+	if m.IsKept(path) {
+		return nil
+	}
+	names, err := Fs.ReadDir(path)
+	if err != nil {
+		// файл или пустая директория
+		return Fs.RemoveAll(path)
+	}
+	for _, name := range names {
+		if err = m.removeAllExceptKept(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+	if names, err = Fs.ReadDir(path); err != nil || len(names) == 0 {
+		return Fs.RemoveAll(path)
+	}
+	return nil
+}
+
+// RecordGeneratedFile учитывает файл в манифесте без записи. Используется когда содержимое файла не изменилось
+// и перезапись пропускается
+func RecordGeneratedFile(path, source string, data []byte) {
+	if manifest != nil && !manifest.IsKept(path) {
+		manifest.Add(path, source, data)
+	}
+}
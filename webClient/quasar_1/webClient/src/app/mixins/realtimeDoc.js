@@ -0,0 +1,46 @@
+import {debounce} from 'quasar'
+
+// Обновление карточки документа при изменении записи (DocType.Realtime): подписка через websocket на топик
+// doc:<таблица>:<id>, при изменении данные перечитываются методом reload компонента.
+// isRecipientsOnly - события документа приходят только получателям (Realtime.Recipients) в топик пользователя
+// Пример: mixins: [realtimeDocMixin('deal')]
+export default (table, isRecipientsOnly) => ({
+  data() {
+    return {
+      realtimeSubs: [],
+    }
+  },
+  watch: {
+    id: {
+      immediate: true,
+      handler(id) {
+        this.realtimeUnsubscribe()
+        if (!id || id === 'new') return
+        const events$ = isRecipientsOnly ? this.$ws.getUserDocEvents$(table, id) : this.$ws.subscribeTopic(`doc:${table}:${id}`)
+        this.realtimeSubs.push(events$.subscribe(ev => {
+          const {op, data} = ev.data
+          if (op === 'delete' || (data && data.deleted === true)) {
+            this.$q.notify({message: this.$t('message.doc_deleted'), type: 'warning', position: 'top-right'})
+          } else {
+            this.realtimeReload()
+          }
+        }))
+        // часть событий потеряна (переподключение) - перечитываем
+        this.realtimeSubs.push(this.$ws.getReset$().subscribe(() => this.realtimeReload()))
+      }
+    }
+  },
+  methods: {
+    realtimeUnsubscribe() {
+      this.realtimeSubs.map(v => v.unsubscribe())
+      this.realtimeSubs = []
+    },
+  },
+  created() {
+    // несколько изменений подряд (например, сохранение с пересчетом в триггерах) - одно перечитывание
+    this.realtimeReload = debounce(() => this.reload(), 500)
+  },
+  beforeDestroy() {
+    this.realtimeUnsubscribe()
+  }
+})
@@ -0,0 +1,12 @@
+import WsClient from '../app/plugins/WsClient'
+
+// подключение по websocket, пока пользователь авторизован
+export default ({Vue}) => {
+  const ws = new WsClient()
+  Vue.use({
+    install: (Vue, options) => {
+      Vue.prototype.$ws = ws
+    }
+  }, {})
+  Vue.prototype.$currentUser.getUser$().subscribe(user => user ? ws.connect() : ws.close())
+}